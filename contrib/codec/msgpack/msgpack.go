@@ -0,0 +1,72 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+// Package msgpack implements the ghttp.Codec of MessagePack format,
+// which is registered to package ghttp automatically when imported.
+package msgpack
+
+import (
+	"bytes"
+
+	"github.com/vmihailenco/msgpack/v5"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/net/ghttp"
+)
+
+// Codec implements ghttp.Codec using MessagePack format.
+//
+// The struct fields are encoded and decoded with the names of their `json` tags,
+// so that the MessagePack content has the same keys as JSON content.
+type Codec struct{}
+
+const (
+	// ContentTypeMsgpack is the registered media type of MessagePack.
+	ContentTypeMsgpack = "application/msgpack"
+	// ContentTypeXMsgpack is the legacy media type of MessagePack.
+	ContentTypeXMsgpack = "application/x-msgpack"
+	// ContentTypeVndMsgpack is the vendor media type of MessagePack.
+	ContentTypeVndMsgpack = "application/vnd.msgpack"
+
+	structTag = "json"
+)
+
+var (
+	_ ghttp.Codec = Codec{}
+)
+
+func init() {
+	for _, mediaType := range []string{ContentTypeMsgpack, ContentTypeXMsgpack, ContentTypeVndMsgpack} {
+		ghttp.RegisterCodec(mediaType, Codec{})
+	}
+}
+
+// Encode implements interface ghttp.Codec.
+func (Codec) Encode(v interface{}) ([]byte, error) {
+	var (
+		buffer  = bytes.NewBuffer(nil)
+		encoder = msgpack.NewEncoder(buffer)
+	)
+	encoder.SetCustomStructTag(structTag)
+	encoder.SetOmitEmpty(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, gerror.Wrap(err, `MessagePack encoding failed`)
+	}
+	return buffer.Bytes(), nil
+}
+
+// Decode implements interface ghttp.Codec.
+func (Codec) Decode(data []byte, v interface{}) error {
+	decoder := msgpack.NewDecoder(bytes.NewReader(data))
+	decoder.SetCustomStructTag(structTag)
+	decoder.SetMapDecoder(func(d *msgpack.Decoder) (interface{}, error) {
+		return d.DecodeUntypedMap()
+	})
+	if err := decoder.Decode(v); err != nil {
+		return gerror.Wrap(err, `MessagePack decoding failed`)
+	}
+	return nil
+}
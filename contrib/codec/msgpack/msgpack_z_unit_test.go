@@ -0,0 +1,97 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package msgpack_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/contrib/codec/msgpack/v2"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/os/gctx"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/gogf/gf/v2/util/guid"
+)
+
+var (
+	ctx = gctx.New()
+)
+
+type UserReq struct {
+	g.Meta `path:"/user" method:"post"`
+	Name   string `json:"name"`
+	Age    int    `json:"age"`
+}
+
+type UserRes struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+func Test_Codec(t *testing.T) {
+	codec := msgpack.Codec{}
+	gtest.C(t, func(t *gtest.T) {
+		data, err := codec.Encode(&UserRes{Name: "john", Age: 18})
+		t.AssertNil(err)
+
+		var m map[string]interface{}
+		t.AssertNil(codec.Decode(data, &m))
+		t.Assert(m, g.Map{"name": "john", "age": 18})
+
+		var res UserRes
+		t.AssertNil(codec.Decode(data, &res))
+		t.Assert(res, UserRes{Name: "john", Age: 18})
+	})
+	gtest.C(t, func(t *gtest.T) {
+		var m map[string]interface{}
+		t.AssertNE(codec.Decode([]byte{0x81, 0xa1}, &m), nil)
+		t.AssertNE(codec.Decode([]byte{0xc1}, &m), nil)
+	})
+	gtest.C(t, func(t *gtest.T) {
+		for _, mediaType := range []string{
+			msgpack.ContentTypeMsgpack, msgpack.ContentTypeXMsgpack, msgpack.ContentTypeVndMsgpack,
+		} {
+			t.AssertNE(ghttp.GetCodec(mediaType), nil)
+		}
+	})
+}
+
+func Test_Server(t *testing.T) {
+	s := g.Server(guid.S())
+	s.Use(ghttp.MiddlewareHandlerResponse)
+	s.BindHandler("/user", func(ctx context.Context, req *UserReq) (res *UserRes, err error) {
+		return &UserRes{Name: req.Name, Age: req.Age}, nil
+	})
+	s.SetDumpRouterMap(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	gtest.C(t, func(t *gtest.T) {
+		codec := msgpack.Codec{}
+		body, err := codec.Encode(g.Map{"name": "john", "age": 18})
+		t.AssertNil(err)
+
+		client := g.Client().Prefix(fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort()))
+		resp, err := client.
+			Header(g.MapStrStr{"Accept": msgpack.ContentTypeMsgpack}).
+			ContentType(msgpack.ContentTypeMsgpack).
+			Post(ctx, "/user", body)
+		t.AssertNil(err)
+		defer resp.Close()
+		t.Assert(resp.Header.Get("Content-Type"), msgpack.ContentTypeMsgpack)
+
+		var res ghttp.DefaultHandlerResponse
+		t.AssertNil(codec.Decode(resp.ReadAll(), &res))
+		t.Assert(res.Message, "OK")
+		t.Assert(res.Data, g.Map{"name": "john", "age": 18})
+	})
+}
@@ -0,0 +1,52 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+// Package brotli implements the gcompress.Codec of brotli algorithm,
+// which is registered to package gcompress automatically when imported.
+package brotli
+
+import (
+	"io"
+
+	"github.com/andybalholm/brotli"
+
+	"github.com/gogf/gf/v2/encoding/gcompress"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+)
+
+// Codec implements gcompress.Codec using brotli algorithm.
+type Codec struct{}
+
+var (
+	_ gcompress.Codec = Codec{}
+)
+
+func init() {
+	gcompress.RegisterCodec(Codec{})
+}
+
+// Encoding implements interface gcompress.Codec.
+func (Codec) Encoding() string {
+	return gcompress.EncodingBrotli
+}
+
+// NewWriter implements interface gcompress.Codec.
+// The `level` is from 0 to 11 which means from the fastest to the best compression.
+func (Codec) NewWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	if level == gcompress.LevelDefault {
+		level = brotli.DefaultCompression
+	}
+	if level < brotli.BestSpeed || level > brotli.BestCompression {
+		return nil, gerror.NewCodef(gcode.CodeInvalidParameter, `invalid brotli compression level "%d"`, level)
+	}
+	return brotli.NewWriterLevel(w, level), nil
+}
+
+// NewReader implements interface gcompress.Codec.
+func (Codec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return io.NopCloser(brotli.NewReader(r)), nil
+}
@@ -0,0 +1,70 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package brotli_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/gogf/gf/contrib/compress/brotli/v2"
+
+	"github.com/gogf/gf/v2/encoding/gcompress"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/os/gctx"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/gogf/gf/v2/util/guid"
+)
+
+var (
+	ctx      = gctx.New()
+	encoding = gcompress.EncodingBrotli
+)
+
+func Test_Codec(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		src := []byte(strings.Repeat("hello, world\n", 100))
+		data, err := gcompress.Compress(encoding, src)
+		t.AssertNil(err)
+		t.Assert(len(data) < len(src), true)
+		data, err = gcompress.Decompress(encoding, data)
+		t.AssertNil(err)
+		t.Assert(data, src)
+
+		data, err = gcompress.Compress(encoding, src, 1)
+		t.AssertNil(err)
+		data, err = gcompress.Decompress(encoding, data)
+		t.AssertNil(err)
+		t.Assert(data, src)
+	})
+}
+
+func Test_MiddlewareCompress(t *testing.T) {
+	content := strings.Repeat("hello, world\n", 100)
+	s := g.Server(guid.S())
+	s.Use(ghttp.MiddlewareCompress())
+	s.BindHandler("/", func(r *ghttp.Request) {
+		r.Response.Write(content)
+	})
+	s.SetDumpRouterMap(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	gtest.C(t, func(t *gtest.T) {
+		client := g.Client().Prefix(fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort()))
+		resp, err := client.Header(g.MapStrStr{"Accept-Encoding": "gzip;q=0.5, " + encoding}).Get(ctx, "/")
+		t.AssertNil(err)
+		defer resp.Close()
+		t.Assert(resp.Header.Get("Content-Encoding"), encoding)
+		data, err := gcompress.Decompress(encoding, resp.ReadAll())
+		t.AssertNil(err)
+		t.Assert(data, content)
+	})
+}
@@ -0,0 +1,57 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+// Package zstd implements the gcompress.Codec of zstandard algorithm,
+// which is registered to package gcompress automatically when imported.
+package zstd
+
+import (
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/gogf/gf/v2/encoding/gcompress"
+	"github.com/gogf/gf/v2/errors/gerror"
+)
+
+// Codec implements gcompress.Codec using zstandard algorithm.
+type Codec struct{}
+
+var (
+	_ gcompress.Codec = Codec{}
+)
+
+func init() {
+	gcompress.RegisterCodec(Codec{})
+}
+
+// Encoding implements interface gcompress.Codec.
+func (Codec) Encoding() string {
+	return gcompress.EncodingZstd
+}
+
+// NewWriter implements interface gcompress.Codec.
+// The `level` is the zstandard compression level, which is mapped to the nearest level of the encoder.
+func (Codec) NewWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	var options []zstd.EOption
+	if level != gcompress.LevelDefault {
+		options = append(options, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+	}
+	writer, err := zstd.NewWriter(w, options...)
+	if err != nil {
+		return nil, gerror.Wrap(err, `zstd.NewWriter failed`)
+	}
+	return writer, nil
+}
+
+// NewReader implements interface gcompress.Codec.
+func (Codec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	reader, err := zstd.NewReader(r)
+	if err != nil {
+		return nil, gerror.Wrap(err, `zstd.NewReader failed`)
+	}
+	return reader.IOReadCloser(), nil
+}
@@ -0,0 +1,170 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gcompress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"sync"
+
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+)
+
+// Codec is the interface for the compression algorithm of an encoding,
+// which is used by stream compression like HTTP content encoding.
+type Codec interface {
+	// Encoding returns the encoding name, which is the token of HTTP header "Content-Encoding", like "gzip".
+	Encoding() string
+
+	// NewWriter creates and returns a writer compressing data to `w` with compression `level`.
+	// The `level` is specific to the algorithm, and it uses the default level if `level` is LevelDefault.
+	NewWriter(w io.Writer, level int) (io.WriteCloser, error)
+
+	// NewReader creates and returns a reader decompressing data from `r`.
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+const (
+	EncodingGzip    = "gzip"    // Encoding of gzip algorithm.
+	EncodingDeflate = "deflate" // Encoding of zlib algorithm, which is named "deflate" in HTTP.
+	EncodingBrotli  = "br"      // Encoding of brotli algorithm, which needs codec registered.
+	EncodingZstd    = "zstd"    // Encoding of zstandard algorithm, which needs codec registered.
+
+	// LevelDefault specifies the default compression level of the codec.
+	LevelDefault = -1
+)
+
+var (
+	// codecMap is the registered codecs.
+	codecMap = map[string]Codec{
+		EncodingGzip:    gzipCodec{},
+		EncodingDeflate: zlibCodec{},
+	}
+	codecMu sync.RWMutex
+)
+
+// RegisterCodec registers `codec` for its encoding, which overwrites the registered one.
+//
+// The codecs of gzip and deflate are registered in default. The codecs of other algorithms,
+// like brotli and zstandard, are registered by importing their implementation packages.
+func RegisterCodec(codec Codec) {
+	codecMu.Lock()
+	defer codecMu.Unlock()
+	codecMap[codec.Encoding()] = codec
+}
+
+// GetCodec returns the registered codec of `encoding`, which is nil if it is not registered.
+func GetCodec(encoding string) Codec {
+	codecMu.RLock()
+	defer codecMu.RUnlock()
+	return codecMap[encoding]
+}
+
+// Compress compresses `data` using the codec of `encoding`.
+// The optional parameter `level` specifies the compression level of the codec.
+func Compress(encoding string, data []byte, level ...int) ([]byte, error) {
+	codec, err := mustGetCodec(encoding)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		buf           bytes.Buffer
+		compressLevel = LevelDefault
+	)
+	if len(level) > 0 {
+		compressLevel = level[0]
+	}
+	writer, err := codec.NewWriter(&buf, compressLevel)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = writer.Write(data); err != nil {
+		return nil, gerror.Wrapf(err, `%s writer.Write failed`, encoding)
+	}
+	if err = writer.Close(); err != nil {
+		return nil, gerror.Wrapf(err, `%s writer.Close failed`, encoding)
+	}
+	return buf.Bytes(), nil
+}
+
+// Decompress decompresses `data` using the codec of `encoding`.
+func Decompress(encoding string, data []byte) ([]byte, error) {
+	codec, err := mustGetCodec(encoding)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := codec.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	var buf bytes.Buffer
+	if _, err = io.Copy(&buf, reader); err != nil {
+		return nil, gerror.Wrapf(err, `%s io.Copy failed`, encoding)
+	}
+	return buf.Bytes(), nil
+}
+
+func mustGetCodec(encoding string) (Codec, error) {
+	if codec := GetCodec(encoding); codec != nil {
+		return codec, nil
+	}
+	return nil, gerror.NewCodef(
+		gcode.CodeNecessaryPackageNotImport,
+		`codec of encoding "%s" is not registered, please import its implementation package`,
+		encoding,
+	)
+}
+
+// gzipCodec is the Codec of gzip algorithm.
+type gzipCodec struct{}
+
+func (gzipCodec) Encoding() string {
+	return EncodingGzip
+}
+
+func (gzipCodec) NewWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	writer, err := gzip.NewWriterLevel(w, level)
+	if err != nil {
+		return nil, gerror.Wrapf(err, `gzip.NewWriterLevel failed for level "%d"`, level)
+	}
+	return writer, nil
+}
+
+func (gzipCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	reader, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, gerror.Wrap(err, `gzip.NewReader failed`)
+	}
+	return reader, nil
+}
+
+// zlibCodec is the Codec of zlib algorithm.
+type zlibCodec struct{}
+
+func (zlibCodec) Encoding() string {
+	return EncodingDeflate
+}
+
+func (zlibCodec) NewWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	writer, err := zlib.NewWriterLevel(w, level)
+	if err != nil {
+		return nil, gerror.Wrapf(err, `zlib.NewWriterLevel failed for level "%d"`, level)
+	}
+	return writer, nil
+}
+
+func (zlibCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	reader, err := zlib.NewReader(r)
+	if err != nil {
+		return nil, gerror.Wrap(err, `zlib.NewReader failed`)
+	}
+	return reader, nil
+}
@@ -0,0 +1,82 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gcompress_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/gogf/gf/v2/encoding/gcompress"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/gogf/gf/v2/text/gstr"
+)
+
+// reverseCodec is a codec for testing, which reverses nothing but marks the encoding.
+type reverseCodec struct{}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+func (reverseCodec) Encoding() string {
+	return "test-identity"
+}
+
+func (reverseCodec) NewWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	return nopWriteCloser{w}, nil
+}
+
+func (reverseCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return io.NopCloser(r), nil
+}
+
+func Test_Codec_Builtin(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		src := []byte(gstr.Repeat("hello, world\n", 100))
+		for _, encoding := range []string{gcompress.EncodingGzip, gcompress.EncodingDeflate} {
+			t.AssertNE(gcompress.GetCodec(encoding), nil)
+			data, err := gcompress.Compress(encoding, src)
+			t.AssertNil(err)
+			t.Assert(len(data) < len(src), true)
+			data, err = gcompress.Decompress(encoding, data)
+			t.AssertNil(err)
+			t.Assert(data, src)
+		}
+		// Gzip compatible.
+		data, err := gcompress.Compress(gcompress.EncodingGzip, src, 9)
+		t.AssertNil(err)
+		data, err = gcompress.UnGzip(data)
+		t.AssertNil(err)
+		t.Assert(data, src)
+
+		_, err = gcompress.Compress(gcompress.EncodingGzip, src, 100)
+		t.AssertNE(err, nil)
+		_, err = gcompress.Decompress(gcompress.EncodingGzip, src)
+		t.AssertNE(err, nil)
+	})
+}
+
+func Test_Codec_Register(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		_, err := gcompress.Compress("test-identity", []byte("hello"))
+		t.Assert(gerror.Code(err), gcode.CodeNecessaryPackageNotImport)
+
+		gcompress.RegisterCodec(reverseCodec{})
+		data, err := gcompress.Compress("test-identity", []byte("hello"))
+		t.AssertNil(err)
+		t.Assert(data, "hello")
+		data, err = gcompress.Decompress("test-identity", data)
+		t.AssertNil(err)
+		t.Assert(data, "hello")
+	})
+}
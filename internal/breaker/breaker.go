@@ -0,0 +1,199 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+// Package breaker provides the circuit breaker state machine for internal usage only.
+package breaker
+
+import (
+	"sync"
+	"time"
+)
+
+// State is the state of circuit breaker.
+type State int
+
+const (
+	StateClosed   State = 0 // Calls are allowed and the failures are counted.
+	StateOpen     State = 1 // Calls are rejected at once.
+	StateHalfOpen State = 2 // Limited trial calls are allowed to detect the recovery.
+)
+
+const (
+	defaultErrorRate        = 0.5
+	defaultSlowRate         = 0.5
+	defaultMinRequests      = 20
+	defaultWindow           = 10 * time.Second
+	defaultOpenTimeout      = 30 * time.Second
+	defaultHalfOpenRequests = 1
+)
+
+// Options is the options for Breaker. The unset fields use default values.
+type Options struct {
+	ErrorRate        float64       // Ratio of failed calls in the window that trips the breaker, 0.5 in default.
+	SlowThreshold    time.Duration // Duration over which the call is slow, 0 means not tripping on latency.
+	SlowRate         float64       // Ratio of slow calls in the window that trips the breaker, 0.5 in default.
+	MinRequests      int           // Minimum number of calls in the window before checking ratios, 20 in default.
+	Window           time.Duration // Duration of the statistics window, 10 seconds in default.
+	OpenTimeout      time.Duration // Duration keeping open before half-open, 30 seconds in default.
+	HalfOpenRequests int           // Number of trial calls in half-open state, 1 in default.
+
+	// OnStateChange is called with the breaker locked when the state changes.
+	OnStateChange func(from, to State)
+}
+
+// Breaker is a circuit breaker with a fixed statistics window.
+type Breaker struct {
+	mu          sync.Mutex
+	options     Options
+	state       State
+	windowStart time.Time // Start time of the statistics window in closed state.
+	openedAt    time.Time // Time of the breaker opening.
+	total       int       // Number of calls in the window, or trial calls in half-open state.
+	failures    int       // Number of failed calls in the window.
+	slows       int       // Number of slow calls in the window.
+	successes   int       // Number of succeeded trial calls in half-open state.
+}
+
+// String returns the name of the state.
+func (s State) String() string {
+	switch s {
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// New creates and returns a Breaker in closed state.
+func New(options Options) *Breaker {
+	if options.ErrorRate <= 0 {
+		options.ErrorRate = defaultErrorRate
+	}
+	if options.SlowRate <= 0 {
+		options.SlowRate = defaultSlowRate
+	}
+	if options.MinRequests <= 0 {
+		options.MinRequests = defaultMinRequests
+	}
+	if options.Window <= 0 {
+		options.Window = defaultWindow
+	}
+	if options.OpenTimeout <= 0 {
+		options.OpenTimeout = defaultOpenTimeout
+	}
+	if options.HalfOpenRequests <= 0 {
+		options.HalfOpenRequests = defaultHalfOpenRequests
+	}
+	return &Breaker{
+		options:     options,
+		windowStart: time.Now(),
+	}
+}
+
+// Allow checks whether a call is allowed. The allowed call must be followed by Done.
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case StateOpen:
+		if time.Since(b.openedAt) < b.options.OpenTimeout {
+			return false
+		}
+		b.setState(StateHalfOpen)
+		fallthrough
+
+	case StateHalfOpen:
+		if b.total >= b.options.HalfOpenRequests {
+			return false
+		}
+		b.total++
+
+	default:
+		if time.Since(b.windowStart) >= b.options.Window {
+			b.reset()
+		}
+	}
+	return true
+}
+
+// Done records the result of an allowed call.
+func (b *Breaker) Done(failed bool, duration time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var slow = b.options.SlowThreshold > 0 && duration >= b.options.SlowThreshold
+	switch b.state {
+	case StateHalfOpen:
+		if failed || slow {
+			b.setState(StateOpen)
+			return
+		}
+		b.successes++
+		if b.successes >= b.options.HalfOpenRequests {
+			b.setState(StateClosed)
+		}
+
+	case StateClosed:
+		b.total++
+		if failed {
+			b.failures++
+		}
+		if slow {
+			b.slows++
+		}
+		if b.total < b.options.MinRequests {
+			return
+		}
+		var (
+			total    = float64(b.total)
+			tripped  = float64(b.failures)/total >= b.options.ErrorRate
+			slowRate = float64(b.slows) / total
+		)
+		if tripped || (b.options.SlowThreshold > 0 && slowRate >= b.options.SlowRate) {
+			b.setState(StateOpen)
+		}
+	}
+}
+
+// State returns the current state of breaker.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// OpenUntil returns the time that the open breaker turns to half-open state.
+// It returns zero time if the breaker is not open.
+func (b *Breaker) OpenUntil() time.Time {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state != StateOpen {
+		return time.Time{}
+	}
+	return b.openedAt.Add(b.options.OpenTimeout)
+}
+
+// setState changes the state of breaker and resets the statistics.
+func (b *Breaker) setState(state State) {
+	if b.options.OnStateChange != nil && b.state != state {
+		b.options.OnStateChange(b.state, state)
+	}
+	b.state = state
+	if state == StateOpen {
+		b.openedAt = time.Now()
+	}
+	b.reset()
+}
+
+// reset resets the statistics of breaker.
+func (b *Breaker) reset() {
+	b.windowStart = time.Now()
+	b.total = 0
+	b.failures = 0
+	b.slows = 0
+	b.successes = 0
+}
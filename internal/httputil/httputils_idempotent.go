@@ -0,0 +1,40 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package httputil
+
+import (
+	"net/http"
+)
+
+const (
+	// HeaderIdempotencyKey is the request header carrying the idempotency key.
+	HeaderIdempotencyKey = "Idempotency-Key"
+)
+
+// idempotentMethods is the idempotent HTTP methods defined by RFC 9110.
+var idempotentMethods = map[string]struct{}{
+	http.MethodGet:     {},
+	http.MethodHead:    {},
+	http.MethodOptions: {},
+	http.MethodTrace:   {},
+	http.MethodPut:     {},
+	http.MethodDelete:  {},
+}
+
+// IsIdempotentMethod checks and returns whether `method` is idempotent.
+func IsIdempotentMethod(method string) bool {
+	_, ok := idempotentMethods[method]
+	return ok
+}
+
+// IsIdempotentRequest checks and returns whether `req` is idempotent by its method or header "Idempotency-Key".
+func IsIdempotentRequest(req *http.Request) bool {
+	if req.Header.Get(HeaderIdempotencyKey) != "" {
+		return true
+	}
+	return IsIdempotentMethod(req.Method)
+}
@@ -0,0 +1,133 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package httputil
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// RouteParam is the named parameter parsed from route pattern like `{id}` or `{id:int}`.
+type RouteParam struct {
+	Name       string // Parameter name, eg: id.
+	Constraint string // Parameter constraint, which is a build-in constraint name or a regular expression.
+	Start      int    // Start index of the parameter in the parsed string, which points to char '{'.
+	End        int    // End index of the parameter in the parsed string, which is next to char '}'.
+}
+
+var (
+	// routeConstraintMu is the concurrent safety lock for routeConstraints.
+	routeConstraintMu sync.RWMutex
+
+	// routeConstraints stores the named constraints for route parameter.
+	routeConstraints = map[string]string{
+		"int":   `-?[0-9]+`,
+		"uint":  `[0-9]+`,
+		"float": `-?[0-9]+(\.[0-9]+)?`,
+		"alpha": `[a-zA-Z]+`,
+		"alnum": `[a-zA-Z0-9]+`,
+		"slug":  `[a-z0-9]+(-[a-z0-9]+)*`,
+		"uuid":  `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`,
+	}
+
+	// routeParamNameRegex is the regular expression for valid route parameter name.
+	routeParamNameRegex = regexp.MustCompile(`^[\w\.\-]+$`)
+)
+
+// SetRouteConstraint sets named constraint `name` for route parameter with regular expression `pattern`.
+func SetRouteConstraint(name, pattern string) {
+	routeConstraintMu.Lock()
+	defer routeConstraintMu.Unlock()
+	routeConstraints[name] = pattern
+}
+
+// GetRouteConstraint retrieves and returns the regular expression of named constraint `name`.
+// It returns empty string if `name` is not a named constraint.
+func GetRouteConstraint(name string) string {
+	routeConstraintMu.RLock()
+	defer routeConstraintMu.RUnlock()
+	return routeConstraints[name]
+}
+
+// ConstraintToRegex converts route parameter constraint to regular expression without anchors.
+// The constraint can be a named constraint like `int`, or else it is treated as a regular expression.
+func ConstraintToRegex(constraint string) string {
+	if pattern := GetRouteConstraint(constraint); pattern != "" {
+		return pattern
+	}
+	return constraint
+}
+
+// ParseRouteParams parses and returns the named parameters in `pattern`, like `{id}`, `{id:int}`
+// and `{code:[A-Z]{3}}`. Note that the braces in the constraint should be paired.
+func ParseRouteParams(pattern string) []RouteParam {
+	var params []RouteParam
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '{' {
+			continue
+		}
+		var (
+			depth = 0
+			end   = -1
+		)
+		for j := i; j < len(pattern); j++ {
+			switch pattern[j] {
+			case '{':
+				depth++
+			case '}':
+				depth--
+			}
+			if depth == 0 {
+				end = j
+				break
+			}
+		}
+		if end == -1 {
+			break
+		}
+		var (
+			inner      = pattern[i+1 : end]
+			name       = inner
+			constraint string
+		)
+		if pos := strings.IndexByte(inner, ':'); pos != -1 {
+			name, constraint = inner[:pos], inner[pos+1:]
+		}
+		if !routeParamNameRegex.MatchString(name) || (constraint == "" && strings.Contains(inner, ":")) {
+			continue
+		}
+		params = append(params, RouteParam{
+			Name:       name,
+			Constraint: constraint,
+			Start:      i,
+			End:        end + 1,
+		})
+		i = end
+	}
+	return params
+}
+
+// StripRouteConstraints removes the constraints of named parameters in `pattern`,
+// eg: `/user/{id:int}` to `/user/{id}`.
+func StripRouteConstraints(pattern string) string {
+	params := ParseRouteParams(pattern)
+	if len(params) == 0 {
+		return pattern
+	}
+	var (
+		builder strings.Builder
+		last    = 0
+	)
+	for _, param := range params {
+		builder.WriteString(pattern[last:param.Start])
+		builder.WriteString("{" + param.Name + "}")
+		last = param.End
+	}
+	builder.WriteString(pattern[last:])
+	return builder.String()
+}
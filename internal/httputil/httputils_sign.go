@@ -0,0 +1,207 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package httputil
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"hash"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+)
+
+// The HMAC request signing scheme shared by gclient and ghttp:
+//
+//	Authorization: HMAC-SHA256 KeyId=<id>, SignedHeaders=<h1;h2>, Signature=<hex>
+//
+// The signature is calculated over the string to sign:
+//
+//	<algorithm>\n<timestamp>\n<hex sha256 of canonical request>
+//
+// in which the canonical request is:
+//
+//	<method>\n<canonical path>\n<canonical query>\n<canonical headers>\n<signed headers>\n<body hash>
+const (
+	SignAlgorithmHMACSHA256 = "HMAC-SHA256"
+	SignAlgorithmHMACSHA512 = "HMAC-SHA512"
+	SignHeaderTimestamp     = "X-Signature-Timestamp" // Unix timestamp in seconds of signing.
+	SignHeaderContentSHA256 = "X-Content-SHA256"      // Hex encoded SHA256 of request body.
+	SignUnsignedPayload     = "UNSIGNED-PAYLOAD"      // Body hash if the body is not signed.
+)
+
+// SignAuthorization is the parsed "Authorization" header of HMAC signing scheme.
+type SignAuthorization struct {
+	Algorithm     string
+	KeyId         string
+	SignedHeaders []string
+	Signature     string
+}
+
+// SignHashHex returns the hex encoded SHA256 of `data`.
+func SignHashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// SignHMAC returns the HMAC of `data` with `key` using SHA256.
+func SignHMAC(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// SignURIEncode encodes `s` as RFC 3986, which escapes all characters except the unreserved ones.
+// The slash character is not escaped if `encodeSlash` is false.
+func SignURIEncode(s string, encodeSlash bool) string {
+	var builder strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !encodeSlash) {
+			builder.WriteByte(c)
+			continue
+		}
+		builder.WriteString("%")
+		builder.WriteString(strings.ToUpper(hex.EncodeToString([]byte{c})))
+	}
+	return builder.String()
+}
+
+// SignCanonicalQuery returns the query string of `values`, which are encoded by SignURIEncode
+// and sorted by name and value.
+func SignCanonicalQuery(values url.Values) string {
+	pairs := make([][2]string, 0, len(values))
+	for name, items := range values {
+		encodedName := SignURIEncode(name, true)
+		for _, item := range items {
+			pairs = append(pairs, [2]string{encodedName, SignURIEncode(item, true)})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i][0] != pairs[j][0] {
+			return pairs[i][0] < pairs[j][0]
+		}
+		return pairs[i][1] < pairs[j][1]
+	})
+	items := make([]string, len(pairs))
+	for i, pair := range pairs {
+		items[i] = pair[0] + "=" + pair[1]
+	}
+	return strings.Join(items, "&")
+}
+
+// SignCanonicalHeaders returns the canonical headers and the signed headers list
+// of `signedHeaders`, whose names are lower-cased and sorted. The "host" header uses `host`.
+func SignCanonicalHeaders(header http.Header, host string, signedHeaders []string) (canonical, signed string) {
+	var (
+		names = make([]string, 0, len(signedHeaders))
+		seen  = make(map[string]struct{}, len(signedHeaders))
+	)
+	for _, name := range signedHeaders {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := seen[name]; ok || name == "" {
+			continue
+		}
+		seen[name] = struct{}{}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var builder strings.Builder
+	for _, name := range names {
+		var values []string
+		if name == "host" {
+			values = []string{host}
+		} else {
+			for _, value := range header.Values(name) {
+				// Sequential spaces are collapsed into one space.
+				values = append(values, strings.Join(strings.Fields(value), " "))
+			}
+		}
+		builder.WriteString(name)
+		builder.WriteString(":")
+		builder.WriteString(strings.Join(values, ","))
+		builder.WriteString("\n")
+	}
+	return builder.String(), strings.Join(names, ";")
+}
+
+// SignCanonicalRequest returns the canonical request of HMAC signing scheme, and the signed headers list.
+func SignCanonicalRequest(
+	method string, u *url.URL, host string, header http.Header, signedHeaders []string, bodyHash string,
+) (canonicalRequest, signed string) {
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+	canonicalHeaders, signed := SignCanonicalHeaders(header, host, signedHeaders)
+	canonicalRequest = strings.Join([]string{
+		strings.ToUpper(method),
+		SignURIEncode(path, false),
+		SignCanonicalQuery(u.Query()),
+		canonicalHeaders,
+		signed,
+		bodyHash,
+	}, "\n")
+	return canonicalRequest, signed
+}
+
+// SignSignature returns the hex encoded signature of HMAC signing scheme.
+func SignSignature(algorithm, secret, timestamp, canonicalRequest string) (string, error) {
+	var newHash func() hash.Hash
+	switch algorithm {
+	case SignAlgorithmHMACSHA256:
+		newHash = sha256.New
+	case SignAlgorithmHMACSHA512:
+		newHash = sha512.New
+	default:
+		return "", gerror.NewCodef(gcode.CodeInvalidParameter, `unsupported signing algorithm "%s"`, algorithm)
+	}
+	var (
+		h            = hmac.New(newHash, []byte(secret))
+		stringToSign = algorithm + "\n" + timestamp + "\n" + SignHashHex([]byte(canonicalRequest))
+	)
+	h.Write([]byte(stringToSign))
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// String returns the "Authorization" header value.
+func (a SignAuthorization) String() string {
+	return a.Algorithm + " KeyId=" + a.KeyId +
+		", SignedHeaders=" + strings.Join(a.SignedHeaders, ";") +
+		", Signature=" + a.Signature
+}
+
+// ParseSignAuthorization parses the "Authorization" header value of HMAC signing scheme.
+func ParseSignAuthorization(value string) (authorization SignAuthorization, err error) {
+	algorithm, params, ok := strings.Cut(strings.TrimSpace(value), " ")
+	if !ok {
+		return authorization, gerror.NewCode(gcode.CodeInvalidParameter, `invalid signature authorization`)
+	}
+	authorization.Algorithm = algorithm
+	for _, param := range strings.Split(params, ",") {
+		name, paramValue, _ := strings.Cut(strings.TrimSpace(param), "=")
+		switch name {
+		case "KeyId":
+			authorization.KeyId = paramValue
+		case "SignedHeaders":
+			authorization.SignedHeaders = strings.Split(paramValue, ";")
+		case "Signature":
+			authorization.Signature = paramValue
+		}
+	}
+	if authorization.KeyId == "" || authorization.Signature == "" || len(authorization.SignedHeaders) == 0 {
+		return authorization, gerror.NewCode(gcode.CodeInvalidParameter, `invalid signature authorization`)
+	}
+	return authorization, nil
+}
@@ -7,6 +7,7 @@
 package httputil_test
 
 import (
+	"net/http"
 	"testing"
 
 	"github.com/gogf/gf/v2/frame/g"
@@ -37,6 +38,18 @@ func TestBuildParams(t *testing.T) {
 	})
 }
 
+func TestIsIdempotentRequest(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		req, _ := http.NewRequest(http.MethodPut, "http://127.0.0.1/", nil)
+		t.Assert(httputil.IsIdempotentRequest(req), true)
+
+		req, _ = http.NewRequest(http.MethodPost, "http://127.0.0.1/", nil)
+		t.Assert(httputil.IsIdempotentRequest(req), false)
+		req.Header.Set(httputil.HeaderIdempotencyKey, "key")
+		t.Assert(httputil.IsIdempotentRequest(req), true)
+	})
+}
+
 // https://github.com/gogf/gf/issues/4023
 func TestIssue4023(t *testing.T) {
 	gtest.C(t, func(t *gtest.T) {
@@ -33,9 +33,12 @@ type Client struct {
 	retryCount        int               // Retry count when request fails.
 	noUrlEncode       bool              // No url encoding for request parameters.
 	retryInterval     time.Duration     // Retry interval when request fails.
+	retryPolicy       *RetryPolicy      // Retry policy when request fails, which takes place of retryCount and retryInterval.
 	middlewareHandler []HandlerFunc     // Interceptor handlers
 	discovery         gsvc.Discovery    // Discovery for service.
 	builder           gsel.Builder      // Builder for request balance.
+	hedger            *hedger           // Hedger sending hedged requests for idempotent requests.
+	hostLimiter       *hostLimiter      // Limiter of in-flight requests per host.
 }
 
 const (
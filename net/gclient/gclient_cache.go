@@ -0,0 +1,567 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gclient
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gogf/gf/v2/container/gmap"
+	"github.com/gogf/gf/v2/internal/intlog"
+	"github.com/gogf/gf/v2/os/gcache"
+)
+
+// CacheOptions is the options for MiddlewareCache.
+type CacheOptions struct {
+	// Adapter specifies the storage of cached responses. It's a memory adapter in default.
+	// Note that the adapter like redis can be shared by multiple clients or processes.
+	Adapter gcache.Adapter
+
+	// Prefix specifies the key prefix of cache items in the adapter. It's "gclient.cache:" in default.
+	Prefix string
+
+	// MaxBodySize specifies the maximum body size of responses that are cached. It's 1MB in default.
+	MaxBodySize int64
+
+	// StaleTTL specifies how long the stale responses having validators "ETag" or "Last-Modified"
+	// are kept for revalidation. It's 1 hour in default.
+	StaleTTL time.Duration
+}
+
+// httpCache is the private HTTP cache implementing RFC 9111.
+type httpCache struct {
+	options      CacheOptions
+	revalidating *gmap.StrAnyMap // Keys of entries being revalidated in background.
+}
+
+// httpCacheEntry is the cached response.
+type httpCacheEntry struct {
+	StatusCode   int         `json:"statusCode"`
+	Header       http.Header `json:"header"`
+	Body         []byte      `json:"body"`
+	RequestTime  time.Time   `json:"requestTime"`  // Time when the request was sent.
+	ResponseTime time.Time   `json:"responseTime"` // Time when the response was received.
+}
+
+// cacheControl is the parsed directives of header "Cache-Control".
+type cacheControl map[string]string
+
+const (
+	defaultCachePrefix      = "gclient.cache:"
+	defaultCacheMaxBodySize = 1 << 20
+	defaultCacheStaleTTL    = time.Hour
+	httpHeaderCacheControl  = "Cache-Control"
+	httpHeaderAge           = "Age"
+	httpHeaderDate          = "Date"
+	httpHeaderExpires       = "Expires"
+	httpHeaderETag          = "ETag"
+	httpHeaderLastModified  = "Last-Modified"
+	httpHeaderVary          = "Vary"
+	httpHeaderIfNoneMatch   = "If-None-Match"
+	httpHeaderIfModified    = "If-Modified-Since"
+)
+
+var (
+	// cacheableStatusCodes is the status codes that are heuristically cacheable defined by RFC 9110.
+	cacheableStatusCodes = map[int]struct{}{
+		http.StatusOK:                   {},
+		http.StatusNonAuthoritativeInfo: {},
+		http.StatusNoContent:            {},
+		http.StatusMultipleChoices:      {},
+		http.StatusMovedPermanently:     {},
+		http.StatusPermanentRedirect:    {},
+		http.StatusNotFound:             {},
+		http.StatusMethodNotAllowed:     {},
+		http.StatusGone:                 {},
+		http.StatusRequestURITooLong:    {},
+		http.StatusNotImplemented:       {},
+	}
+
+	// cacheNotUpdatedHeaders is the headers that are not updated from the 304 response.
+	cacheNotUpdatedHeaders = map[string]struct{}{
+		"Content-Length":    {},
+		"Content-Encoding":  {},
+		"Transfer-Encoding": {},
+		"Content-Range":     {},
+	}
+)
+
+// MiddlewareCache creates and returns a middleware for Client.Use that caches the responses
+// following RFC 9111 as a private cache.
+//
+// The responses of GET requests are stored if they are cacheable by header "Cache-Control", "Expires"
+// and status code, and the fresh ones are served without sending requests. The stale responses are
+// revalidated with header "If-None-Match" or "If-Modified-Since", or served during revalidation in
+// background within "stale-while-revalidate", or served if the revalidation fails within "stale-if-error".
+// Header "Vary" of responses makes the requests having different values of the listed headers cached
+// separately. The successful requests of unsafe methods like POST invalidate the cached response of the URL.
+func MiddlewareCache(options ...CacheOptions) HandlerFunc {
+	var cacheOptions CacheOptions
+	if len(options) > 0 {
+		cacheOptions = options[0]
+	}
+	if cacheOptions.Adapter == nil {
+		cacheOptions.Adapter = gcache.NewAdapterMemory()
+	}
+	if cacheOptions.Prefix == "" {
+		cacheOptions.Prefix = defaultCachePrefix
+	}
+	if cacheOptions.MaxBodySize <= 0 {
+		cacheOptions.MaxBodySize = defaultCacheMaxBodySize
+	}
+	if cacheOptions.StaleTTL <= 0 {
+		cacheOptions.StaleTTL = defaultCacheStaleTTL
+	}
+	h := &httpCache{
+		options:      cacheOptions,
+		revalidating: gmap.NewStrAnyMap(true),
+	}
+	return h.handle
+}
+
+// handle is the middleware handler of the cache.
+func (h *httpCache) handle(c *Client, r *http.Request) (resp *Response, err error) {
+	var (
+		ctx = r.Context()
+		key = h.primaryKey(r)
+	)
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return c.Next(r)
+	default:
+		// The unsafe methods invalidate the cached response.
+		resp, err = c.Next(r)
+		if err == nil && resp.StatusCode < http.StatusBadRequest {
+			if _, err := h.options.Adapter.Remove(ctx, key); err != nil {
+				intlog.Errorf(ctx, `%+v`, err)
+			}
+		}
+		return resp, err
+	}
+	var requestCC = parseCacheControl(r.Header)
+	if requestCC.has("no-store") || r.Header.Get(httpHeaderIfNoneMatch) != "" || r.Header.Get(httpHeaderIfModified) != "" {
+		return c.Next(r)
+	}
+	entry, entryKey := h.lookup(ctx, key, r)
+	if entry == nil {
+		if requestCC.has("only-if-cached") {
+			return newCacheGatewayTimeoutResponse(r), nil
+		}
+		return h.fetch(r, key, c.Next)
+	}
+	var (
+		now        = time.Now()
+		responseCC = parseCacheControl(entry.Header)
+		age        = entry.currentAge(now)
+		lifetime   = entry.freshnessLifetime(responseCC)
+		staleness  = age - lifetime
+	)
+	if h.isFresh(requestCC, responseCC, age, lifetime) {
+		return entry.toResponse(r, age), nil
+	}
+	if requestCC.has("only-if-cached") {
+		return newCacheGatewayTimeoutResponse(r), nil
+	}
+	var canServeStale = !responseCC.has("must-revalidate") && !responseCC.has("no-cache")
+	if swr, ok := responseCC.seconds("stale-while-revalidate"); ok && canServeStale && staleness <= swr {
+		h.revalidateInBackground(c, r, key, entryKey, entry)
+		return entry.toResponse(r, age), nil
+	}
+	resp, err = h.revalidate(r, key, entry, c.Next)
+	if canServeStale && (err != nil || resp.StatusCode >= http.StatusInternalServerError) {
+		sie, ok := requestCC.seconds("stale-if-error")
+		if !ok {
+			sie, ok = responseCC.seconds("stale-if-error")
+		}
+		if ok && staleness <= sie {
+			if resp != nil {
+				_ = resp.Close()
+			}
+			return entry.toResponse(r, age), nil
+		}
+	}
+	return resp, err
+}
+
+// isFresh checks whether the cached response can be served without revalidation.
+func (h *httpCache) isFresh(requestCC, responseCC cacheControl, age, lifetime time.Duration) bool {
+	if requestCC.has("no-cache") || responseCC.has("no-cache") {
+		return false
+	}
+	if maxAge, ok := requestCC.seconds("max-age"); ok && age > maxAge {
+		return false
+	}
+	if minFresh, ok := requestCC.seconds("min-fresh"); ok {
+		age += minFresh
+	}
+	if age < lifetime {
+		return true
+	}
+	if responseCC.has("must-revalidate") || !requestCC.has("max-stale") {
+		return false
+	}
+	if maxStale, ok := requestCC.seconds("max-stale"); ok {
+		return age-lifetime <= maxStale
+	}
+	return true
+}
+
+// fetch sends request `r` using `send` and stores the response if it is cacheable.
+func (h *httpCache) fetch(r *http.Request, key string, send func(*http.Request) (*Response, error)) (*Response, error) {
+	requestTime := time.Now()
+	resp, err := send(r)
+	if err != nil {
+		return resp, err
+	}
+	h.store(r, key, resp, requestTime)
+	return resp, nil
+}
+
+// revalidate sends the conditional request of `r` using `send` to validate the stale `entry`.
+func (h *httpCache) revalidate(
+	r *http.Request, key string, entry *httpCacheEntry, send func(*http.Request) (*Response, error),
+) (*Response, error) {
+	var (
+		req          = r.Clone(r.Context())
+		etag         = entry.Header.Get(httpHeaderETag)
+		lastModified = entry.Header.Get(httpHeaderLastModified)
+	)
+	if etag != "" {
+		req.Header.Set(httpHeaderIfNoneMatch, etag)
+	}
+	if lastModified != "" {
+		req.Header.Set(httpHeaderIfModified, lastModified)
+	}
+	requestTime := time.Now()
+	resp, err := send(req)
+	if err != nil {
+		return resp, err
+	}
+	if resp.StatusCode != http.StatusNotModified || (etag == "" && lastModified == "") {
+		h.store(r, key, resp, requestTime)
+		return resp, nil
+	}
+	// Freshens a copy of the cached response with the headers of 304 response,
+	// as the cached one might be in use.
+	_ = resp.Close()
+	freshened := *entry
+	freshened.Header = entry.Header.Clone()
+	for name, values := range resp.Header {
+		if _, ok := cacheNotUpdatedHeaders[name]; !ok {
+			freshened.Header[name] = values
+		}
+	}
+	freshened.RequestTime = requestTime
+	freshened.ResponseTime = time.Now()
+	h.save(r, key, &freshened)
+	return freshened.toResponse(r, freshened.currentAge(freshened.ResponseTime)), nil
+}
+
+// revalidateInBackground revalidates the stale `entry` in background, which sends the request
+// without the subsequent middlewares.
+func (h *httpCache) revalidateInBackground(c *Client, r *http.Request, key, entryKey string, entry *httpCacheEntry) {
+	if !h.revalidating.SetIfNotExist(entryKey, struct{}{}) {
+		return
+	}
+	req := r.Clone(context.WithoutCancel(r.Context()))
+	req.Body = http.NoBody
+	go func() {
+		defer h.revalidating.Remove(entryKey)
+		resp, err := h.revalidate(req, key, entry, c.callRequest)
+		if err != nil {
+			intlog.Errorf(req.Context(), `%+v`, err)
+			return
+		}
+		_ = resp.Close()
+	}()
+}
+
+// lookup retrieves and returns the cached entry and its key for request `r`.
+// The cached responses of URL `key` are distinguished by the request headers listed by header "Vary".
+func (h *httpCache) lookup(ctx context.Context, key string, r *http.Request) (*httpCacheEntry, string) {
+	v, err := h.options.Adapter.Get(ctx, key)
+	if err != nil {
+		intlog.Errorf(ctx, `%+v`, err)
+		return nil, ""
+	}
+	if v.IsNil() {
+		return nil, ""
+	}
+	var (
+		entry    *httpCacheEntry
+		entryKey = key + "|" + getCacheVaryKey(splitCacheVaryNames(v.String()), r.Header)
+	)
+	if v, err = h.options.Adapter.Get(ctx, entryKey); err != nil {
+		intlog.Errorf(ctx, `%+v`, err)
+		return nil, ""
+	}
+	if v.IsNil() {
+		return nil, entryKey
+	}
+	if err = v.Scan(&entry); err != nil {
+		intlog.Errorf(ctx, `%+v`, err)
+		return nil, ""
+	}
+	return entry, entryKey
+}
+
+// store stores the response `resp` of request `r` sent at `requestTime` if it is cacheable.
+// The response body is read and replaced for the caller.
+func (h *httpCache) store(r *http.Request, key string, resp *Response, requestTime time.Time) {
+	var (
+		requestCC  = parseCacheControl(r.Header)
+		responseCC = parseCacheControl(resp.Header)
+	)
+	if requestCC.has("no-store") || responseCC.has("no-store") || resp.Header.Get(httpHeaderVary) == "*" {
+		return
+	}
+	_, cacheable := cacheableStatusCodes[resp.StatusCode]
+	if !cacheable && !responseCC.has("max-age") && !responseCC.has("public") && resp.Header.Get(httpHeaderExpires) == "" {
+		return
+	}
+	if resp.ContentLength > h.options.MaxBodySize {
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, h.options.MaxBodySize+1))
+	if err != nil {
+		// The caller reads the partial body and then the reading error.
+		resp.Body = &cacheReadCloser{
+			Reader: io.MultiReader(bytes.NewReader(body), cacheErrReader{err: err}),
+			Closer: resp.Body,
+		}
+		return
+	}
+	if int64(len(body)) > h.options.MaxBodySize {
+		resp.Body = &cacheReadCloser{
+			Reader: io.MultiReader(bytes.NewReader(body), resp.Body),
+			Closer: resp.Body,
+		}
+		return
+	}
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	h.save(r, key, &httpCacheEntry{
+		StatusCode:   resp.StatusCode,
+		Header:       resp.Header.Clone(),
+		Body:         body,
+		RequestTime:  requestTime,
+		ResponseTime: time.Now(),
+	})
+}
+
+// save saves `entry` for request `r` if it is useful.
+func (h *httpCache) save(r *http.Request, key string, entry *httpCacheEntry) {
+	var (
+		ctx        = r.Context()
+		responseCC = parseCacheControl(entry.Header)
+		ttl        = entry.freshnessLifetime(responseCC) - entry.currentAge(entry.ResponseTime)
+		staleTTL   time.Duration
+	)
+	if ttl < 0 {
+		ttl = 0
+	}
+	if entry.Header.Get(httpHeaderETag) != "" || entry.Header.Get(httpHeaderLastModified) != "" {
+		staleTTL = h.options.StaleTTL
+	}
+	for _, name := range []string{"stale-while-revalidate", "stale-if-error"} {
+		if v, ok := responseCC.seconds(name); ok && v > staleTTL {
+			staleTTL = v
+		}
+	}
+	if ttl += staleTTL; ttl <= 0 {
+		return
+	}
+	var (
+		varyNames = getCacheVaryNames(entry.Header)
+		entryKey  = key + "|" + getCacheVaryKey(varyNames, r.Header)
+	)
+	// The primary key stores the header names of "Vary" for looking up.
+	if err := h.options.Adapter.Set(ctx, key, strings.Join(varyNames, ","), ttl); err != nil {
+		intlog.Errorf(ctx, `%+v`, err)
+		return
+	}
+	if err := h.options.Adapter.Set(ctx, entryKey, entry, ttl); err != nil {
+		intlog.Errorf(ctx, `%+v`, err)
+	}
+}
+
+// primaryKey returns the cache key of the URL of request `r`. The host is replaced with the service
+// name for requests with discovery, so that the endpoints of the service share the cache.
+func (h *httpCache) primaryKey(r *http.Request) string {
+	u := *r.URL
+	if name, ok := r.Context().Value(ctxKeyDiscoveryService{}).(string); ok {
+		u.Host = name
+	}
+	return h.options.Prefix + u.String()
+}
+
+// freshnessLifetime calculates the freshness lifetime of the response by RFC 9111 section 4.2.1.
+func (e *httpCacheEntry) freshnessLifetime(cc cacheControl) time.Duration {
+	if maxAge, ok := cc.seconds("max-age"); ok {
+		return maxAge
+	}
+	if expires := e.Header.Get(httpHeaderExpires); expires != "" {
+		t, err := http.ParseTime(expires)
+		if err != nil {
+			return 0
+		}
+		return t.Sub(e.date())
+	}
+	// Heuristic freshness is 10% of the time since the last modification.
+	if _, ok := cacheableStatusCodes[e.StatusCode]; ok {
+		if t, err := http.ParseTime(e.Header.Get(httpHeaderLastModified)); err == nil {
+			if lifetime := e.date().Sub(t) / 10; lifetime > 0 {
+				return lifetime
+			}
+		}
+	}
+	return 0
+}
+
+// currentAge calculates the age of the response at `now` by RFC 9111 section 4.2.3.
+func (e *httpCacheEntry) currentAge(now time.Time) time.Duration {
+	var (
+		apparentAge = e.ResponseTime.Sub(e.date())
+		ageValue, _ = strconv.Atoi(e.Header.Get(httpHeaderAge))
+		correctAge  = time.Duration(ageValue)*time.Second + e.ResponseTime.Sub(e.RequestTime)
+	)
+	if apparentAge < 0 {
+		apparentAge = 0
+	}
+	if correctAge < apparentAge {
+		correctAge = apparentAge
+	}
+	return correctAge + now.Sub(e.ResponseTime)
+}
+
+// date returns the time of header "Date", or the response time if it is absent.
+func (e *httpCacheEntry) date() time.Time {
+	if t, err := http.ParseTime(e.Header.Get(httpHeaderDate)); err == nil {
+		return t
+	}
+	return e.ResponseTime
+}
+
+// toResponse creates and returns the response of request `r` from the cached entry with `age`.
+func (e *httpCacheEntry) toResponse(r *http.Request, age time.Duration) *Response {
+	header := e.Header.Clone()
+	header.Set(httpHeaderAge, strconv.Itoa(int(age/time.Second)))
+	return &Response{
+		Response: &http.Response{
+			Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+			StatusCode:    e.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader(e.Body)),
+			ContentLength: int64(len(e.Body)),
+			Request:       r,
+		},
+		request: r,
+	}
+}
+
+// newCacheGatewayTimeoutResponse creates and returns the 504 response for requests with directive
+// "only-if-cached" that are not satisfied by the cache.
+func newCacheGatewayTimeoutResponse(r *http.Request) *Response {
+	entry := &httpCacheEntry{
+		StatusCode: http.StatusGatewayTimeout,
+		Header:     make(http.Header),
+	}
+	resp := entry.toResponse(r, 0)
+	resp.Header.Del(httpHeaderAge)
+	return resp
+}
+
+// cacheReadCloser is the response body that is partially read.
+type cacheReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// cacheErrReader is the reader that always returns the error of reading the response body.
+type cacheErrReader struct {
+	err error
+}
+
+// Read implements interface io.Reader.
+func (r cacheErrReader) Read(p []byte) (int, error) {
+	return 0, r.err
+}
+
+// parseCacheControl parses and returns the directives of header "Cache-Control" in lower case.
+func parseCacheControl(header http.Header) cacheControl {
+	cc := make(cacheControl)
+	for _, value := range header.Values(httpHeaderCacheControl) {
+		for _, directive := range strings.Split(value, ",") {
+			name, arg, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+				cc[name] = strings.Trim(strings.TrimSpace(arg), `"`)
+			}
+		}
+	}
+	return cc
+}
+
+// has checks whether the directive `name` exists.
+func (cc cacheControl) has(name string) bool {
+	_, ok := cc[name]
+	return ok
+}
+
+// seconds returns the argument of directive `name` as seconds.
+func (cc cacheControl) seconds(name string) (time.Duration, bool) {
+	arg, ok := cc[name]
+	if !ok {
+		return 0, false
+	}
+	seconds, err := strconv.Atoi(arg)
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+// getCacheVaryNames returns the sorted canonical header names listed by header "Vary".
+func getCacheVaryNames(header http.Header) []string {
+	var names []string
+	for _, value := range header.Values(httpHeaderVary) {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// splitCacheVaryNames splits the header names of "Vary" joined by ",".
+func splitCacheVaryNames(joined string) []string {
+	if joined == "" {
+		return nil
+	}
+	return strings.Split(joined, ",")
+}
+
+// getCacheVaryKey returns the key of request header values selected by `names`.
+func getCacheVaryKey(names []string, header http.Header) string {
+	var parts = make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, name+"="+strings.Join(header.Values(name), ","))
+	}
+	return strings.Join(parts, "&")
+}
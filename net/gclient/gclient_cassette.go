@@ -0,0 +1,497 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gclient
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gogf/gf/v2"
+	"github.com/gogf/gf/v2/encoding/gyaml"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/internal/json"
+	"github.com/gogf/gf/v2/os/gfile"
+)
+
+// CassetteMode is the mode of cassette.
+type CassetteMode int
+
+const (
+	// CassetteModeReplay serves the recorded responses without network,
+	// and fails the requests matching no recorded interaction.
+	CassetteModeReplay CassetteMode = 0
+
+	// CassetteModeRecord sends the requests to network, and records the request/response
+	// pairs to the cassette file, which overwrites the existing file.
+	CassetteModeRecord CassetteMode = 1
+)
+
+// CassetteOptions is the options for Client.SetCassette.
+type CassetteOptions struct {
+	// Path specifies the cassette file path. The file is in HAR format if it has extension ".har",
+	// or else in YAML format that has the same structure as HAR.
+	Path string
+
+	// Mode specifies the mode of cassette. It's CassetteModeReplay in default.
+	Mode CassetteMode
+
+	// MatchHeaders specifies the request headers that are matched in replay mode,
+	// besides the method, URL and body of requests.
+	MatchHeaders []string
+
+	// RedactHeaders specifies the headers whose values are redacted in recording.
+	// It's "Authorization", "Proxy-Authorization", "Cookie" and "Set-Cookie" in default.
+	RedactHeaders []string
+
+	// RedactPatterns specifies the regular expressions of request and response bodies that are redacted
+	// in recording, like `"password":"(.*?)"`. It redacts the sub matches if the expression has groups,
+	// or else the whole matches. The request bodies are redacted in the same way in replay mode for matching.
+	RedactPatterns []string
+}
+
+// cassette stores the recorded interactions.
+type cassette struct {
+	mu             sync.Mutex
+	options        CassetteOptions
+	redactHeaders  map[string]struct{} // Canonical header names that are redacted.
+	redactPatterns []*regexp.Regexp    // Compiled RedactPatterns.
+	har            cassetteHar         // Recorded interactions.
+	replayed       map[int]struct{}    // Indexes of entries that are replayed.
+}
+
+// cassetteTransport is the transport that records or replays requests using cassette.
+type cassetteTransport struct {
+	http.RoundTripper // Underlying transport for sending requests in record mode.
+	cassette          *cassette
+}
+
+// cassetteHar is the HAR 1.2 document of cassette, see http://www.softwareishard.com/blog/har-12-spec/.
+type cassetteHar struct {
+	Log cassetteLog `json:"log" yaml:"log"`
+}
+
+type cassetteLog struct {
+	Version string           `json:"version" yaml:"version"`
+	Creator cassetteCreator  `json:"creator" yaml:"creator"`
+	Entries []*cassetteEntry `json:"entries" yaml:"entries"`
+}
+
+type cassetteCreator struct {
+	Name    string `json:"name" yaml:"name"`
+	Version string `json:"version" yaml:"version"`
+}
+
+type cassetteEntry struct {
+	StartedDateTime string           `json:"startedDateTime" yaml:"startedDateTime"`
+	Time            float64          `json:"time" yaml:"time"`
+	Request         cassetteRequest  `json:"request" yaml:"request"`
+	Response        cassetteResponse `json:"response" yaml:"response"`
+	Cache           struct{}         `json:"cache" yaml:"cache"`
+	Timings         cassetteTimings  `json:"timings" yaml:"timings"`
+}
+
+type cassetteRequest struct {
+	Method      string              `json:"method" yaml:"method"`
+	URL         string              `json:"url" yaml:"url"`
+	HTTPVersion string              `json:"httpVersion" yaml:"httpVersion"`
+	Cookies     []cassetteNameValue `json:"cookies" yaml:"cookies"`
+	Headers     []cassetteNameValue `json:"headers" yaml:"headers"`
+	QueryString []cassetteNameValue `json:"queryString" yaml:"queryString"`
+	PostData    *cassettePostData   `json:"postData,omitempty" yaml:"postData,omitempty"`
+	HeadersSize int                 `json:"headersSize" yaml:"headersSize"`
+	BodySize    int                 `json:"bodySize" yaml:"bodySize"`
+}
+
+type cassetteResponse struct {
+	Status      int                 `json:"status" yaml:"status"`
+	StatusText  string              `json:"statusText" yaml:"statusText"`
+	HTTPVersion string              `json:"httpVersion" yaml:"httpVersion"`
+	Cookies     []cassetteNameValue `json:"cookies" yaml:"cookies"`
+	Headers     []cassetteNameValue `json:"headers" yaml:"headers"`
+	Content     cassetteContent     `json:"content" yaml:"content"`
+	RedirectURL string              `json:"redirectURL" yaml:"redirectURL"`
+	HeadersSize int                 `json:"headersSize" yaml:"headersSize"`
+	BodySize    int                 `json:"bodySize" yaml:"bodySize"`
+}
+
+type cassetteNameValue struct {
+	Name  string `json:"name" yaml:"name"`
+	Value string `json:"value" yaml:"value"`
+}
+
+type cassettePostData struct {
+	MimeType string `json:"mimeType" yaml:"mimeType"`
+	Text     string `json:"text" yaml:"text"`
+	Encoding string `json:"_encoding,omitempty" yaml:"_encoding,omitempty"` // Custom field for binary body.
+}
+
+type cassetteContent struct {
+	Size     int    `json:"size" yaml:"size"`
+	MimeType string `json:"mimeType" yaml:"mimeType"`
+	Text     string `json:"text" yaml:"text"`
+	Encoding string `json:"encoding,omitempty" yaml:"encoding,omitempty"`
+}
+
+type cassetteTimings struct {
+	Send    float64 `json:"send" yaml:"send"`
+	Wait    float64 `json:"wait" yaml:"wait"`
+	Receive float64 `json:"receive" yaml:"receive"`
+}
+
+const (
+	cassetteRedacted       = "[REDACTED]"
+	cassetteHarVersion     = "1.2"
+	cassetteCreatorName    = "gclient"
+	cassetteEncodingBase64 = "base64"
+)
+
+// defaultCassetteRedactHeaders is the headers redacted in default.
+var defaultCassetteRedactHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+}
+
+// newCassette creates and returns a cassette, which loads the recorded interactions in replay mode.
+func newCassette(options CassetteOptions) (*cassette, error) {
+	if options.Path == "" {
+		return nil, gerror.NewCode(gcode.CodeInvalidParameter, `cassette path cannot be empty`)
+	}
+	if options.RedactHeaders == nil {
+		options.RedactHeaders = defaultCassetteRedactHeaders
+	}
+	c := &cassette{
+		options:       options,
+		redactHeaders: make(map[string]struct{}, len(options.RedactHeaders)),
+		replayed:      make(map[int]struct{}),
+		har: cassetteHar{Log: cassetteLog{
+			Version: cassetteHarVersion,
+			Creator: cassetteCreator{Name: cassetteCreatorName, Version: gf.VERSION},
+			Entries: make([]*cassetteEntry, 0),
+		}},
+	}
+	for _, name := range options.RedactHeaders {
+		c.redactHeaders[http.CanonicalHeaderKey(name)] = struct{}{}
+	}
+	for _, pattern := range options.RedactPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, gerror.WrapCodef(gcode.CodeInvalidParameter, err, `invalid redact pattern "%s"`, pattern)
+		}
+		c.redactPatterns = append(c.redactPatterns, re)
+	}
+	if options.Mode == CassetteModeRecord {
+		return c, nil
+	}
+	if !gfile.Exists(options.Path) {
+		return nil, gerror.NewCodef(gcode.CodeNotFound, `cassette file "%s" not found`, options.Path)
+	}
+	var (
+		err     error
+		content = gfile.GetBytes(options.Path)
+	)
+	if c.isHar() {
+		err = json.Unmarshal(content, &c.har)
+	} else {
+		err = gyaml.DecodeTo(content, &c.har)
+	}
+	if err != nil {
+		return nil, gerror.Wrapf(err, `load cassette file "%s" failed`, options.Path)
+	}
+	return c, nil
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *cassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		_ = req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	if t.cassette.options.Mode != CassetteModeRecord {
+		return t.cassette.replay(req, body)
+	}
+	transport := t.RoundTripper
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	start := time.Now()
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	if err = t.cassette.record(req, body, resp, respBody, start); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// CloseIdleConnections closes the idle connections of underlying transport.
+func (t *cassetteTransport) CloseIdleConnections() {
+	if v, ok := t.RoundTripper.(interface{ CloseIdleConnections() }); ok {
+		v.CloseIdleConnections()
+	}
+}
+
+// record records the interaction of request `req` and response `resp`, and saves the cassette file.
+func (c *cassette) record(req *http.Request, body []byte, resp *http.Response, respBody []byte, start time.Time) error {
+	var (
+		elapsed = float64(time.Since(start)) / float64(time.Millisecond)
+		entry   = &cassetteEntry{
+			StartedDateTime: start.Format(time.RFC3339Nano),
+			Time:            elapsed,
+			Request: cassetteRequest{
+				Method:      req.Method,
+				URL:         req.URL.String(),
+				HTTPVersion: req.Proto,
+				Cookies:     make([]cassetteNameValue, 0),
+				Headers:     c.recordHeaders(req.Header),
+				QueryString: make([]cassetteNameValue, 0),
+				HeadersSize: -1,
+				BodySize:    len(body),
+			},
+			Response: cassetteResponse{
+				Status:      resp.StatusCode,
+				StatusText:  http.StatusText(resp.StatusCode),
+				HTTPVersion: resp.Proto,
+				Cookies:     make([]cassetteNameValue, 0),
+				Headers:     c.recordHeaders(resp.Header),
+				RedirectURL: resp.Header.Get("Location"),
+				HeadersSize: -1,
+				BodySize:    len(respBody),
+			},
+			Timings: cassetteTimings{Wait: elapsed},
+		}
+	)
+	for name, values := range req.URL.Query() {
+		for _, value := range values {
+			entry.Request.QueryString = append(entry.Request.QueryString, cassetteNameValue{Name: name, Value: value})
+		}
+	}
+	sort.Slice(entry.Request.QueryString, func(i, j int) bool {
+		return entry.Request.QueryString[i].Name < entry.Request.QueryString[j].Name
+	})
+	if len(body) > 0 {
+		text, encoding := encodeCassetteBody(c.redactBody(body))
+		entry.Request.PostData = &cassettePostData{
+			MimeType: req.Header.Get(httpHeaderContentType),
+			Text:     text,
+			Encoding: encoding,
+		}
+	}
+	text, encoding := encodeCassetteBody(c.redactBody(respBody))
+	entry.Response.Content = cassetteContent{
+		Size:     len(respBody),
+		MimeType: resp.Header.Get(httpHeaderContentType),
+		Text:     text,
+		Encoding: encoding,
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.har.Log.Entries = append(c.har.Log.Entries, entry)
+	var (
+		err     error
+		content []byte
+	)
+	if c.isHar() {
+		content, err = json.MarshalIndent(c.har, "", "  ")
+	} else {
+		content, err = gyaml.Encode(c.har)
+	}
+	if err != nil {
+		return gerror.Wrapf(err, `encode cassette "%s" failed`, c.options.Path)
+	}
+	if err = gfile.PutBytes(c.options.Path, content); err != nil {
+		return gerror.Wrapf(err, `save cassette file "%s" failed`, c.options.Path)
+	}
+	return nil
+}
+
+// replay returns the recorded response of the interaction matching request `req`.
+// The unreplayed interactions are preferred if multiple ones match, so that the same requests
+// are replayed in recording order.
+func (c *cassette) replay(req *http.Request, body []byte) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var (
+		matched = -1
+		text, _ = encodeCassetteBody(c.redactBody(body))
+	)
+	for i, entry := range c.har.Log.Entries {
+		if !c.isMatched(entry, req, text) {
+			continue
+		}
+		if _, ok := c.replayed[i]; !ok {
+			matched = i
+			break
+		}
+		if matched == -1 {
+			matched = i
+		}
+	}
+	if matched == -1 {
+		return nil, gerror.NewCodef(
+			gcode.CodeNotFound,
+			`no interaction in cassette "%s" matches request: %s %s`,
+			c.options.Path, req.Method, req.URL.String(),
+		)
+	}
+	c.replayed[matched] = struct{}{}
+	var (
+		entry  = c.har.Log.Entries[matched]
+		header = make(http.Header)
+	)
+	for _, h := range entry.Response.Headers {
+		header.Add(h.Name, h.Value)
+	}
+	respBody, err := decodeCassetteBody(entry.Response.Content.Text, entry.Response.Content.Encoding)
+	if err != nil {
+		return nil, gerror.Wrapf(err, `decode response body in cassette "%s" failed`, c.options.Path)
+	}
+	header.Del("Content-Length")
+	resp := &http.Response{
+		Status:        fmt.Sprintf("%d %s", entry.Response.Status, entry.Response.StatusText),
+		StatusCode:    entry.Response.Status,
+		Proto:         entry.Response.HTTPVersion,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(respBody)),
+		ContentLength: int64(len(respBody)),
+		Request:       req,
+	}
+	var ok bool
+	if resp.ProtoMajor, resp.ProtoMinor, ok = http.ParseHTTPVersion(resp.Proto); !ok {
+		resp.Proto, resp.ProtoMajor, resp.ProtoMinor = "HTTP/1.1", 1, 1
+	}
+	return resp, nil
+}
+
+// isMatched checks whether the recorded `entry` matches request `req` with redacted body `text`.
+func (c *cassette) isMatched(entry *cassetteEntry, req *http.Request, text string) bool {
+	if entry.Request.Method != req.Method || entry.Request.URL != req.URL.String() {
+		return false
+	}
+	var recordedText string
+	if entry.Request.PostData != nil {
+		recordedText = entry.Request.PostData.Text
+	}
+	if recordedText != text {
+		return false
+	}
+	for _, name := range c.options.MatchHeaders {
+		name = http.CanonicalHeaderKey(name)
+		var recordedValues []string
+		for _, h := range entry.Request.Headers {
+			if http.CanonicalHeaderKey(h.Name) == name {
+				recordedValues = append(recordedValues, h.Value)
+			}
+		}
+		if strings.Join(recordedValues, ",") != strings.Join(c.redactHeaderValues(name, req.Header.Values(name)), ",") {
+			return false
+		}
+	}
+	return true
+}
+
+// recordHeaders returns the redacted headers sorted by name.
+func (c *cassette) recordHeaders(header http.Header) []cassetteNameValue {
+	var (
+		names   = make([]string, 0, len(header))
+		headers = make([]cassetteNameValue, 0, len(header))
+	)
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range c.redactHeaderValues(name, header[name]) {
+			headers = append(headers, cassetteNameValue{Name: name, Value: value})
+		}
+	}
+	return headers
+}
+
+// redactHeaderValues returns the redacted values of header `name`.
+func (c *cassette) redactHeaderValues(name string, values []string) []string {
+	if _, ok := c.redactHeaders[http.CanonicalHeaderKey(name)]; !ok {
+		return values
+	}
+	redacted := make([]string, len(values))
+	for i := range values {
+		redacted[i] = cassetteRedacted
+	}
+	return redacted
+}
+
+// redactBody returns the body redacted by RedactPatterns.
+func (c *cassette) redactBody(body []byte) []byte {
+	for _, re := range c.redactPatterns {
+		if re.NumSubexp() == 0 {
+			body = re.ReplaceAll(body, []byte(cassetteRedacted))
+			continue
+		}
+		var (
+			buffer bytes.Buffer
+			last   int
+		)
+		for _, match := range re.FindAllSubmatchIndex(body, -1) {
+			for i := 2; i < len(match); i += 2 {
+				if match[i] < last {
+					continue
+				}
+				buffer.Write(body[last:match[i]])
+				buffer.WriteString(cassetteRedacted)
+				last = match[i+1]
+			}
+		}
+		buffer.Write(body[last:])
+		body = buffer.Bytes()
+	}
+	return body
+}
+
+// isHar checks whether the cassette file is in HAR format.
+func (c *cassette) isHar() bool {
+	return strings.EqualFold(gfile.Ext(c.options.Path), ".har")
+}
+
+// encodeCassetteBody encodes `body` as text, which is in base64 if it is not valid UTF-8.
+func encodeCassetteBody(body []byte) (text, encoding string) {
+	if utf8.Valid(body) {
+		return string(body), ""
+	}
+	return base64.StdEncoding.EncodeToString(body), cassetteEncodingBase64
+}
+
+// decodeCassetteBody decodes body from `text` in `encoding`.
+func decodeCassetteBody(text, encoding string) ([]byte, error) {
+	if encoding == cassetteEncodingBase64 {
+		return base64.StdEncoding.DecodeString(text)
+	}
+	return []byte(text), nil
+}
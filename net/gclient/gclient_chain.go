@@ -7,6 +7,7 @@
 package gclient
 
 import (
+	"net/http"
 	"time"
 
 	"github.com/gogf/gf/v2/net/gsvc"
@@ -108,6 +109,39 @@ func (c *Client) Retry(retryCount int, retryInterval time.Duration) *Client {
 	return newClient
 }
 
+// RetryPolicy is a chaining function,
+// which sets the retry policy for failed requests of next request.
+func (c *Client) RetryPolicy(policy RetryPolicy) *Client {
+	newClient := c.Clone()
+	newClient.SetRetryPolicy(policy)
+	return newClient
+}
+
+// CookieJar is a chaining function,
+// which sets the cookie jar for next request.
+func (c *Client) CookieJar(jar http.CookieJar) *Client {
+	newClient := c.Clone()
+	newClient.SetCookieJar(jar)
+	return newClient
+}
+
+// HedgePolicy is a chaining function,
+// which sets the hedging policy of next request.
+func (c *Client) HedgePolicy(policy HedgePolicy) *Client {
+	newClient := c.Clone()
+	newClient.SetHedgePolicy(policy)
+	return newClient
+}
+
+// HostLimit is a chaining function,
+// which sets the limit of in-flight requests per host of next request.
+// Note that the limit is shared with the client it's chained from only if it's set before.
+func (c *Client) HostLimit(options HostLimitOptions) *Client {
+	newClient := c.Clone()
+	newClient.SetHostLimit(options)
+	return newClient
+}
+
 // Proxy is a chaining function,
 // which sets proxy for next request.
 // Make sure you pass the correct `proxyURL`.
@@ -127,6 +161,14 @@ func (c *Client) RedirectLimit(redirectLimit int) *Client {
 	return newClient
 }
 
+// H2C is a chaining function,
+// which enables cleartext HTTP/2 (h2c) with prior knowledge for next request.
+func (c *Client) H2C() *Client {
+	newClient := c.Clone()
+	newClient.SetH2C(true)
+	return newClient
+}
+
 // NoUrlEncode sets the mark that do not encode the parameters before sending request.
 func (c *Client) NoUrlEncode() *Client {
 	newClient := c.Clone()
@@ -0,0 +1,269 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gogf/gf/v2/container/gmap"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/internal/breaker"
+	"github.com/gogf/gf/v2/net/gsel"
+	"github.com/gogf/gf/v2/os/gmetric"
+)
+
+// CircuitBreakerOptions is the options for MiddlewareCircuitBreaker.
+type CircuitBreakerOptions struct {
+	// Scope specifies the granularity of breakers. It's CircuitBreakerScopeHost in default.
+	Scope CircuitBreakerScope
+
+	// ErrorRate specifies the ratio of failed requests in the statistics window that trips the breaker.
+	// It's 0.5 in default.
+	ErrorRate float64
+
+	// SlowThreshold specifies the duration over which the request is considered slow.
+	// It's 0 in default, which means the breaker does not trip on latency.
+	SlowThreshold time.Duration
+
+	// SlowRate specifies the ratio of slow requests in the statistics window that trips the breaker.
+	// It takes effect only if SlowThreshold is positive. It's 0.5 in default.
+	SlowRate float64
+
+	// MinRequests specifies the minimum number of requests in the statistics window
+	// before the breaker calculates the ratios. It's 20 in default.
+	MinRequests int
+
+	// Window specifies the duration of the statistics window. It's 10 seconds in default.
+	Window time.Duration
+
+	// OpenTimeout specifies the duration that the breaker keeps open before it allows
+	// trial requests in half-open state. It's 30 seconds in default.
+	OpenTimeout time.Duration
+
+	// HalfOpenRequests specifies the number of trial requests in half-open state.
+	// The breaker closes if all of them succeed, or else it opens again. It's 1 in default.
+	HalfOpenRequests int
+
+	// IsFailure checks whether the request is failed by its response `resp` and error `err`.
+	// It's failed in default if the request returns an error except context canceling,
+	// or the response status is 5XX.
+	IsFailure func(resp *Response, err error) bool
+}
+
+// CircuitBreakerScope is the granularity of circuit breakers.
+type CircuitBreakerScope int
+
+const (
+	// CircuitBreakerScopeHost creates a breaker for each upstream host, which is "host:port" of request URL.
+	// For the requests to services with discovery, it is the address of the picked endpoint, and the
+	// endpoints whose breakers are open are skipped by the selector.
+	CircuitBreakerScopeHost CircuitBreakerScope = 0
+
+	// CircuitBreakerScopeService creates a breaker for each service from discovery, which trips for all
+	// the endpoints of the service. It falls back to CircuitBreakerScopeHost for requests without discovery.
+	CircuitBreakerScopeService CircuitBreakerScope = 1
+)
+
+const (
+	metricAttrKeyServiceName = "service.name"
+)
+
+var (
+	// ErrCircuitBreakerOpen is the error that indicates the request is rejected as the circuit breaker is open.
+	ErrCircuitBreakerOpen = gerror.NewWithOption(gerror.Option{
+		Text: "circuit breaker is open",
+		Code: gcode.CodeServerBusy,
+	})
+)
+
+// ctxKeyDiscoveryService is the context key for the name of service picked by discovery.
+type ctxKeyDiscoveryService struct{}
+
+// ctxKeyEndpointFilter is the context key for the gsel.NodeFilter of circuit breaker middleware,
+// which skips the endpoints whose breakers are open.
+type ctxKeyEndpointFilter struct{}
+
+// MiddlewareCircuitBreaker creates and returns a middleware for Client.Use that fails fast the requests
+// to a degraded upstream for a while, which gives it time to recover.
+//
+// The breaker of each upstream host or service trips to open state if the ratio of failed requests or
+// slow requests in the statistics window exceeds the threshold. In open state, the requests fail at once
+// with error of code gcode.CodeServerBusy, which wraps ErrCircuitBreakerOpen. After OpenTimeout, it turns
+// to half-open state that allows limited trial requests, and closes if they succeed.
+//
+// The state of breakers is exposed as metric "http.client.circuit_breaker.state", and the rejected requests
+// are counted by metric "http.client.circuit_breaker.rejected".
+func MiddlewareCircuitBreaker(options ...CircuitBreakerOptions) HandlerFunc {
+	var (
+		breakerOptions CircuitBreakerOptions
+		breakers       sync.Map                     // Host or service name -> *circuitBreaker
+		tripped        = gmap.NewStrAnyMap(true)    // Endpoint address -> *breaker.Breaker that is open.
+		filter         = newEndpointFilter(tripped) // Filter skipping the endpoints in `tripped`.
+	)
+	if len(options) > 0 {
+		breakerOptions = options[0]
+	}
+	if breakerOptions.IsFailure == nil {
+		breakerOptions.IsFailure = isCircuitBreakerFailure
+	}
+	return func(c *Client, r *http.Request) (resp *Response, err error) {
+		var (
+			key      = r.URL.Host
+			isHost   = true
+			ctx      = r.Context()
+			v, found = ctx.Value(ctxKeyDiscoveryService{}).(string)
+		)
+		if breakerOptions.Scope == CircuitBreakerScopeService && found {
+			key = v
+			isHost = false
+		} else if found {
+			// The endpoint picked by discovery might be tripped, it then picks another one.
+			if done := repickEndpoint(r, filter); done != nil {
+				defer done()
+			}
+			key = r.URL.Host
+		}
+		// The hedged requests also skip the tripped endpoints.
+		r = r.WithContext(context.WithValue(ctx, ctxKeyEndpointFilter{}, filter))
+		b, ok := breakers.Load(key)
+		if !ok {
+			b, _ = breakers.LoadOrStore(key, newCircuitBreaker(r, key, isHost, tripped, breakerOptions))
+		}
+		cb := b.(*circuitBreaker)
+		if !cb.Allow() {
+			c.handleMetricsCircuitBreakerRejected(r, cb.metricOption)
+			return nil, gerror.WrapCodef(
+				gcode.CodeServerBusy, ErrCircuitBreakerOpen, `request to "%s" rejected`, key,
+			)
+		}
+		start := time.Now()
+		resp, err = c.Next(r)
+		cb.Done(breakerOptions.IsFailure(resp, err), time.Since(start))
+		return resp, err
+	}
+}
+
+// circuitBreaker is the circuit breaker of an upstream host or service.
+type circuitBreaker struct {
+	*breaker.Breaker
+	metricOption gmetric.Option
+}
+
+// newCircuitBreaker creates and returns a circuit breaker of `key` for request `r`.
+// The breaker of host records its open state in `tripped`.
+func newCircuitBreaker(
+	r *http.Request, key string, isHost bool, tripped *gmap.StrAnyMap, options CircuitBreakerOptions,
+) *circuitBreaker {
+	var (
+		ctx = context.WithoutCancel(r.Context())
+		cb  = &circuitBreaker{}
+	)
+	if gmetric.IsEnabled() {
+		if isHost {
+			cb.metricOption = metricManager.GetMetricOptionForHistogram(r)
+		} else {
+			cb.metricOption = gmetric.Option{
+				Attributes: gmetric.Attributes{gmetric.NewAttribute(metricAttrKeyServiceName, key)},
+			}
+		}
+	}
+	cb.Breaker = breaker.New(breaker.Options{
+		ErrorRate:        options.ErrorRate,
+		SlowThreshold:    options.SlowThreshold,
+		SlowRate:         options.SlowRate,
+		MinRequests:      options.MinRequests,
+		Window:           options.Window,
+		OpenTimeout:      options.OpenTimeout,
+		HalfOpenRequests: options.HalfOpenRequests,
+		OnStateChange: func(from, to breaker.State) {
+			if isHost {
+				if to == breaker.StateOpen {
+					tripped.Set(key, cb.Breaker)
+				} else {
+					tripped.Remove(key)
+				}
+			}
+			handleMetricsCircuitBreakerState(ctx, cb.metricOption, from, to)
+		},
+	})
+	return cb
+}
+
+// isCircuitBreakerFailure is the default function checking whether the request is failed.
+func isCircuitBreakerFailure(resp *Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+	return resp != nil && resp.StatusCode >= http.StatusInternalServerError
+}
+
+// newEndpointFilter creates and returns a gsel.NodeFilter that skips the endpoints whose breakers
+// in `tripped` are open.
+func newEndpointFilter(tripped *gmap.StrAnyMap) gsel.NodeFilter {
+	return func(ctx context.Context, node gsel.Node) bool {
+		if v := tripped.Get(node.Address()); v != nil {
+			openUntil := v.(*breaker.Breaker).OpenUntil()
+			return openUntil.IsZero() || !time.Now().Before(openUntil)
+		}
+		return true
+	}
+}
+
+// isEndpointAvailable checks whether `node` is not skipped by the filter of circuit breaker
+// middleware in `ctx`.
+func isEndpointAvailable(ctx context.Context, node gsel.Node) bool {
+	if filter, ok := ctx.Value(ctxKeyEndpointFilter{}).(gsel.NodeFilter); ok {
+		return filter(ctx, node)
+	}
+	return true
+}
+
+// repickEndpoint picks another endpoint for `r` using discovery selector if the picked one is skipped
+// by `filter`, and returns the function that should be called after the request is done. It keeps the
+// picked endpoint if all of them are skipped, which lets the breaker fail fast.
+func repickEndpoint(r *http.Request, filter gsel.NodeFilter) func() {
+	var (
+		ctx         = r.Context()
+		selector, _ = ctx.Value(ctxKeyDiscoverySelector{}).(gsel.Selector)
+	)
+	if selector == nil || filter(ctx, &discoveryNode{address: r.URL.Host}) {
+		return nil
+	}
+	node, done, err := selector.Pick(gsel.WithNodeFilter(ctx, filter))
+	if err != nil || node == nil {
+		return nil
+	}
+	r.Host = node.Address()
+	r.URL.Host = node.Address()
+	if done == nil {
+		return nil
+	}
+	return func() {
+		done(ctx, gsel.DoneInfo{})
+	}
+}
+
+func (c *Client) handleMetricsCircuitBreakerRejected(r *http.Request, option gmetric.Option) {
+	if !gmetric.IsEnabled() {
+		return
+	}
+	metricManager.HttpClientCircuitBreakerRejected.Inc(r.Context(), option)
+}
+
+// handleMetricsCircuitBreakerState records the state change of breaker,
+// which makes the metric value of host or service the number of current state.
+func handleMetricsCircuitBreakerState(ctx context.Context, option gmetric.Option, from, to breaker.State) {
+	if !gmetric.IsEnabled() {
+		return
+	}
+	metricManager.HttpClientCircuitBreakerState.Add(ctx, float64(to-from), option)
+}
@@ -37,6 +37,13 @@ func (c *Client) SetBrowserMode(enabled bool) *Client {
 	return c
 }
 
+// SetCookieJar sets the cookie jar like CookieJar, which saves and sends cookie content
+// from and to server. The jar is shared by the clients cloned from current client.
+func (c *Client) SetCookieJar(jar http.CookieJar) *Client {
+	c.Jar = jar
+	return c
+}
+
 // SetHeader sets a custom HTTP header pair for the client.
 func (c *Client) SetHeader(key, value string) *Client {
 	c.header[key] = value
@@ -115,6 +122,25 @@ func (c *Client) SetRetry(retryCount int, retryInterval time.Duration) *Client {
 	return c
 }
 
+// SetRetryPolicy sets the retry policy for failed requests, which replaces the retry settings of SetRetry.
+func (c *Client) SetRetryPolicy(policy RetryPolicy) *Client {
+	c.retryPolicy = &policy
+	return c
+}
+
+// SetHedgePolicy sets the hedging policy, which sends hedged requests for slow idempotent requests
+// to other nodes and takes the first response.
+func (c *Client) SetHedgePolicy(policy HedgePolicy) *Client {
+	c.hedger = newHedger(policy)
+	return c
+}
+
+// SetHostLimit sets the limit of in-flight requests per host, the exceeded requests wait in queue.
+func (c *Client) SetHostLimit(options HostLimitOptions) *Client {
+	c.hostLimiter = newHostLimiter(options)
+	return c
+}
+
 // SetRedirectLimit limits the number of jumps.
 func (c *Client) SetRedirectLimit(redirectLimit int) *Client {
 	c.CheckRedirect = func(req *http.Request, via []*http.Request) error {
@@ -132,6 +158,50 @@ func (c *Client) SetNoUrlEncode(noUrlEncode bool) *Client {
 	return c
 }
 
+// SetH2C enables/disables cleartext HTTP/2 (h2c) with prior knowledge for requests of "http" scheme,
+// which is used to communicate with servers serving h2c, like ghttp.Server with H2C enabled.
+// The requests of "https" scheme are still sent using the underlying transport.
+func (c *Client) SetH2C(enabled bool) *Client {
+	// The cassette transport keeps wrapping the others.
+	if v, ok := c.Transport.(*cassetteTransport); ok {
+		wrapped := *v
+		c.Transport = wrapped.RoundTripper
+		c.SetH2C(enabled)
+		wrapped.RoundTripper = c.Transport
+		c.Transport = &wrapped
+		return c
+	}
+	transport, ok := c.Transport.(*h2cTransport)
+	switch {
+	case enabled && !ok:
+		c.Transport = newH2cTransport(c.Transport)
+	case !enabled && ok:
+		c.Transport = transport.RoundTripper
+	}
+	return c
+}
+
+// SetCassette enables record/replay mode for client using cassette, which makes the integration tests
+// deterministic. In record mode, the client sends requests to network and writes the request/response
+// pairs to the cassette file with sensitive headers and bodies redacted. In replay mode, the client serves
+// the recorded responses without network, matching requests by method, URL, body and selected headers,
+// and fails the requests that match no recorded interaction with error of code gcode.CodeNotFound.
+func (c *Client) SetCassette(options CassetteOptions) error {
+	cassette, err := newCassette(options)
+	if err != nil {
+		return err
+	}
+	transport := c.Transport
+	if v, ok := transport.(*cassetteTransport); ok {
+		transport = v.RoundTripper
+	}
+	c.Transport = &cassetteTransport{
+		RoundTripper: transport,
+		cassette:     cassette,
+	}
+	return nil
+}
+
 // SetProxy set proxy for the client.
 // This func will do nothing when the parameter `proxyURL` is empty or in wrong pattern.
 // The correct pattern is like `http://USER:PASSWORD@IP:PORT` or `socks5://USER:PASSWORD@IP:PORT`.
@@ -146,7 +216,7 @@ func (c *Client) SetProxy(proxyURL string) {
 		return
 	}
 	if _proxy.Scheme == httpProtocolName {
-		if v, ok := c.Transport.(*http.Transport); ok {
+		if v, ok := c.getHttpTransport(); ok {
 			v.Proxy = http.ProxyURL(_proxy)
 		}
 	} else {
@@ -175,7 +245,7 @@ func (c *Client) SetProxy(proxyURL string) {
 			intlog.Errorf(context.TODO(), `%+v`, err)
 			return
 		}
-		if v, ok := c.Transport.(*http.Transport); ok {
+		if v, ok := c.getHttpTransport(); ok {
 			v.DialContext = func(ctx context.Context, network, addr string) (conn net.Conn, e error) {
 				return dialer.Dial(network, addr)
 			}
@@ -190,7 +260,7 @@ func (c *Client) SetTLSKeyCrt(crtFile, keyFile string) error {
 	if err != nil {
 		return gerror.Wrap(err, "LoadKeyCrt failed")
 	}
-	if v, ok := c.Transport.(*http.Transport); ok {
+	if v, ok := c.getHttpTransport(); ok {
 		tlsConfig.InsecureSkipVerify = true
 		v.TLSClientConfig = tlsConfig
 		return nil
@@ -200,13 +270,26 @@ func (c *Client) SetTLSKeyCrt(crtFile, keyFile string) error {
 
 // SetTLSConfig sets the TLS configuration of client.
 func (c *Client) SetTLSConfig(tlsConfig *tls.Config) error {
-	if v, ok := c.Transport.(*http.Transport); ok {
+	if v, ok := c.getHttpTransport(); ok {
 		v.TLSClientConfig = tlsConfig
 		return nil
 	}
 	return gerror.New(`cannot set TLSClientConfig for custom Transport of the client`)
 }
 
+// getHttpTransport returns the underlying *http.Transport of the client if any.
+func (c *Client) getHttpTransport() (*http.Transport, bool) {
+	transport := c.Transport
+	if v, ok := transport.(*cassetteTransport); ok {
+		transport = v.RoundTripper
+	}
+	if v, ok := transport.(*h2cTransport); ok {
+		transport = v.RoundTripper
+	}
+	v, ok := transport.(*http.Transport)
+	return v, ok
+}
+
 // SetBuilder sets the load balance builder for client.
 func (c *Client) SetBuilder(builder gsel.Builder) {
 	c.builder = builder
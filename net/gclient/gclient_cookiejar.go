@@ -0,0 +1,584 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gclient
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/idna"
+	"golang.org/x/net/publicsuffix"
+
+	"github.com/gogf/gf/v2/container/gtype"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/internal/intlog"
+	"github.com/gogf/gf/v2/internal/json"
+	"github.com/gogf/gf/v2/os/gcache"
+	"github.com/gogf/gf/v2/os/gfile"
+)
+
+// CookieJarOptions is the options for CookieJar.
+type CookieJarOptions struct {
+	// File specifies the file path that the cookies are persisted to in JSON.
+	File string
+
+	// Adapter specifies the cache adapter that the cookies are persisted to, which takes priority over File.
+	// Note that the adapter like redis can be shared by multiple processes.
+	Adapter gcache.Adapter
+
+	// Key specifies the key of cookies in Adapter. It's "gclient.cookiejar" in default.
+	Key string
+
+	// PublicSuffixList specifies the public suffix list, which rejects the cookies of domains like "co.uk".
+	// It's the list of "golang.org/x/net/publicsuffix" in default.
+	PublicSuffixList cookiejar.PublicSuffixList
+
+	// SaveInterval specifies the interval saving the changed cookies in background.
+	// It's 0 in default, which means the cookies are saved synchronously once changed.
+	SaveInterval time.Duration
+
+	// KeepSessionCookies specifies whether the session cookies without expiry are persisted,
+	// which are discarded on restart like browsers in default.
+	KeepSessionCookies bool
+}
+
+// CookieJarEntry is a cookie stored in CookieJar.
+type CookieJarEntry struct {
+	Name       string    `json:"name"`
+	Value      string    `json:"value"`
+	Domain     string    `json:"domain"`   // Domain without leading dot, or the host for host-only cookie.
+	Path       string    `json:"path"`     // Path of the cookie, it's "/" if empty.
+	HostOnly   bool      `json:"hostOnly"` // Whether the cookie is only sent to the host of Domain but not its subdomains.
+	Secure     bool      `json:"secure"`
+	HttpOnly   bool      `json:"httpOnly"`
+	Expires    time.Time `json:"expires"`    // Expiry time, it's zero for session cookie.
+	Creation   time.Time `json:"creation"`   // Creation time, which keeps unchanged when the cookie is updated.
+	LastAccess time.Time `json:"lastAccess"` // Last time the cookie is sent.
+}
+
+// CookieJar is a cookie jar implementing http.CookieJar, which follows the domain, path, secure
+// and expiry rules of RFC 6265 and rejects the cookies of public suffixes.
+//
+// Its cookies can be persisted to a file or cache adapter, and inspected and edited programmatically.
+// It can be shared by multiple clients, and it's shared by the clients cloned from the client using it.
+type CookieJar struct {
+	mu      sync.Mutex
+	saveMu  sync.Mutex // Serializes saving, so that the latest cookies are persisted at last.
+	options CookieJarOptions
+	entries map[string]map[string]*CookieJarEntry // Key of domain -> id of entry -> entry.
+	saving  *gtype.Bool                           // Whether the saving in background is scheduled.
+	created time.Time                             // Last creation time, which makes the creation times unique for ordering.
+}
+
+const (
+	defaultCookieJarKey = "gclient.cookiejar"
+)
+
+// NewCookieJar creates and returns a CookieJar, which loads the persisted cookies if any.
+func NewCookieJar(options ...CookieJarOptions) (*CookieJar, error) {
+	var jarOptions CookieJarOptions
+	if len(options) > 0 {
+		jarOptions = options[0]
+	}
+	if jarOptions.Key == "" {
+		jarOptions.Key = defaultCookieJarKey
+	}
+	if jarOptions.PublicSuffixList == nil {
+		jarOptions.PublicSuffixList = publicsuffix.List
+	}
+	j := &CookieJar{
+		options: jarOptions,
+		entries: make(map[string]map[string]*CookieJarEntry),
+		saving:  gtype.NewBool(),
+	}
+	if err := j.Load(context.Background()); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// SetCookies implements the http.CookieJar interface, which stores the `cookies` received from `u`.
+// The invalid cookies are ignored.
+func (j *CookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	if len(cookies) == 0 || (u.Scheme != "http" && u.Scheme != "https") {
+		return
+	}
+	host, err := cookieJarCanonicalHost(u.Host)
+	if err != nil {
+		return
+	}
+	var (
+		now     = time.Now()
+		changed = false
+	)
+	j.mu.Lock()
+	for _, cookie := range cookies {
+		entry, remove, err := j.newEntry(cookie, host, u.Path, now)
+		if err != nil {
+			intlog.Printf(context.Background(), `cookie "%s" from "%s" ignored: %s`, cookie.Name, host, err.Error())
+			continue
+		}
+		if remove {
+			changed = j.remove(entry.Domain, entry.Path, entry.Name) || changed
+			continue
+		}
+		j.put(entry, now)
+		changed = true
+	}
+	j.mu.Unlock()
+	if changed {
+		j.changed()
+	}
+}
+
+// Cookies implements the http.CookieJar interface, which returns the cookies sent to `u`.
+// The cookies with longer paths are listed first, and then the earlier created ones.
+func (j *CookieJar) Cookies(u *url.URL) []*http.Cookie {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil
+	}
+	host, err := cookieJarCanonicalHost(u.Host)
+	if err != nil {
+		return nil
+	}
+	var (
+		now     = time.Now()
+		path    = u.Path
+		https   = u.Scheme == "https"
+		matched = make([]*CookieJarEntry, 0)
+		expired = false
+	)
+	if path == "" {
+		path = "/"
+	}
+	j.mu.Lock()
+	submap := j.entries[j.domainKey(host)]
+	for id, entry := range submap {
+		if entry.isExpired(now) {
+			delete(submap, id)
+			expired = true
+			continue
+		}
+		if !entry.shouldSend(https, host, path) {
+			continue
+		}
+		entry.LastAccess = now
+		matched = append(matched, entry)
+	}
+	sort.Slice(matched, func(i, k int) bool {
+		if len(matched[i].Path) != len(matched[k].Path) {
+			return len(matched[i].Path) > len(matched[k].Path)
+		}
+		return matched[i].Creation.Before(matched[k].Creation)
+	})
+	cookies := make([]*http.Cookie, len(matched))
+	for i, entry := range matched {
+		cookies[i] = &http.Cookie{Name: entry.Name, Value: entry.Value}
+	}
+	j.mu.Unlock()
+	if expired {
+		j.changed()
+	}
+	return cookies
+}
+
+// Entries returns copies of all unexpired cookies sorted by domain, path and name.
+// It returns the cookies of `domain` and its subdomains only if `domain` is given.
+func (j *CookieJar) Entries(domain ...string) []CookieJarEntry {
+	var (
+		now     = time.Now()
+		filter  string
+		entries = make([]CookieJarEntry, 0)
+	)
+	if len(domain) > 0 {
+		filter = strings.TrimPrefix(strings.ToLower(domain[0]), ".")
+	}
+	j.mu.Lock()
+	for _, submap := range j.entries {
+		for _, entry := range submap {
+			if entry.isExpired(now) {
+				continue
+			}
+			if filter != "" && entry.Domain != filter && !cookieJarHasDotSuffix(entry.Domain, filter) {
+				continue
+			}
+			entries = append(entries, *entry)
+		}
+	}
+	j.mu.Unlock()
+	sort.Slice(entries, func(i, k int) bool {
+		if entries[i].Domain != entries[k].Domain {
+			return entries[i].Domain < entries[k].Domain
+		}
+		if entries[i].Path != entries[k].Path {
+			return entries[i].Path < entries[k].Path
+		}
+		return entries[i].Name < entries[k].Name
+	})
+	return entries
+}
+
+// Set adds or replaces the cookie of the same domain, path and name with `entry`.
+// The path is "/" if empty, and the creation time is kept if the cookie exists.
+func (j *CookieJar) Set(entry CookieJarEntry) error {
+	if entry.Name == "" || entry.Domain == "" {
+		return gerror.NewCode(gcode.CodeInvalidParameter, `cookie name and domain should not be empty`)
+	}
+	domain, err := cookieJarCanonicalHost(strings.TrimPrefix(entry.Domain, "."))
+	if err != nil {
+		return err
+	}
+	entry.Domain = domain
+	if entry.Path == "" || entry.Path[0] != '/' {
+		entry.Path = "/"
+	}
+	j.mu.Lock()
+	j.put(&entry, time.Now())
+	j.mu.Unlock()
+	j.changed()
+	return nil
+}
+
+// Delete deletes the cookie of `domain`, `path` and `name`, and returns whether it exists.
+func (j *CookieJar) Delete(domain, path, name string) bool {
+	j.mu.Lock()
+	removed := j.remove(strings.TrimPrefix(strings.ToLower(domain), "."), path, name)
+	j.mu.Unlock()
+	if removed {
+		j.changed()
+	}
+	return removed
+}
+
+// Clear deletes all cookies.
+func (j *CookieJar) Clear() {
+	j.mu.Lock()
+	j.entries = make(map[string]map[string]*CookieJarEntry)
+	j.mu.Unlock()
+	j.changed()
+}
+
+// Load replaces the cookies with the persisted ones. It does nothing if there's no persistence.
+func (j *CookieJar) Load(ctx context.Context) error {
+	var content []byte
+	switch {
+	case j.options.Adapter != nil:
+		v, err := j.options.Adapter.Get(ctx, j.options.Key)
+		if err != nil {
+			return err
+		}
+		if v.IsNil() {
+			return nil
+		}
+		content = v.Bytes()
+
+	case j.options.File != "":
+		if !gfile.Exists(j.options.File) {
+			return nil
+		}
+		content = gfile.GetBytes(j.options.File)
+
+	default:
+		return nil
+	}
+	var entries []*CookieJarEntry
+	if len(content) > 0 {
+		if err := json.Unmarshal(content, &entries); err != nil {
+			return gerror.Wrap(err, `invalid persisted cookies`)
+		}
+	}
+	now := time.Now()
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.entries = make(map[string]map[string]*CookieJarEntry)
+	for _, entry := range entries {
+		if entry.Name == "" || entry.Domain == "" || entry.isExpired(now) {
+			continue
+		}
+		j.put(entry, entry.Creation)
+	}
+	return nil
+}
+
+// Save persists the cookies. The expired cookies and the session cookies are not persisted,
+// unless KeepSessionCookies is enabled. It does nothing if there's no persistence.
+func (j *CookieJar) Save(ctx context.Context) error {
+	if j.options.Adapter == nil && j.options.File == "" {
+		return nil
+	}
+	j.saveMu.Lock()
+	defer j.saveMu.Unlock()
+	var (
+		now     = time.Now()
+		entries = make([]CookieJarEntry, 0)
+	)
+	for _, entry := range j.Entries() {
+		if entry.Expires.IsZero() && !j.options.KeepSessionCookies {
+			continue
+		}
+		if !entry.isExpired(now) {
+			entries = append(entries, entry)
+		}
+	}
+	content, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	if j.options.Adapter != nil {
+		return j.options.Adapter.Set(ctx, j.options.Key, content, 0)
+	}
+	// It writes a unique temporary file in the same directory and renames it,
+	// so that the file is never partially written.
+	dir := gfile.Dir(j.options.File)
+	if err = gfile.Mkdir(dir); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(dir, gfile.Basename(j.options.File)+".*.tmp")
+	if err != nil {
+		return gerror.Wrapf(err, `create temporary file in "%s" failed`, dir)
+	}
+	tempFile := file.Name()
+	if _, err = file.Write(content); err != nil {
+		_ = file.Close()
+		_ = gfile.Remove(tempFile)
+		return gerror.Wrapf(err, `write temporary file "%s" failed`, tempFile)
+	}
+	if err = file.Close(); err != nil {
+		_ = gfile.Remove(tempFile)
+		return gerror.Wrapf(err, `close temporary file "%s" failed`, tempFile)
+	}
+	if err = gfile.Rename(tempFile, j.options.File); err != nil {
+		_ = gfile.Remove(tempFile)
+		return err
+	}
+	return nil
+}
+
+// changed persists the cookies after they are changed.
+func (j *CookieJar) changed() {
+	if j.options.Adapter == nil && j.options.File == "" {
+		return
+	}
+	ctx := context.Background()
+	if j.options.SaveInterval <= 0 {
+		if err := j.Save(ctx); err != nil {
+			intlog.Errorf(ctx, `%+v`, err)
+		}
+		return
+	}
+	if !j.saving.Cas(false, true) {
+		return
+	}
+	time.AfterFunc(j.options.SaveInterval, func() {
+		j.saving.Set(false)
+		if err := j.Save(ctx); err != nil {
+			intlog.Errorf(ctx, `%+v`, err)
+		}
+	})
+}
+
+// newEntry creates an entry for `cookie` received from `host` and `requestPath`,
+// and returns whether the existing entry should be removed as the cookie is expired.
+func (j *CookieJar) newEntry(
+	cookie *http.Cookie, host, requestPath string, now time.Time,
+) (entry *CookieJarEntry, remove bool, err error) {
+	entry = &CookieJarEntry{
+		Name:     cookie.Name,
+		Value:    cookie.Value,
+		Path:     cookie.Path,
+		Secure:   cookie.Secure,
+		HttpOnly: cookie.HttpOnly,
+	}
+	if entry.Path == "" || entry.Path[0] != '/' {
+		entry.Path = cookieJarDefaultPath(requestPath)
+	}
+	if entry.Domain, entry.HostOnly, err = j.domainAndType(host, cookie.Domain); err != nil {
+		return nil, false, err
+	}
+	switch {
+	case cookie.MaxAge < 0:
+		return entry, true, nil
+	case cookie.MaxAge > 0:
+		entry.Expires = now.Add(time.Duration(cookie.MaxAge) * time.Second)
+	case !cookie.Expires.IsZero():
+		if !cookie.Expires.After(now) {
+			return entry, true, nil
+		}
+		entry.Expires = cookie.Expires
+	}
+	return entry, false, nil
+}
+
+// domainAndType returns the domain of cookie with attribute `domain` received from `host`,
+// and whether it's a host-only cookie.
+func (j *CookieJar) domainAndType(host, domain string) (string, bool, error) {
+	if domain == "" {
+		return host, true, nil
+	}
+	if net.ParseIP(host) != nil {
+		// The cookie of IP address is always host-only.
+		if host != domain {
+			return "", false, gerror.NewCodef(gcode.CodeInvalidParameter, `invalid cookie domain "%s"`, domain)
+		}
+		return host, true, nil
+	}
+	domain = strings.TrimPrefix(domain, ".")
+	if domain == "" || strings.HasSuffix(domain, ".") {
+		return "", false, gerror.NewCodef(gcode.CodeInvalidParameter, `invalid cookie domain "%s"`, domain)
+	}
+	domain, err := cookieJarCanonicalHost(domain)
+	if err != nil {
+		return "", false, err
+	}
+	// The cookie of public suffix is only accepted as host-only cookie of the same host.
+	if suffix := j.options.PublicSuffixList.PublicSuffix(domain); suffix != "" && !cookieJarHasDotSuffix(domain, suffix) {
+		if host == domain {
+			return host, true, nil
+		}
+		return "", false, gerror.NewCodef(gcode.CodeInvalidParameter, `cookie domain "%s" is a public suffix`, domain)
+	}
+	if host != domain && !cookieJarHasDotSuffix(host, domain) {
+		return "", false, gerror.NewCodef(
+			gcode.CodeInvalidParameter, `cookie domain "%s" does not domain-match host "%s"`, domain, host,
+		)
+	}
+	return domain, false, nil
+}
+
+// domainKey returns the key of `domain` in entries, which is its registrable domain like "example.com",
+// so that the cookies sent to a host are all under the same key.
+func (j *CookieJar) domainKey(domain string) string {
+	if net.ParseIP(domain) != nil {
+		return domain
+	}
+	suffix := j.options.PublicSuffixList.PublicSuffix(domain)
+	if suffix == domain {
+		return domain
+	}
+	i := len(domain) - len(suffix)
+	if i <= 0 || domain[i-1] != '.' {
+		return domain
+	}
+	return domain[strings.LastIndex(domain[:i-1], ".")+1:]
+}
+
+// put puts `entry` into the jar, which keeps the creation time of the existing one.
+func (j *CookieJar) put(entry *CookieJarEntry, now time.Time) {
+	var (
+		key    = j.domainKey(entry.Domain)
+		id     = entry.id()
+		submap = j.entries[key]
+	)
+	if submap == nil {
+		submap = make(map[string]*CookieJarEntry)
+		j.entries[key] = submap
+	}
+	if old, ok := submap[id]; ok {
+		entry.Creation = old.Creation
+	} else if entry.Creation.IsZero() {
+		if !now.After(j.created) {
+			now = j.created.Add(time.Nanosecond)
+		}
+		entry.Creation = now
+	}
+	if entry.Creation.After(j.created) {
+		j.created = entry.Creation
+	}
+	if entry.LastAccess.IsZero() {
+		entry.LastAccess = now
+	}
+	submap[id] = entry
+}
+
+// remove removes the entry of `domain`, `path` and `name`, and returns whether it exists.
+func (j *CookieJar) remove(domain, path, name string) bool {
+	var (
+		key = j.domainKey(domain)
+		id  = (&CookieJarEntry{Domain: domain, Path: path, Name: name}).id()
+	)
+	if _, ok := j.entries[key][id]; !ok {
+		return false
+	}
+	delete(j.entries[key], id)
+	if len(j.entries[key]) == 0 {
+		delete(j.entries, key)
+	}
+	return true
+}
+
+// id returns the identity of entry in its domain key.
+func (e *CookieJarEntry) id() string {
+	return e.Domain + ";" + e.Path + ";" + e.Name
+}
+
+// isExpired checks whether the entry is expired at `now`.
+func (e *CookieJarEntry) isExpired(now time.Time) bool {
+	return !e.Expires.IsZero() && !e.Expires.After(now)
+}
+
+// shouldSend checks whether the entry should be sent to `host` and `path` by its domain, path and secure.
+func (e *CookieJarEntry) shouldSend(https bool, host, path string) bool {
+	if e.Secure && !https {
+		return false
+	}
+	if e.Domain != host && (e.HostOnly || !cookieJarHasDotSuffix(host, e.Domain)) {
+		return false
+	}
+	// Path matching of RFC 6265 section 5.1.4.
+	if path == e.Path {
+		return true
+	}
+	if strings.HasPrefix(path, e.Path) {
+		return e.Path[len(e.Path)-1] == '/' || path[len(e.Path)] == '/'
+	}
+	return false
+}
+
+// cookieJarCanonicalHost returns the lower-cased ASCII host without port of `host`.
+func cookieJarCanonicalHost(host string) (string, error) {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(strings.Trim(host, "[]"), ".")
+	if host == "" {
+		return "", gerror.NewCode(gcode.CodeInvalidParameter, `empty cookie host`)
+	}
+	if net.ParseIP(host) != nil {
+		return host, nil
+	}
+	ascii, err := idna.ToASCII(host)
+	if err != nil {
+		return "", gerror.WrapCodef(gcode.CodeInvalidParameter, err, `invalid cookie host "%s"`, host)
+	}
+	return strings.ToLower(ascii), nil
+}
+
+// cookieJarDefaultPath returns the default cookie path of request path, see RFC 6265 section 5.1.4.
+func cookieJarDefaultPath(path string) string {
+	if path == "" || path[0] != '/' {
+		return "/"
+	}
+	i := strings.LastIndex(path, "/")
+	if i == 0 {
+		return "/"
+	}
+	return path[:i]
+}
+
+// cookieJarHasDotSuffix checks whether `s` ends with "." + `suffix`.
+func cookieJarHasDotSuffix(s, suffix string) bool {
+	return len(s) > len(suffix) && s[len(s)-len(suffix)-1] == '.' && s[len(s)-len(suffix):] == suffix
+}
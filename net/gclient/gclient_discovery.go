@@ -33,6 +33,10 @@ func (n *discoveryNode) Address() string {
 	return n.address
 }
 
+// ctxKeyDiscoverySelector is the context key for the selector of service picked by discovery,
+// which is used to pick other nodes for hedged requests.
+type ctxKeyDiscoverySelector struct{}
+
 // service prefix to its selector map cache.
 var clientSelectorMap = gmap.New(true)
 
@@ -84,15 +88,24 @@ func internalMiddlewareDiscovery(c *Client, r *http.Request) (response *Response
 	}
 	selector := selectorMapValue.(gsel.Selector)
 	// Pick one node from multiple addresses.
+	// The endpoints whose circuit breakers are open are skipped by the circuit breaker middleware.
 	node, done, err := selector.Pick(ctx)
 	if err != nil {
 		return nil, err
 	}
+	if node == nil {
+		return nil, gerror.NewCodef(
+			gcode.CodeNotFound, `no available endpoint of service "%s"`, service.GetName(),
+		)
+	}
 	if done != nil {
 		defer done(ctx, gsel.DoneInfo{})
 	}
 	r.Host = node.Address()
 	r.URL.Host = node.Address()
+	ctx = context.WithValue(ctx, ctxKeyDiscoveryService{}, service.GetName())
+	ctx = context.WithValue(ctx, ctxKeyDiscoverySelector{}, selector)
+	r = r.WithContext(ctx)
 	return c.Next(r)
 }
 
@@ -0,0 +1,445 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gclient
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/internal/json"
+	"github.com/gogf/gf/v2/os/gfile"
+)
+
+// DownloadOptions is the options for Client.Download.
+type DownloadOptions struct {
+	// Segments specifies the number of segments downloaded in parallel. It takes effect only if the server
+	// supports range requests and returns the file size. It's 1 in default.
+	Segments int
+
+	// Checksum specifies the expected checksum of the file in format "algorithm:hex", like "sha256:9f86d0...".
+	// The supported algorithms are "md5", "sha1", "sha256" and "sha512".
+	Checksum string
+
+	// DisableResume disables resuming the partially downloaded file of the previous downloading,
+	// which makes the file downloaded from scratch.
+	DisableResume bool
+
+	// Progress is called serially after each chunk of data is written, with the downloaded and total bytes.
+	// The total bytes is -1 if it is unknown.
+	Progress func(downloaded, total int64)
+}
+
+// downloader downloads a file in segments.
+type downloader struct {
+	client     *Client
+	ctx        context.Context
+	url        string
+	destPath   string
+	options    DownloadOptions
+	meta       downloadMeta
+	mu         sync.Mutex // Mutex for progress reporting.
+	downloaded int64      // Downloaded bytes.
+}
+
+// downloadMeta is the metadata of the partially downloaded file for resuming,
+// which is saved besides the segment files.
+type downloadMeta struct {
+	URL       string `json:"url"`
+	Validator string `json:"validator"` // ETag or Last-Modified of the remote file.
+	Total     int64  `json:"total"`     // Total size of the remote file, -1 if unknown.
+	Segments  int    `json:"segments"`
+}
+
+const (
+	httpHeaderRange         = "Range"
+	httpHeaderIfRange       = "If-Range"
+	httpHeaderContentRange  = "Content-Range"
+	downloadTempSuffix      = ".download"
+	downloadMetaSuffix      = ".download.json"
+	downloadCopyBufferSize  = 32 * 1024
+	downloadChecksumMD5     = "md5"
+	downloadChecksumSHA1    = "sha1"
+	downloadChecksumSHA256  = "sha256"
+	downloadChecksumSHA512  = "sha512"
+	downloadUnknownFileSize = -1
+)
+
+// Download downloads the file of `url` to `destPath`, which is written atomically by renaming
+// the temporary file after it is completed and verified.
+//
+// If the server supports range requests, the partially downloaded file of the previous downloading
+// is resumed with header "Range", and the file is split into segments downloaded in parallel
+// if DownloadOptions.Segments is greater than 1. The temporary files are saved in the same
+// directory of `destPath` with suffix ".download".
+func (c *Client) Download(ctx context.Context, url, destPath string, options ...DownloadOptions) error {
+	d := &downloader{
+		client:   c,
+		ctx:      ctx,
+		url:      url,
+		destPath: destPath,
+	}
+	if len(options) > 0 {
+		d.options = options[0]
+	}
+	newHash, expectedChecksum, err := parseDownloadChecksum(d.options.Checksum)
+	if err != nil {
+		return err
+	}
+	if err = gfile.Mkdir(gfile.Dir(destPath)); err != nil {
+		return err
+	}
+	path, err := d.download()
+	if err != nil {
+		return err
+	}
+	if newHash != nil {
+		var checksum string
+		if checksum, err = getFileChecksum(path, newHash()); err != nil {
+			return err
+		}
+		if !strings.EqualFold(checksum, expectedChecksum) {
+			d.removeTemporaryFiles(d.meta.Segments)
+			return gerror.NewCodef(
+				gcode.CodeValidationFailed,
+				`checksum mismatch of downloaded file "%s", expected "%s" but got "%s"`,
+				url, expectedChecksum, checksum,
+			)
+		}
+	}
+	if err = gfile.Rename(path, destPath); err != nil {
+		return err
+	}
+	d.removeTemporaryFiles(d.meta.Segments)
+	return nil
+}
+
+// download downloads all the segments, and returns the path of the completed temporary file.
+func (d *downloader) download() (string, error) {
+	// It probes the range support and file size with the first byte.
+	resp, err := d.client.Header(map[string]string{httpHeaderRange: "bytes=0-0"}).Get(d.ctx, d.url)
+	if err != nil {
+		return "", err
+	}
+	var (
+		body  *Response // Body of full content if the server does not support range requests.
+		total = int64(downloadUnknownFileSize)
+	)
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		_ = resp.Close()
+		total = parseContentRangeTotal(resp.Header.Get(httpHeaderContentRange))
+	case http.StatusOK:
+		body = resp
+		defer body.Close()
+		total = resp.ContentLength
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The server responds the range request of empty file with "Content-Range: bytes */0".
+		_ = resp.Close()
+		if total = parseContentRangeTotal(resp.Header.Get(httpHeaderContentRange)); total != 0 {
+			return "", gerror.NewCodef(
+				gcode.CodeOperationFailed, `download "%s" failed with status: %s`, d.url, resp.Status,
+			)
+		}
+	default:
+		_ = resp.Close()
+		return "", gerror.NewCodef(
+			gcode.CodeOperationFailed, `download "%s" failed with status: %s`, d.url, resp.Status,
+		)
+	}
+	d.meta = downloadMeta{
+		URL:       d.url,
+		Validator: resp.Header.Get(httpHeaderETag),
+		Total:     total,
+		Segments:  1,
+	}
+	if d.meta.Validator == "" {
+		d.meta.Validator = resp.Header.Get(httpHeaderLastModified)
+	}
+	if body == nil && total > 0 && d.options.Segments > 1 {
+		d.meta.Segments = d.options.Segments
+		// It avoids empty segments by making all segments except the last one the same size.
+		segmentSize := (total + int64(d.meta.Segments) - 1) / int64(d.meta.Segments)
+		d.meta.Segments = int((total + segmentSize - 1) / segmentSize)
+	}
+	if err = d.prepare(body != nil); err != nil {
+		return "", err
+	}
+	// There's nothing to download for empty file.
+	if body == nil && total == 0 {
+		path := d.segmentPath(0)
+		if err = gfile.PutBytes(path, nil); err != nil {
+			return "", err
+		}
+		return path, nil
+	}
+	if err = d.downloadSegments(body); err != nil {
+		return "", err
+	}
+	if d.meta.Segments == 1 {
+		return d.segmentPath(0), nil
+	}
+	return d.mergeSegments()
+}
+
+// prepare checks whether the partially downloaded segments can be resumed, and removes them if not.
+func (d *downloader) prepare(fromScratch bool) error {
+	var (
+		previous  downloadMeta
+		metaPath  = d.destPath + downloadMetaSuffix
+		resumable = !fromScratch && !d.options.DisableResume
+	)
+	if gfile.Exists(metaPath) {
+		if err := json.Unmarshal(gfile.GetBytes(metaPath), &previous); err != nil || previous != d.meta {
+			resumable = false
+		}
+		if !resumable {
+			d.removeTemporaryFiles(previous.Segments)
+		}
+	} else {
+		resumable = false
+	}
+	if !resumable {
+		d.removeTemporaryFiles(d.meta.Segments)
+	}
+	content, err := json.Marshal(d.meta)
+	if err != nil {
+		return err
+	}
+	return gfile.PutBytes(metaPath, content)
+}
+
+// downloadSegments downloads the segments in parallel. The `body` is the full content of file
+// if the server does not support range requests.
+func (d *downloader) downloadSegments(body *Response) error {
+	var (
+		wg          sync.WaitGroup
+		errOnce     sync.Once
+		firstErr    error
+		ctx, cancel = context.WithCancel(d.ctx)
+		segments    = d.meta.Segments
+		segmentSize = (d.meta.Total + int64(segments) - 1) / int64(segments)
+	)
+	defer cancel()
+	for i := 0; i < segments; i++ {
+		d.downloaded += gfile.Size(d.segmentPath(i))
+	}
+	for i := 0; i < segments; i++ {
+		var start, end int64 = 0, downloadUnknownFileSize
+		if d.meta.Total > 0 {
+			start = int64(i) * segmentSize
+			end = start + segmentSize - 1
+			if end >= d.meta.Total {
+				end = d.meta.Total - 1
+			}
+		}
+		wg.Add(1)
+		go func(i int, start, end int64) {
+			defer wg.Done()
+			if err := d.downloadSegment(ctx, i, start, end, body); err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}(i, start, end)
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// downloadSegment downloads the segment in range [start, end] to its segment file, which resumes
+// from the end of the existing segment file. The `end` is -1 if the file size is unknown.
+func (d *downloader) downloadSegment(ctx context.Context, index int, start, end int64, body *Response) error {
+	var (
+		path   = d.segmentPath(index)
+		offset = gfile.Size(path)
+		flag   = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+		resp   = body
+	)
+	if end >= 0 && start+offset > end {
+		return nil
+	}
+	if resp == nil {
+		var (
+			err    error
+			header = map[string]string{httpHeaderRange: fmt.Sprintf("bytes=%d-", start+offset)}
+		)
+		if end >= 0 {
+			header[httpHeaderRange] += strconv.FormatInt(end, 10)
+		}
+		if d.meta.Validator != "" {
+			header[httpHeaderIfRange] = d.meta.Validator
+		}
+		if resp, err = d.client.Header(header).Get(ctx, d.url); err != nil {
+			return err
+		}
+		defer resp.Close()
+		// The file of unknown size is already completed.
+		if resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && end < 0 && offset > 0 {
+			return nil
+		}
+		if resp.StatusCode != http.StatusPartialContent {
+			return gerror.NewCodef(
+				gcode.CodeOperationFailed,
+				`download segment of "%s" failed with status: %s, the remote file might be changed`,
+				d.url, resp.Status,
+			)
+		}
+	}
+	file, err := gfile.OpenFile(path, flag, gfile.DefaultPermOpen)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	var buffer = make([]byte, downloadCopyBufferSize)
+	for {
+		if err = ctx.Err(); err != nil {
+			return gerror.Wrapf(err, `download "%s" canceled`, d.url)
+		}
+		n, readErr := resp.Body.Read(buffer)
+		if n > 0 {
+			if _, err = file.Write(buffer[:n]); err != nil {
+				return gerror.Wrapf(err, `write segment file "%s" failed`, path)
+			}
+			offset += int64(n)
+			d.reportProgress(int64(n))
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return gerror.Wrapf(readErr, `download "%s" failed`, d.url)
+		}
+	}
+	if end >= 0 && offset != end-start+1 {
+		return gerror.NewCodef(
+			gcode.CodeOperationFailed,
+			`download segment of "%s" incomplete, expected %d bytes but got %d`,
+			d.url, end-start+1, offset,
+		)
+	}
+	return nil
+}
+
+// mergeSegments merges the segment files to the temporary file and returns its path.
+func (d *downloader) mergeSegments() (string, error) {
+	path := d.destPath + downloadTempSuffix
+	file, err := gfile.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, gfile.DefaultPermOpen)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	for i := 0; i < d.meta.Segments; i++ {
+		if err = appendFile(file, d.segmentPath(i)); err != nil {
+			return "", err
+		}
+	}
+	return path, nil
+}
+
+// reportProgress adds `n` bytes to the downloaded bytes and calls the progress callback.
+func (d *downloader) reportProgress(n int64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.downloaded += n
+	if d.options.Progress != nil {
+		d.options.Progress(d.downloaded, d.meta.Total)
+	}
+}
+
+// segmentPath returns the path of the segment file of `index`.
+func (d *downloader) segmentPath(index int) string {
+	return fmt.Sprintf("%s%s.%d", d.destPath, downloadTempSuffix, index)
+}
+
+// removeTemporaryFiles removes the metadata, merged and `segments` segment files.
+func (d *downloader) removeTemporaryFiles(segments int) {
+	_ = gfile.RemoveFile(d.destPath + downloadMetaSuffix)
+	_ = gfile.RemoveFile(d.destPath + downloadTempSuffix)
+	for i := 0; i < segments; i++ {
+		_ = gfile.RemoveFile(d.segmentPath(i))
+	}
+}
+
+// appendFile appends the content of file `path` to `file`.
+func appendFile(file *os.File, path string) error {
+	src, err := gfile.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	if _, err = io.Copy(file, src); err != nil {
+		return gerror.Wrapf(err, `merge segment file "%s" failed`, path)
+	}
+	return nil
+}
+
+// getFileChecksum calculates and returns the checksum of file `path` in hex using `h`.
+func getFileChecksum(path string, h hash.Hash) (string, error) {
+	file, err := gfile.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	if _, err = io.Copy(h, file); err != nil {
+		return "", gerror.Wrapf(err, `read file "%s" failed`, path)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// parseDownloadChecksum parses the checksum in format "algorithm:hex",
+// and returns the hash creating function and expected checksum in hex.
+func parseDownloadChecksum(checksum string) (func() hash.Hash, string, error) {
+	if checksum == "" {
+		return nil, "", nil
+	}
+	algorithm, expected, found := strings.Cut(checksum, ":")
+	if !found || expected == "" {
+		return nil, "", gerror.NewCodef(
+			gcode.CodeInvalidParameter, `invalid checksum "%s", it should be like "sha256:hex"`, checksum,
+		)
+	}
+	switch strings.ToLower(algorithm) {
+	case downloadChecksumMD5:
+		return md5.New, expected, nil
+	case downloadChecksumSHA1:
+		return sha1.New, expected, nil
+	case downloadChecksumSHA256:
+		return sha256.New, expected, nil
+	case downloadChecksumSHA512:
+		return sha512.New, expected, nil
+	}
+	return nil, "", gerror.NewCodef(gcode.CodeNotSupported, `unsupported checksum algorithm "%s"`, algorithm)
+}
+
+// parseContentRangeTotal parses the total size from header "Content-Range" like "bytes 0-0/1234".
+// It returns -1 if the total size is unknown.
+func parseContentRangeTotal(contentRange string) int64 {
+	_, total, found := strings.Cut(contentRange, "/")
+	if !found {
+		return downloadUnknownFileSize
+	}
+	size, err := strconv.ParseInt(strings.TrimSpace(total), 10, 64)
+	if err != nil {
+		return downloadUnknownFileSize
+	}
+	return size
+}
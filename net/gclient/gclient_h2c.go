@@ -0,0 +1,63 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gclient
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+
+	"golang.org/x/net/http2"
+)
+
+// h2cTransport is the transport that sends requests of "http" scheme using cleartext HTTP/2
+// with prior knowledge, and sends the other requests using the underlying transport.
+type h2cTransport struct {
+	http.RoundTripper                  // Underlying transport for requests not of "http" scheme.
+	h2c               *http2.Transport // Transport for cleartext HTTP/2.
+}
+
+// newH2cTransport creates and returns a h2c transport wrapping `transport`.
+func newH2cTransport(transport http.RoundTripper) *h2cTransport {
+	t := &h2cTransport{
+		RoundTripper: transport,
+	}
+	t.h2c = &http2.Transport{
+		AllowHTTP: true,
+		// It dials plain TCP connection instead of TLS for cleartext HTTP/2,
+		// using the dialer of underlying transport if any.
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			if v, ok := t.RoundTripper.(*http.Transport); ok && v.DialContext != nil {
+				return v.DialContext(ctx, network, addr)
+			}
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, addr)
+		},
+	}
+	return t
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *h2cTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme == httpProtocolName {
+		return t.h2c.RoundTrip(req)
+	}
+	transport := t.RoundTripper
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return transport.RoundTrip(req)
+}
+
+// CloseIdleConnections closes the idle connections of both cleartext HTTP/2 and underlying transports.
+func (t *h2cTransport) CloseIdleConnections() {
+	t.h2c.CloseIdleConnections()
+	if v, ok := t.RoundTripper.(interface{ CloseIdleConnections() }); ok {
+		v.CloseIdleConnections()
+	}
+}
@@ -0,0 +1,322 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gclient
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gogf/gf/v2/container/gmap"
+	"github.com/gogf/gf/v2/internal/httputil"
+	"github.com/gogf/gf/v2/internal/intlog"
+	"github.com/gogf/gf/v2/net/gsel"
+	"github.com/gogf/gf/v2/os/gmetric"
+)
+
+// HedgePolicy is the policy of hedged requests, which reduces the tail latency caused by slow upstream replicas.
+//
+// If the response of an idempotent request does not arrive within the hedging delay, it sends a hedged
+// request to another node picked by the service discovery selector, or to the same host if there's no
+// discovery or other node. It takes the first response and cancels the others.
+type HedgePolicy struct {
+	// MaxHedges specifies the maximum number of hedged requests besides the original one. It's 1 in default.
+	MaxHedges int
+
+	// Delay specifies the fixed hedging delay. If it's not positive, the delay is the Percentile
+	// of the recent latencies of the upstream host or service.
+	Delay time.Duration
+
+	// Percentile specifies the percentile of recent latencies in range (0, 1) as the hedging delay.
+	// It's 0.95 in default.
+	Percentile float64
+
+	// MinDelay specifies the minimum hedging delay calculated by Percentile. It's 10ms in default.
+	MinDelay time.Duration
+
+	// MinSamples specifies the minimum number of latency samples before the requests are hedged
+	// using the delay calculated by Percentile. It's 20 in default.
+	MinSamples int
+
+	// WindowSize specifies the number of recent latencies kept for each upstream. It's 100 in default.
+	WindowSize int
+}
+
+// hedger sends hedged requests.
+type hedger struct {
+	policy    HedgePolicy
+	latencies *gmap.StrAnyMap // Upstream host or service to its *hedgeLatencies.
+}
+
+// hedgeLatencies is the sliding window of recent latencies of an upstream.
+type hedgeLatencies struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int // Index of the next sample to write.
+	count   int // Number of samples written, no more than the window size.
+}
+
+// hedgeResult is the result of an attempt of hedged request.
+type hedgeResult struct {
+	index    int
+	resp     *http.Response
+	err      error
+	duration time.Duration
+}
+
+const (
+	defaultHedgeMaxHedges  = 1
+	defaultHedgePercentile = 0.95
+	defaultHedgeMinDelay   = 10 * time.Millisecond
+	defaultHedgeMinSamples = 20
+	defaultHedgeWindowSize = 100
+)
+
+// newHedger creates and returns a hedger with `policy`.
+func newHedger(policy HedgePolicy) *hedger {
+	if policy.MaxHedges <= 0 {
+		policy.MaxHedges = defaultHedgeMaxHedges
+	}
+	if policy.Percentile <= 0 || policy.Percentile >= 1 {
+		policy.Percentile = defaultHedgePercentile
+	}
+	if policy.MinDelay <= 0 {
+		policy.MinDelay = defaultHedgeMinDelay
+	}
+	if policy.MinSamples <= 0 {
+		policy.MinSamples = defaultHedgeMinSamples
+	}
+	if policy.WindowSize <= 0 {
+		policy.WindowSize = defaultHedgeWindowSize
+	}
+	if policy.MinSamples > policy.WindowSize {
+		policy.MinSamples = policy.WindowSize
+	}
+	return &hedger{
+		policy:    policy,
+		latencies: gmap.NewStrAnyMap(true),
+	}
+}
+
+// send sends `req` using the hedging policy and the host limit of client.
+// Only the idempotent requests with replayable body are hedged.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	if c.hedger == nil || req.GetBody == nil || !httputil.IsIdempotentRequest(req) {
+		return c.sendLimited(req)
+	}
+	return c.hedger.send(c, req)
+}
+
+// send sends `req` and its hedged requests, and returns the first response.
+func (h *hedger) send(c *Client, req *http.Request) (*http.Response, error) {
+	var (
+		ctx       = req.Context()
+		key       = hedgeUpstreamKey(req)
+		delay, ok = h.delay(key)
+	)
+	if !ok {
+		start := time.Now()
+		resp, err := c.sendLimited(req)
+		if err == nil {
+			h.record(key, time.Since(start))
+		}
+		return resp, err
+	}
+	var (
+		results   = make(chan hedgeResult, h.policy.MaxHedges+1)
+		cancels   = make([]context.CancelFunc, 0, h.policy.MaxHedges+1)
+		addresses = []string{req.URL.Host}
+		inFlight  = 0
+		timer     = time.NewTimer(delay)
+	)
+	defer timer.Stop()
+	launch := func(r *http.Request, done func()) {
+		var (
+			index             = len(cancels)
+			attemptCtx, abort = context.WithCancel(ctx)
+		)
+		cancels = append(cancels, abort)
+		inFlight++
+		go func() {
+			start := time.Now()
+			resp, err := c.sendLimited(r.WithContext(attemptCtx))
+			if done != nil {
+				done()
+			}
+			results <- hedgeResult{index: index, resp: resp, err: err, duration: time.Since(start)}
+		}()
+	}
+	// hedge sends a hedged request, and returns false if it cannot be sent.
+	hedge := func() bool {
+		if len(cancels) > h.policy.MaxHedges || ctx.Err() != nil {
+			return false
+		}
+		r, done, err := h.newHedgedRequest(req, addresses)
+		if err != nil {
+			intlog.Errorf(ctx, `create hedged request failed: %+v`, err)
+			return false
+		}
+		addresses = append(addresses, r.URL.Host)
+		launch(r, done)
+		c.handleMetricsHedge(r, metricManager.HttpClientHedgeTotal)
+		return true
+	}
+	launch(req, nil)
+	for {
+		select {
+		case <-timer.C:
+			if hedge() {
+				timer.Reset(delay)
+			}
+
+		case result := <-results:
+			inFlight--
+			if result.err == nil {
+				h.record(key, result.duration)
+				// Cancels the other attempts and releases their responses in background.
+				for i, abort := range cancels {
+					if i != result.index {
+						abort()
+					}
+				}
+				go func(n int) {
+					for i := 0; i < n; i++ {
+						if r := <-results; r.resp != nil && r.resp.Body != nil {
+							_ = r.resp.Body.Close()
+						}
+					}
+				}(inFlight)
+				if result.index > 0 {
+					c.handleMetricsHedge(req, metricManager.HttpClientHedgeWon)
+				}
+				// The context of response is canceled after its body is closed.
+				result.resp.Body = &closeHookBody{ReadCloser: result.resp.Body, hook: cancels[result.index]}
+				return result.resp, nil
+			}
+			cancels[result.index]()
+			// It sends the hedged request immediately if all the sent ones failed.
+			if inFlight == 0 && !hedge() {
+				return result.resp, result.err
+			}
+		}
+	}
+}
+
+// newHedgedRequest creates a hedged request of `req`, which is sent to another node picked by
+// service discovery selector skipping `addresses`, or to the same host if there's no one.
+func (h *hedger) newHedgedRequest(req *http.Request, addresses []string) (*http.Request, func(), error) {
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, nil, err
+	}
+	var (
+		ctx  = req.Context()
+		r    = req.Clone(ctx)
+		done func()
+	)
+	r.Body = body
+	if selector, ok := ctx.Value(ctxKeyDiscoverySelector{}).(gsel.Selector); ok {
+		filter := func(ctx context.Context, node gsel.Node) bool {
+			for _, address := range addresses {
+				if node.Address() == address {
+					return false
+				}
+			}
+			return isEndpointAvailable(ctx, node)
+		}
+		node, pickDone, err := selector.Pick(gsel.WithNodeFilter(ctx, filter))
+		if err == nil && node != nil {
+			r.Host = node.Address()
+			r.URL.Host = node.Address()
+			if pickDone != nil {
+				done = func() {
+					pickDone(ctx, gsel.DoneInfo{})
+				}
+			}
+		}
+	}
+	return r, done, nil
+}
+
+// delay returns the hedging delay of `key`, and false if the requests should not be hedged.
+func (h *hedger) delay(key string) (time.Duration, bool) {
+	if h.policy.Delay > 0 {
+		return h.policy.Delay, true
+	}
+	v := h.latencies.Get(key)
+	if v == nil {
+		return 0, false
+	}
+	d, count := v.(*hedgeLatencies).percentile(h.policy.Percentile)
+	if count < h.policy.MinSamples {
+		return 0, false
+	}
+	if d < h.policy.MinDelay {
+		d = h.policy.MinDelay
+	}
+	return d, true
+}
+
+// record adds the latency `d` of `key`.
+func (h *hedger) record(key string, d time.Duration) {
+	if h.policy.Delay > 0 {
+		return
+	}
+	h.latencies.GetOrSetFuncLock(key, func() interface{} {
+		return &hedgeLatencies{samples: make([]time.Duration, h.policy.WindowSize)}
+	}).(*hedgeLatencies).add(d)
+}
+
+// add adds a latency sample.
+func (l *hedgeLatencies) add(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.samples[l.next] = d
+	l.next = (l.next + 1) % len(l.samples)
+	if l.count < len(l.samples) {
+		l.count++
+	}
+}
+
+// percentile returns the `p` percentile of samples and the count of samples.
+func (l *hedgeLatencies) percentile(p float64) (time.Duration, int) {
+	l.mu.Lock()
+	samples := make([]time.Duration, l.count)
+	copy(samples, l.samples[:l.count])
+	l.mu.Unlock()
+	if len(samples) == 0 {
+		return 0, 0
+	}
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i] < samples[j]
+	})
+	index := int(math.Ceil(p*float64(len(samples)))) - 1
+	if index < 0 {
+		index = 0
+	}
+	return samples[index], len(samples)
+}
+
+// hedgeUpstreamKey returns the upstream key of `req`, which is the service name if it's from
+// service discovery, or else the host.
+func hedgeUpstreamKey(req *http.Request) string {
+	if name, ok := req.Context().Value(ctxKeyDiscoveryService{}).(string); ok {
+		return name
+	}
+	return req.URL.Host
+}
+
+// handleMetricsHedge increases the hedge metric `counter`.
+func (c *Client) handleMetricsHedge(r *http.Request, counter gmetric.Counter) {
+	if !gmetric.IsEnabled() {
+		return
+	}
+	counter.Inc(r.Context(), metricManager.GetMetricOptionForRequest(r))
+}
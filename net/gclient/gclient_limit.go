@@ -0,0 +1,167 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gogf/gf/v2/container/gmap"
+	"github.com/gogf/gf/v2/container/gtype"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/os/gmetric"
+)
+
+// HostLimitOptions is the options for limiting the in-flight requests per host.
+type HostLimitOptions struct {
+	// MaxInFlight specifies the maximum number of in-flight requests per host.
+	// A request is in flight until its response body is closed. It does not limit if it's not positive.
+	MaxInFlight int
+
+	// MaxQueue specifies the maximum number of requests waiting for the in-flight slots per host.
+	// The request is rejected with ErrHostLimitExceeded if the queue is full.
+	// It's 0 in default, which means the queue is unlimited.
+	MaxQueue int
+
+	// QueueTimeout specifies the maximum duration that a request waits in the queue.
+	// The request is rejected with ErrHostLimitExceeded if it times out.
+	// It's 0 in default, which means it waits until the request context is done.
+	QueueTimeout time.Duration
+}
+
+// hostLimiter limits the in-flight requests per host.
+type hostLimiter struct {
+	options HostLimitOptions
+	hosts   *gmap.StrAnyMap // Host to its *hostLimitSlots.
+}
+
+// hostLimitSlots is the in-flight slots of a host.
+type hostLimitSlots struct {
+	slots  chan struct{}
+	queued *gtype.Int
+}
+
+// closeHookBody is the response body that calls hook once it's closed.
+type closeHookBody struct {
+	io.ReadCloser
+	once sync.Once
+	hook func()
+}
+
+// ErrHostLimitExceeded is the error that indicates the request is rejected by the host limit
+// as the queue is full or it times out waiting in the queue.
+var ErrHostLimitExceeded = gerror.NewWithOption(gerror.Option{
+	Text: "host in-flight request limit exceeded",
+	Code: gcode.CodeServerBusy,
+})
+
+// newHostLimiter creates and returns a hostLimiter.
+func newHostLimiter(options HostLimitOptions) *hostLimiter {
+	return &hostLimiter{
+		options: options,
+		hosts:   gmap.NewStrAnyMap(true),
+	}
+}
+
+// sendLimited sends `req` with the in-flight limit of its host.
+func (c *Client) sendLimited(req *http.Request) (*http.Response, error) {
+	if c.hostLimiter == nil || c.hostLimiter.options.MaxInFlight <= 0 {
+		return c.Do(req)
+	}
+	release, err := c.hostLimiter.acquire(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.Do(req)
+	if err != nil || resp.Body == nil {
+		release()
+		return resp, err
+	}
+	resp.Body = &closeHookBody{ReadCloser: resp.Body, hook: release}
+	return resp, nil
+}
+
+// acquire waits for an in-flight slot of the host of `req`, and returns the function releasing it.
+func (l *hostLimiter) acquire(req *http.Request) (release func(), err error) {
+	var (
+		ctx   = req.Context()
+		slots = l.hosts.GetOrSetFuncLock(req.URL.Host, func() interface{} {
+			return &hostLimitSlots{
+				slots:  make(chan struct{}, l.options.MaxInFlight),
+				queued: gtype.NewInt(),
+			}
+		}).(*hostLimitSlots)
+	)
+	release = func() {
+		<-slots.slots
+		handleMetricsHostLimit(ctx, req, metricManager.HttpClientHostLimitInFlight, false)
+	}
+	select {
+	case slots.slots <- struct{}{}:
+		handleMetricsHostLimit(ctx, req, metricManager.HttpClientHostLimitInFlight, true)
+		return release, nil
+	default:
+	}
+	// Waits in the queue.
+	if queued := slots.queued.Add(1); l.options.MaxQueue > 0 && queued > l.options.MaxQueue {
+		slots.queued.Add(-1)
+		handleMetricsHostLimitRejected(ctx, req)
+		return nil, gerror.Wrapf(ErrHostLimitExceeded, `queue of host "%s" is full`, req.URL.Host)
+	}
+	handleMetricsHostLimit(ctx, req, metricManager.HttpClientHostLimitQueued, true)
+	defer func() {
+		slots.queued.Add(-1)
+		handleMetricsHostLimit(ctx, req, metricManager.HttpClientHostLimitQueued, false)
+	}()
+	var timeout <-chan time.Time
+	if l.options.QueueTimeout > 0 {
+		timer := time.NewTimer(l.options.QueueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case slots.slots <- struct{}{}:
+		handleMetricsHostLimit(ctx, req, metricManager.HttpClientHostLimitInFlight, true)
+		return release, nil
+	case <-timeout:
+		handleMetricsHostLimitRejected(ctx, req)
+		return nil, gerror.Wrapf(ErrHostLimitExceeded, `waiting in queue of host "%s" timed out`, req.URL.Host)
+	case <-ctx.Done():
+		return nil, gerror.Wrap(ctx.Err(), `request canceled while waiting in host queue`)
+	}
+}
+
+// Close implements the io.Closer interface, which calls the hook once.
+func (b *closeHookBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.hook)
+	return err
+}
+
+// handleMetricsHostLimit increases or decreases the host limit gauge `counter`.
+func handleMetricsHostLimit(ctx context.Context, req *http.Request, counter gmetric.UpDownCounter, increase bool) {
+	if !gmetric.IsEnabled() {
+		return
+	}
+	if increase {
+		counter.Inc(ctx, metricManager.GetMetricOptionForHistogram(req))
+	} else {
+		counter.Dec(ctx, metricManager.GetMetricOptionForHistogram(req))
+	}
+}
+
+// handleMetricsHostLimitRejected increases the counter of requests rejected by host limit.
+func handleMetricsHostLimitRejected(ctx context.Context, req *http.Request) {
+	if !gmetric.IsEnabled() {
+		return
+	}
+	metricManager.HttpClientHostLimitRejected.Inc(ctx, metricManager.GetMetricOptionForHistogram(req))
+}
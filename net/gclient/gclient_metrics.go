@@ -23,6 +23,16 @@ type localMetricManager struct {
 	HttpClientConnectionDuration   gmetric.Histogram
 	HttpClientRequestBodySize      gmetric.Counter
 	HttpClientResponseBodySize     gmetric.Counter
+	HttpClientRequestRetryTotal    gmetric.Counter
+
+	HttpClientCircuitBreakerRejected gmetric.Counter
+	HttpClientCircuitBreakerState    gmetric.UpDownCounter
+
+	HttpClientHostLimitInFlight gmetric.UpDownCounter
+	HttpClientHostLimitQueued   gmetric.UpDownCounter
+	HttpClientHostLimitRejected gmetric.Counter
+	HttpClientHedgeTotal        gmetric.Counter
+	HttpClientHedgeWon          gmetric.Counter
 }
 
 const (
@@ -113,6 +123,70 @@ func newMetricManager() *localMetricManager {
 				Attributes: gmetric.Attributes{},
 			},
 		),
+		HttpClientRequestRetryTotal: meter.MustCounter(
+			"http.client.request.retry_total",
+			gmetric.MetricOption{
+				Help:       "Total retried request number.",
+				Unit:       "",
+				Attributes: gmetric.Attributes{},
+			},
+		),
+		HttpClientCircuitBreakerRejected: meter.MustCounter(
+			"http.client.circuit_breaker.rejected",
+			gmetric.MetricOption{
+				Help:       "Total request number rejected by open circuit breakers.",
+				Unit:       "",
+				Attributes: gmetric.Attributes{},
+			},
+		),
+		HttpClientCircuitBreakerState: meter.MustUpDownCounter(
+			"http.client.circuit_breaker.state",
+			gmetric.MetricOption{
+				Help:       "State of circuit breaker of upstream, 0: closed, 1: open, 2: half-open.",
+				Unit:       "",
+				Attributes: gmetric.Attributes{},
+			},
+		),
+		HttpClientHostLimitInFlight: meter.MustUpDownCounter(
+			"http.client.host_limit.in_flight",
+			gmetric.MetricOption{
+				Help:       "Number of in-flight requests limited by host limit.",
+				Unit:       "",
+				Attributes: gmetric.Attributes{},
+			},
+		),
+		HttpClientHostLimitQueued: meter.MustUpDownCounter(
+			"http.client.host_limit.queued",
+			gmetric.MetricOption{
+				Help:       "Number of requests waiting in queue of host limit.",
+				Unit:       "",
+				Attributes: gmetric.Attributes{},
+			},
+		),
+		HttpClientHostLimitRejected: meter.MustCounter(
+			"http.client.host_limit.rejected",
+			gmetric.MetricOption{
+				Help:       "Total request number rejected by host limit.",
+				Unit:       "",
+				Attributes: gmetric.Attributes{},
+			},
+		),
+		HttpClientHedgeTotal: meter.MustCounter(
+			"http.client.hedge.total",
+			gmetric.MetricOption{
+				Help:       "Total hedged request number.",
+				Unit:       "",
+				Attributes: gmetric.Attributes{},
+			},
+		),
+		HttpClientHedgeWon: meter.MustCounter(
+			"http.client.hedge.won",
+			gmetric.MetricOption{
+				Help:       "Total request number whose response is from hedged request.",
+				Unit:       "",
+				Attributes: gmetric.Attributes{},
+			},
+		),
 		HttpClientConnectionDuration: meter.MustHistogram(
 			"http.client.connection_duration",
 			gmetric.MetricOption{
@@ -48,3 +48,16 @@ func (m *clientMiddleware) Next(req *http.Request) (resp *Response, err error) {
 	}
 	return m.resp, m.err
 }
+
+// resetNext returns a function that resets the middleware chain of `req` to the handler calling it,
+// so that Next can be called again to resend the request through the following handlers.
+func resetNext(req *http.Request) func() {
+	m, ok := req.Context().Value(clientMiddlewareKey).(*clientMiddleware)
+	if !ok {
+		return func() {}
+	}
+	index := m.handlerIndex
+	return func() {
+		m.handlerIndex, m.resp, m.err = index, nil, nil
+	}
+}
@@ -0,0 +1,304 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gclient
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+	"sync"
+
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/os/gfile"
+)
+
+// Multipart is the builder of "multipart/form-data" request body, which streams the parts
+// without buffering the whole payload in memory. It is used as the request data, for example:
+//
+//	client.Post(ctx, url, gclient.NewMultipart().Field("name", "john").File("avatar", "/tmp/avatar.png"))
+//
+// The parts are re-opened for each sending of the request, so that it works with retries.
+type Multipart struct {
+	boundary string
+	parts    []*multipartPart
+	progress func(sent, total int64)
+}
+
+// multipartPart is a part of multipart body.
+type multipartPart struct {
+	fieldName   string
+	fileName    string
+	contentType string
+	size        int64                         // Size of content, -1 if unknown.
+	open        func() (io.ReadCloser, error) // Opens the content for each sending.
+}
+
+// multipartBody is the streaming request body of Multipart.
+type multipartBody struct {
+	*io.PipeReader
+	multipart *Multipart
+	total     int64 // Total size of body, -1 if unknown.
+	sent      int64 // Sent size of body.
+}
+
+// multipartReaderOnce opens `reader` only once, or from its start if it is an io.Seeker.
+type multipartReaderOnce struct {
+	mu        sync.Mutex
+	reader    io.Reader
+	start     int64 // Start offset for seeking.
+	seekable  bool
+	consumed  bool
+	fieldName string
+}
+
+const (
+	httpHeaderContentDisposition   = "Content-Disposition"
+	httpHeaderContentTypeOctStream = "application/octet-stream"
+	multipartUnknownSize           = -1
+)
+
+// quoteEscaper escapes the quotes in Content-Disposition like mime/multipart.
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// NewMultipart creates and returns an empty Multipart with random boundary.
+func NewMultipart() *Multipart {
+	return &Multipart{
+		boundary: multipart.NewWriter(io.Discard).Boundary(),
+	}
+}
+
+// Field adds a form field part.
+func (m *Multipart) Field(name, value string) *Multipart {
+	m.parts = append(m.parts, &multipartPart{
+		fieldName: name,
+		size:      int64(len(value)),
+		open: func() (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader(value)), nil
+		},
+	})
+	return m
+}
+
+// File adds a file part from local file `path`, which is opened when the request is sent.
+// The optional parameter `contentType` specifies the content type of part,
+// which is detected by the file extension in default.
+func (m *Multipart) File(fieldName, path string, contentType ...string) *Multipart {
+	part := &multipartPart{
+		fieldName:   fieldName,
+		fileName:    gfile.Basename(path),
+		contentType: mime.TypeByExtension(gfile.Ext(path)),
+		size:        multipartUnknownSize,
+		open: func() (io.ReadCloser, error) {
+			return gfile.Open(path)
+		},
+	}
+	if info, err := gfile.Stat(path); err == nil {
+		part.size = info.Size()
+	}
+	if len(contentType) > 0 {
+		part.contentType = contentType[0]
+	}
+	m.parts = append(m.parts, part)
+	return m
+}
+
+// Reader adds a file part with name `fileName` from `reader`. If the reader is an io.Seeker,
+// it is read from its current offset for each sending, or else it can be sent only once,
+// which fails the retries. Use ReaderFunc for the sources that can be re-opened.
+func (m *Multipart) Reader(fieldName, fileName string, reader io.Reader, contentType ...string) *Multipart {
+	var (
+		once = &multipartReaderOnce{reader: reader, fieldName: fieldName}
+		size = int64(multipartUnknownSize)
+	)
+	if seeker, ok := reader.(io.Seeker); ok {
+		if start, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+			if end, err := seeker.Seek(0, io.SeekEnd); err == nil {
+				if _, err = seeker.Seek(start, io.SeekStart); err == nil {
+					once.start, once.seekable, size = start, true, end-start
+				}
+			}
+		}
+	}
+	return m.ReaderFunc(fieldName, fileName, size, once.open, contentType...)
+}
+
+// ReaderFunc adds a file part with name `fileName`, whose content is opened by `open`
+// for each sending of the request. The `size` is the content size, or -1 if unknown,
+// which makes the request body sent in chunked transfer encoding.
+func (m *Multipart) ReaderFunc(
+	fieldName, fileName string, size int64, open func() (io.ReadCloser, error), contentType ...string,
+) *Multipart {
+	part := &multipartPart{
+		fieldName:   fieldName,
+		fileName:    fileName,
+		contentType: mime.TypeByExtension(gfile.Ext(fileName)),
+		size:        size,
+		open:        open,
+	}
+	if len(contentType) > 0 {
+		part.contentType = contentType[0]
+	}
+	m.parts = append(m.parts, part)
+	return m
+}
+
+// Progress sets the callback reporting the upload progress with the sent and total bytes of body.
+// The total bytes is -1 if it is unknown.
+func (m *Multipart) Progress(progress func(sent, total int64)) *Multipart {
+	m.progress = progress
+	return m
+}
+
+// ContentType returns the Content-Type of body with boundary.
+func (m *Multipart) ContentType() string {
+	return "multipart/form-data; boundary=" + m.boundary
+}
+
+// Size calculates and returns the size of body, which is -1 if the size of any part is unknown.
+func (m *Multipart) Size() int64 {
+	var counter = &multipartCounter{}
+	writer := multipart.NewWriter(counter)
+	if err := writer.SetBoundary(m.boundary); err != nil {
+		return multipartUnknownSize
+	}
+	for _, part := range m.parts {
+		if part.size < 0 {
+			return multipartUnknownSize
+		}
+		if _, err := writer.CreatePart(part.header()); err != nil {
+			return multipartUnknownSize
+		}
+		counter.n += part.size
+	}
+	if err := writer.Close(); err != nil {
+		return multipartUnknownSize
+	}
+	return counter.n
+}
+
+// Open opens and returns the streaming body, whose content is written by a goroutine through io.Pipe.
+func (m *Multipart) Open() (io.ReadCloser, error) {
+	reader, writer := io.Pipe()
+	go func() {
+		_ = writer.CloseWithError(m.writeTo(writer))
+	}()
+	return &multipartBody{
+		PipeReader: reader,
+		multipart:  m,
+		total:      m.Size(),
+	}, nil
+}
+
+// writeTo writes the parts to `w`.
+func (m *Multipart) writeTo(w io.Writer) error {
+	writer := multipart.NewWriter(w)
+	if err := writer.SetBoundary(m.boundary); err != nil {
+		return gerror.Wrapf(err, `set multipart boundary failed`)
+	}
+	for _, part := range m.parts {
+		if err := part.writeTo(writer); err != nil {
+			return err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return gerror.Wrapf(err, `close multipart writer failed`)
+	}
+	return nil
+}
+
+// header returns the MIME header of part.
+func (p *multipartPart) header() textproto.MIMEHeader {
+	header := make(textproto.MIMEHeader)
+	if p.fileName == "" {
+		header.Set(httpHeaderContentDisposition, fmt.Sprintf(
+			`form-data; name="%s"`, quoteEscaper.Replace(p.fieldName),
+		))
+		if p.contentType != "" {
+			header.Set(httpHeaderContentType, p.contentType)
+		}
+		return header
+	}
+	header.Set(httpHeaderContentDisposition, fmt.Sprintf(
+		`form-data; name="%s"; filename="%s"`,
+		quoteEscaper.Replace(p.fieldName), quoteEscaper.Replace(p.fileName),
+	))
+	if p.contentType != "" {
+		header.Set(httpHeaderContentType, p.contentType)
+	} else {
+		header.Set(httpHeaderContentType, httpHeaderContentTypeOctStream)
+	}
+	return header
+}
+
+// writeTo opens the content of part and writes it to `writer`.
+func (p *multipartPart) writeTo(writer *multipart.Writer) error {
+	content, err := p.open()
+	if err != nil {
+		return gerror.Wrapf(err, `open content of multipart field "%s" failed`, p.fieldName)
+	}
+	defer content.Close()
+	partWriter, err := writer.CreatePart(p.header())
+	if err != nil {
+		return gerror.Wrapf(err, `create multipart field "%s" failed`, p.fieldName)
+	}
+	if _, err = io.Copy(partWriter, content); err != nil {
+		return gerror.Wrapf(err, `write multipart field "%s" failed`, p.fieldName)
+	}
+	return nil
+}
+
+// Read implements the io.Reader interface, which reports the upload progress.
+func (b *multipartBody) Read(p []byte) (n int, err error) {
+	n, err = b.PipeReader.Read(p)
+	if n > 0 && b.multipart.progress != nil {
+		b.sent += int64(n)
+		b.multipart.progress(b.sent, b.total)
+	}
+	return
+}
+
+// open opens the reader for sending.
+func (r *multipartReaderOnce) open() (io.ReadCloser, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.seekable {
+		if _, err := r.reader.(io.Seeker).Seek(r.start, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return io.NopCloser(r.reader), nil
+	}
+	if r.consumed {
+		return nil, gerror.NewCodef(
+			gcode.CodeInvalidOperation,
+			`reader of multipart field "%s" cannot be read again, use ReaderFunc instead`,
+			r.fieldName,
+		)
+	}
+	r.consumed = true
+	return io.NopCloser(r.reader), nil
+}
+
+// multipartCounter counts the bytes written.
+type multipartCounter struct {
+	n int64
+}
+
+// Write implements the io.Writer interface.
+func (c *multipartCounter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return len(p), nil
+}
+
+// isStreamingBody checks whether `body` is a streaming body that should not be buffered.
+func isStreamingBody(body io.ReadCloser) bool {
+	_, ok := body.(*multipartBody)
+	return ok
+}
@@ -0,0 +1,477 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gclient
+
+import (
+	"context"
+	"crypto"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gogf/gf/v2/container/gtype"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/internal/intlog"
+	"github.com/gogf/gf/v2/internal/json"
+	"github.com/gogf/gf/v2/text/gstr"
+	"github.com/gogf/gf/v2/util/gconv"
+)
+
+// OAuth2GrantType is the grant type of OAuth2 token request.
+type OAuth2GrantType string
+
+// OAuth2AuthStyle is the style of client authentication in OAuth2 token request.
+type OAuth2AuthStyle string
+
+const (
+	// OAuth2GrantClientCredentials is the client credentials grant defined by RFC 6749.
+	OAuth2GrantClientCredentials OAuth2GrantType = "client_credentials"
+	// OAuth2GrantRefreshToken is the refresh token grant defined by RFC 6749.
+	OAuth2GrantRefreshToken OAuth2GrantType = "refresh_token"
+	// OAuth2GrantJWTBearer is the JWT bearer grant defined by RFC 7523.
+	OAuth2GrantJWTBearer OAuth2GrantType = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+)
+
+const (
+	// OAuth2AuthStyleHeader sends the client credentials in HTTP basic authentication header.
+	OAuth2AuthStyleHeader OAuth2AuthStyle = "header"
+	// OAuth2AuthStyleParams sends the client credentials as "client_id" and "client_secret" parameters.
+	OAuth2AuthStyleParams OAuth2AuthStyle = "params"
+)
+
+// OAuth2Config is the configuration for OAuth2TokenSource, which can be created from
+// configuration component using OAuth2ConfigFromMap, for example:
+//
+//	oauth2:
+//	  grantType:    "client_credentials"
+//	  tokenURL:     "https://auth.example.com/oauth/token"
+//	  clientId:     "client"
+//	  clientSecret: "secret"
+//	  scopes:       ["orders.read"]
+type OAuth2Config struct {
+	// GrantType specifies the grant type of token request. It's OAuth2GrantClientCredentials in default.
+	GrantType OAuth2GrantType `json:"grantType"`
+
+	// TokenURL specifies the token endpoint of authorization server. It's required.
+	TokenURL string `json:"tokenURL"`
+
+	// ClientId and ClientSecret specify the client credentials, which are optional for JWT bearer grant.
+	ClientId     string `json:"clientId"`
+	ClientSecret string `json:"clientSecret"`
+
+	// AuthStyle specifies how the client credentials are sent. It's OAuth2AuthStyleHeader in default.
+	AuthStyle OAuth2AuthStyle `json:"authStyle"`
+
+	// Scopes specifies the scopes of requested token.
+	Scopes []string `json:"scopes"`
+
+	// Params specifies the additional parameters of token request, like "audience" or "resource".
+	Params map[string]string `json:"params"`
+
+	// RefreshToken specifies the refresh token for OAuth2GrantRefreshToken grant.
+	// It's replaced if the authorization server rotates the refresh token.
+	RefreshToken string `json:"refreshToken"`
+
+	// Assertion specifies the static JWT assertion for OAuth2GrantJWTBearer grant.
+	Assertion string `json:"assertion"`
+
+	// PrivateKey specifies the PEM encoded RSA or ECDSA P-256 private key, which signs the
+	// JWT assertion for OAuth2GrantJWTBearer grant if Assertion is not specified.
+	PrivateKey string `json:"privateKey"`
+
+	// KeyId specifies the "kid" header of signed JWT assertion.
+	KeyId string `json:"keyId"`
+
+	// Issuer and Subject specify the "iss" and "sub" claims of signed JWT assertion.
+	// They're ClientId in default.
+	Issuer  string `json:"issuer"`
+	Subject string `json:"subject"`
+
+	// Audience specifies the "aud" claim of signed JWT assertion. It's TokenURL in default.
+	Audience string `json:"audience"`
+
+	// AssertionTTL specifies the lifetime of signed JWT assertion. It's 5 minutes in default.
+	AssertionTTL time.Duration `json:"assertionTTL"`
+
+	// RefreshBefore specifies how long before token expiry the token is refreshed in background,
+	// while the current token is still used. It's 1 minute in default,
+	// and it's no more than half of the token lifetime.
+	RefreshBefore time.Duration `json:"refreshBefore"`
+
+	// AssertionFunc specifies the function creating JWT assertion for OAuth2GrantJWTBearer grant,
+	// which takes priority over Assertion and PrivateKey.
+	AssertionFunc func(ctx context.Context) (string, error) `json:"-"`
+
+	// Client specifies the client sending token requests. It's a new client in default.
+	Client *Client `json:"-"`
+}
+
+// OAuth2Token is the access token issued by authorization server.
+type OAuth2Token struct {
+	AccessToken  string    `json:"accessToken"`
+	TokenType    string    `json:"tokenType"`
+	RefreshToken string    `json:"refreshToken"`
+	Expiry       time.Time `json:"expiry"` // Zero if the token does not expire.
+	expireAt     time.Time // Time when the token is considered expired, a little earlier than Expiry.
+	refreshAt    time.Time // Time when the token is refreshed in background.
+}
+
+// OAuth2TokenSource fetches and caches the OAuth2 access tokens, which are refreshed
+// in background before expiry. It is safe for concurrent use.
+type OAuth2TokenSource struct {
+	config       OAuth2Config
+	signer       crypto.Signer // Signer of JWT assertion, nil if not configured.
+	mu           sync.Mutex    // Protects the current token, which is not held during token request.
+	fetchMu      sync.Mutex    // Serializes token fetching, which also protects the refresh token.
+	token        *OAuth2Token  // Current token, nil if not fetched or invalidated.
+	refreshToken string        // Current refresh token.
+	refreshing   *gtype.Bool   // Whether the token is being refreshed in background.
+}
+
+// oauth2TokenResponse is the token response defined by RFC 6749.
+type oauth2TokenResponse struct {
+	AccessToken      string      `json:"access_token"`
+	TokenType        string      `json:"token_type"`
+	RefreshToken     string      `json:"refresh_token"`
+	ExpiresIn        interface{} `json:"expires_in"`
+	Error            string      `json:"error"`
+	ErrorDescription string      `json:"error_description"`
+}
+
+const (
+	defaultOAuth2AssertionTTL  = 5 * time.Minute
+	defaultOAuth2RefreshBefore = time.Minute
+	// oauth2ExpiryDelta is the duration before expiry that the token is considered expired,
+	// which avoids sending the token that expires in flight. It's no more than a quarter of
+	// the token lifetime.
+	oauth2ExpiryDelta       = 10 * time.Second
+	httpHeaderAuthorization = "Authorization"
+)
+
+// OAuth2ConfigFromMap creates and returns an OAuth2Config from given map,
+// which is usually retrieved from configuration component.
+// The durations can be specified in string like "30s" or "5m".
+func OAuth2ConfigFromMap(m map[string]interface{}) (config OAuth2Config, err error) {
+	if err = gconv.Struct(m, &config); err != nil {
+		return config, gerror.Wrap(err, `convert OAuth2 configuration failed`)
+	}
+	return config, nil
+}
+
+// NewOAuth2TokenSource creates and returns an OAuth2TokenSource with given configuration.
+func NewOAuth2TokenSource(config OAuth2Config) (*OAuth2TokenSource, error) {
+	if config.TokenURL == "" {
+		return nil, gerror.NewCode(gcode.CodeMissingConfiguration, `OAuth2 token URL is required`)
+	}
+	if config.GrantType == "" {
+		config.GrantType = OAuth2GrantClientCredentials
+	}
+	if config.AuthStyle == "" {
+		config.AuthStyle = OAuth2AuthStyleHeader
+	}
+	if config.AssertionTTL <= 0 {
+		config.AssertionTTL = defaultOAuth2AssertionTTL
+	}
+	if config.RefreshBefore <= 0 {
+		config.RefreshBefore = defaultOAuth2RefreshBefore
+	}
+	if config.Client == nil {
+		config.Client = New()
+	}
+	source := &OAuth2TokenSource{
+		config:       config,
+		refreshToken: config.RefreshToken,
+		refreshing:   gtype.NewBool(),
+	}
+	switch config.AuthStyle {
+	case OAuth2AuthStyleHeader, OAuth2AuthStyleParams:
+	default:
+		return nil, gerror.NewCodef(
+			gcode.CodeInvalidConfiguration, `invalid OAuth2 auth style "%s"`, config.AuthStyle,
+		)
+	}
+	switch config.GrantType {
+	case OAuth2GrantClientCredentials:
+		if config.ClientId == "" {
+			return nil, gerror.NewCode(
+				gcode.CodeMissingConfiguration, `OAuth2 client id is required for client credentials grant`,
+			)
+		}
+
+	case OAuth2GrantRefreshToken:
+		if config.RefreshToken == "" {
+			return nil, gerror.NewCode(
+				gcode.CodeMissingConfiguration, `OAuth2 refresh token is required for refresh token grant`,
+			)
+		}
+
+	case OAuth2GrantJWTBearer:
+		if config.AssertionFunc != nil || config.Assertion != "" {
+			break
+		}
+		if config.PrivateKey == "" {
+			return nil, gerror.NewCode(
+				gcode.CodeMissingConfiguration,
+				`OAuth2 assertion or private key is required for JWT bearer grant`,
+			)
+		}
+		signer, err := parseJWTPrivateKey(config.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		source.signer = signer
+
+	default:
+		return nil, gerror.NewCodef(
+			gcode.CodeInvalidConfiguration, `unsupported OAuth2 grant type "%s"`, config.GrantType,
+		)
+	}
+	return source, nil
+}
+
+// MiddlewareOAuth2 returns a client middleware that authorizes requests with the access tokens
+// from `source`. If the server responds status 401, it retries the request once with a new token.
+func MiddlewareOAuth2(source *OAuth2TokenSource) HandlerFunc {
+	return func(c *Client, r *http.Request) (resp *Response, err error) {
+		token, err := source.Token(r.Context())
+		if err != nil {
+			return nil, err
+		}
+		r.Header.Set(httpHeaderAuthorization, token.authorization())
+		reset := resetNext(r)
+		resp, err = c.Next(r)
+		if err != nil || resp == nil || resp.Response == nil ||
+			resp.StatusCode != http.StatusUnauthorized || r.GetBody == nil {
+			return resp, err
+		}
+		// The token may be revoked before expiry, so it retries once with a new token.
+		source.invalidate(token)
+		if token, err = source.Token(r.Context()); err != nil {
+			_ = resp.Close()
+			return nil, err
+		}
+		if r.Body, err = r.GetBody(); err != nil {
+			_ = resp.Close()
+			return nil, gerror.Wrap(err, `re-open request body failed`)
+		}
+		_ = resp.Close()
+		r.Header.Set(httpHeaderAuthorization, token.authorization())
+		reset()
+		return c.Next(r)
+	}
+}
+
+// Token returns the cached token if it's not expired, or else it fetches a new token.
+// The token is refreshed in background if it's about to expire.
+func (s *OAuth2TokenSource) Token(ctx context.Context) (*OAuth2Token, error) {
+	token := s.current()
+	if !token.valid() {
+		return s.fetch(ctx, token)
+	}
+	if token.needsRefresh() && s.refreshing.Cas(false, true) {
+		go func() {
+			defer s.refreshing.Set(false)
+			if _, err := s.fetch(context.WithoutCancel(ctx), token); err != nil {
+				intlog.Errorf(ctx, `refresh OAuth2 token in background failed: %+v`, err)
+			}
+		}()
+	}
+	return token, nil
+}
+
+// current returns the current token, which is nil if not fetched or invalidated.
+func (s *OAuth2TokenSource) current() *OAuth2Token {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.token
+}
+
+// invalidate removes the cached `token`, so that a new token is fetched for the next request.
+func (s *OAuth2TokenSource) invalidate(token *OAuth2Token) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token == token {
+		s.token = nil
+	}
+}
+
+// fetch requests and caches a new token, replacing the `stale` one.
+// It returns the token fetched by other goroutine if there's one.
+func (s *OAuth2TokenSource) fetch(ctx context.Context, stale *OAuth2Token) (*OAuth2Token, error) {
+	s.fetchMu.Lock()
+	defer s.fetchMu.Unlock()
+	if token := s.current(); token != nil && token != stale && token.valid() {
+		return token, nil
+	}
+	params, err := s.tokenParams(ctx)
+	if err != nil {
+		return nil, err
+	}
+	token, err := s.requestToken(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if token.RefreshToken != "" {
+		s.refreshToken = token.RefreshToken
+	}
+	s.mu.Lock()
+	s.token = token
+	s.mu.Unlock()
+	return token, nil
+}
+
+// tokenParams builds the parameters of token request.
+func (s *OAuth2TokenSource) tokenParams(ctx context.Context) (url.Values, error) {
+	params := url.Values{}
+	for k, v := range s.config.Params {
+		params.Set(k, v)
+	}
+	params.Set("grant_type", string(s.config.GrantType))
+	if len(s.config.Scopes) > 0 {
+		params.Set("scope", strings.Join(s.config.Scopes, " "))
+	}
+	switch s.config.GrantType {
+	case OAuth2GrantRefreshToken:
+		params.Set("refresh_token", s.refreshToken)
+
+	case OAuth2GrantJWTBearer:
+		assertion, err := s.assertion(ctx)
+		if err != nil {
+			return nil, err
+		}
+		params.Set("assertion", assertion)
+	}
+	if s.config.AuthStyle == OAuth2AuthStyleParams && s.config.ClientId != "" {
+		params.Set("client_id", s.config.ClientId)
+		params.Set("client_secret", s.config.ClientSecret)
+	}
+	return params, nil
+}
+
+// assertion returns the JWT assertion for JWT bearer grant.
+func (s *OAuth2TokenSource) assertion(ctx context.Context) (string, error) {
+	if s.config.AssertionFunc != nil {
+		return s.config.AssertionFunc(ctx)
+	}
+	if s.config.Assertion != "" {
+		return s.config.Assertion, nil
+	}
+	var (
+		now    = time.Now()
+		claims = map[string]interface{}{
+			"iss": s.config.Issuer,
+			"sub": s.config.Subject,
+			"aud": s.config.Audience,
+			"iat": now.Unix(),
+			"exp": now.Add(s.config.AssertionTTL).Unix(),
+		}
+	)
+	if claims["iss"] == "" {
+		claims["iss"] = s.config.ClientId
+	}
+	if claims["sub"] == "" {
+		claims["sub"] = s.config.ClientId
+	}
+	if claims["aud"] == "" {
+		claims["aud"] = s.config.TokenURL
+	}
+	return signJWT(s.signer, s.config.KeyId, claims)
+}
+
+// requestToken sends the token request with `params` and parses the token response.
+func (s *OAuth2TokenSource) requestToken(ctx context.Context, params url.Values) (*OAuth2Token, error) {
+	client := s.config.Client.Clone()
+	client.SetHeader("Accept", httpHeaderContentTypeJson)
+	client.SetContentType(httpHeaderContentTypeForm)
+	if s.config.AuthStyle == OAuth2AuthStyleHeader && s.config.ClientId != "" {
+		// The client credentials are form-encoded before basic authentication, see RFC 6749 2.3.1.
+		client.SetBasicAuth(url.QueryEscape(s.config.ClientId), url.QueryEscape(s.config.ClientSecret))
+	}
+	var requestTime = time.Now()
+	resp, err := client.Post(ctx, s.config.TokenURL, params.Encode())
+	if err != nil {
+		return nil, gerror.Wrapf(err, `request OAuth2 token from "%s" failed`, s.config.TokenURL)
+	}
+	defer resp.Close()
+	var (
+		body          = resp.ReadAll()
+		tokenResponse oauth2TokenResponse
+	)
+	if gstr.Contains(resp.Header.Get(httpHeaderContentType), httpHeaderContentTypeForm) {
+		// Some authorization servers respond the token in form encoding.
+		values, _ := url.ParseQuery(string(body))
+		tokenResponse = oauth2TokenResponse{
+			AccessToken:      values.Get("access_token"),
+			TokenType:        values.Get("token_type"),
+			RefreshToken:     values.Get("refresh_token"),
+			ExpiresIn:        values.Get("expires_in"),
+			Error:            values.Get("error"),
+			ErrorDescription: values.Get("error_description"),
+		}
+	} else if len(body) > 0 {
+		_ = json.Unmarshal(body, &tokenResponse)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 || tokenResponse.AccessToken == "" {
+		if tokenResponse.Error != "" {
+			return nil, gerror.NewCodef(
+				gcode.CodeNotAuthorized, `request OAuth2 token failed with status %d: %s %s`,
+				resp.StatusCode, tokenResponse.Error, tokenResponse.ErrorDescription,
+			)
+		}
+		return nil, gerror.NewCodef(
+			gcode.CodeNotAuthorized, `request OAuth2 token failed with status %d: %s`,
+			resp.StatusCode, body,
+		)
+	}
+	token := &OAuth2Token{
+		AccessToken:  tokenResponse.AccessToken,
+		TokenType:    tokenResponse.TokenType,
+		RefreshToken: tokenResponse.RefreshToken,
+	}
+	if expiresIn := gconv.Int64(tokenResponse.ExpiresIn); expiresIn > 0 {
+		var (
+			lifetime      = time.Duration(expiresIn) * time.Second
+			expiryDelta   = oauth2ExpiryDelta
+			refreshBefore = s.config.RefreshBefore
+		)
+		if expiryDelta > lifetime/4 {
+			expiryDelta = lifetime / 4
+		}
+		if refreshBefore > lifetime/2 {
+			refreshBefore = lifetime / 2
+		}
+		token.Expiry = requestTime.Add(lifetime)
+		token.expireAt = token.Expiry.Add(-expiryDelta)
+		token.refreshAt = token.Expiry.Add(-refreshBefore)
+	}
+	return token, nil
+}
+
+// valid checks whether the token is usable.
+func (t *OAuth2Token) valid() bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+	return t.expireAt.IsZero() || time.Now().Before(t.expireAt)
+}
+
+// needsRefresh checks whether the token is about to expire and should be refreshed in background.
+func (t *OAuth2Token) needsRefresh() bool {
+	return !t.refreshAt.IsZero() && !time.Now().Before(t.refreshAt)
+}
+
+// authorization returns the value of header "Authorization" for the token.
+func (t *OAuth2Token) authorization() string {
+	tokenType := t.TokenType
+	if tokenType == "" || strings.EqualFold(tokenType, "bearer") {
+		tokenType = "Bearer"
+	}
+	return tokenType + " " + t.AccessToken
+}
@@ -0,0 +1,103 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gclient
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/internal/json"
+	"github.com/gogf/gf/v2/util/guid"
+)
+
+// parseJWTPrivateKey parses the PEM encoded RSA or ECDSA P-256 private key for signing JWT.
+func parseJWTPrivateKey(privateKey string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(privateKey))
+	if block == nil {
+		return nil, gerror.NewCode(gcode.CodeInvalidConfiguration, `invalid PEM encoded private key`)
+	}
+	var (
+		key interface{}
+		err error
+	)
+	if key, err = x509.ParsePKCS8PrivateKey(block.Bytes); err != nil {
+		if key, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+			if key, err = x509.ParseECPrivateKey(block.Bytes); err != nil {
+				return nil, gerror.WrapCode(gcode.CodeInvalidConfiguration, err, `parse private key failed`)
+			}
+		}
+	}
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return k, nil
+	case *ecdsa.PrivateKey:
+		if k.Curve == elliptic.P256() {
+			return k, nil
+		}
+	}
+	return nil, gerror.NewCodef(
+		gcode.CodeInvalidConfiguration, `unsupported private key type %T, RSA or ECDSA P-256 is required`, key,
+	)
+}
+
+// signJWT creates a JWT with `claims`, which is signed by `signer` using RS256 or ES256.
+func signJWT(signer crypto.Signer, keyId string, claims map[string]interface{}) (string, error) {
+	header := map[string]interface{}{
+		"typ": "JWT",
+		"alg": "RS256",
+	}
+	if _, ok := signer.(*ecdsa.PrivateKey); ok {
+		header["alg"] = "ES256"
+	}
+	if keyId != "" {
+		header["kid"] = keyId
+	}
+	if _, ok := claims["jti"]; !ok {
+		claims["jti"] = guid.S()
+	}
+	headerBytes, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	claimsBytes, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	var (
+		signingInput = base64.RawURLEncoding.EncodeToString(headerBytes) + "." +
+			base64.RawURLEncoding.EncodeToString(claimsBytes)
+		digest    = sha256.Sum256([]byte(signingInput))
+		signature []byte
+	)
+	switch key := signer.(type) {
+	case *ecdsa.PrivateKey:
+		// The ES256 signature is the concatenation of fixed length R and S, see RFC 7518 3.4.
+		var r, s *big.Int
+		if r, s, err = ecdsa.Sign(rand.Reader, key, digest[:]); err != nil {
+			return "", gerror.Wrap(err, `sign JWT failed`)
+		}
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+
+	default:
+		if signature, err = signer.Sign(rand.Reader, digest[:], crypto.SHA256); err != nil {
+			return "", gerror.Wrap(err, `sign JWT failed`)
+		}
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
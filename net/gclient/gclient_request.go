@@ -170,8 +170,12 @@ func (c *Client) prepareRequest(ctx context.Context, method, url string, data ..
 	var (
 		params             string
 		allowFileUploading = true
+		multipartData      *Multipart
 	)
 	if len(data) > 0 {
+		multipartData, _ = data[0].(*Multipart)
+	}
+	if len(data) > 0 && multipartData == nil {
 		switch c.header[httpHeaderContentType] {
 		case httpHeaderContentTypeJson:
 			switch data[0].(type) {
@@ -203,7 +207,22 @@ func (c *Client) prepareRequest(ctx context.Context, method, url string, data ..
 			params = httputil.BuildParams(data[0], c.noUrlEncode)
 		}
 	}
-	if method == http.MethodGet {
+	if multipartData != nil {
+		// Streaming multipart request, whose body is re-opened by GetBody for retries.
+		var body io.ReadCloser
+		if body, err = multipartData.Open(); err != nil {
+			return nil, err
+		}
+		if req, err = http.NewRequest(method, url, body); err != nil {
+			_ = body.Close()
+			return nil, gerror.Wrapf(
+				err, `http.NewRequest failed for method "%s" and URL "%s"`, method, url,
+			)
+		}
+		req.GetBody = multipartData.Open
+		req.ContentLength = multipartData.Size()
+		req.Header.Set(httpHeaderContentType, multipartData.ContentType())
+	} else if method == http.MethodGet {
 		var bodyBuffer *bytes.Buffer
 		if params != "" {
 			switch c.header[httpHeaderContentType] {
@@ -363,11 +382,26 @@ func (c *Client) callRequest(req *http.Request) (resp *Response, err error) {
 	// Dump feature.
 	// The request body can be reused for dumping
 	// raw HTTP request-response procedure.
-	reqBodyContent, _ := io.ReadAll(req.Body)
-	resp.requestBody = reqBodyContent
-	for {
+	// The streaming body is not buffered, which is re-opened by GetBody for retries.
+	if !isStreamingBody(req.Body) || req.GetBody == nil {
+		reqBodyContent, _ := io.ReadAll(req.Body)
+		resp.requestBody = reqBodyContent
 		req.Body = utils.NewReadCloser(reqBodyContent, false)
-		if resp.Response, err = c.Do(req); err != nil {
+		req.GetBody = func() (io.ReadCloser, error) {
+			return utils.NewReadCloser(reqBodyContent, false), nil
+		}
+	}
+	if c.retryPolicy != nil {
+		err = c.callRequestWithRetryPolicy(req, resp)
+		return resp, err
+	}
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 {
+			if req.Body, err = req.GetBody(); err != nil {
+				return resp, gerror.Wrapf(err, `re-open request body failed`)
+			}
+		}
+		if resp.Response, err = c.send(req); err != nil {
 			err = gerror.Wrapf(err, `request failed`)
 			// The response might not be nil when err != nil.
 			if resp.Response != nil {
@@ -0,0 +1,321 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gclient
+
+import (
+	"errors"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/gogf/gf/v2"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/internal/httputil"
+	"github.com/gogf/gf/v2/os/gmetric"
+	"github.com/gogf/gf/v2/util/grand"
+)
+
+// RetryPolicy is the policy retrying failed requests with exponential backoff.
+//
+// The delay before the Nth retry is InitialInterval * Multiplier^(N-1), randomized by Jitter
+// and limited by MaxInterval. If the response has header "Retry-After", the delay is from the header.
+type RetryPolicy struct {
+	// MaxRetries specifies the maximum retry count after the first attempt.
+	// It does not retry if it is not positive.
+	MaxRetries int
+
+	// InitialInterval specifies the delay before the first retry. It's 100ms in default.
+	InitialInterval time.Duration
+
+	// MaxInterval specifies the maximum delay between retries. It's 10 seconds in default.
+	// The request is not retried if the delay required by header "Retry-After" exceeds it.
+	MaxInterval time.Duration
+
+	// Multiplier specifies the factor by which the delay increases after each retry. It's 2 in default.
+	Multiplier float64
+
+	// Jitter specifies the randomization factor of delays in range (0, 1], which makes the delay
+	// a random value in [delay*(1-Jitter), delay*(1+Jitter)]. It avoids the retries of clients
+	// arriving at the same time. It's 0.2 in default, and it disables randomization if negative.
+	Jitter float64
+
+	// StatusCodes specifies the response status codes that are retried.
+	// It's 429, 502, 503 and 504 in default.
+	StatusCodes []int
+
+	// ErrorClasses specifies the classes of network errors that are retried.
+	// It's RetryErrorAll in default.
+	ErrorClasses RetryErrorClass
+
+	// RetryNonIdempotent specifies whether to retry the requests of non-idempotent methods like POST and PATCH.
+	// Note that the requests having header "Idempotency-Key" are always considered idempotent.
+	RetryNonIdempotent bool
+
+	// ShouldRetry optionally specifies the custom function checking whether the attempt is retried,
+	// which replaces the checks of StatusCodes and ErrorClasses.
+	// Either `resp` or `err` is nil. Note that the method idempotency is still checked before it.
+	ShouldRetry func(resp *http.Response, err error) bool
+}
+
+// RetryErrorClass is the class of network errors, which can be combined using operator "|".
+type RetryErrorClass int
+
+const (
+	RetryErrorConnection RetryErrorClass = 1 << iota // Connection errors like connection refused, reset and closed.
+	RetryErrorTimeout                                // Timeout errors of network operations like dialing and reading.
+	RetryErrorDNS                                    // Temporary DNS resolving errors.
+
+	// RetryErrorAll specifies all the network error classes.
+	RetryErrorAll = RetryErrorConnection | RetryErrorTimeout | RetryErrorDNS
+)
+
+const (
+	defaultRetryInitialInterval = 100 * time.Millisecond
+	defaultRetryMaxInterval     = 10 * time.Second
+	defaultRetryMultiplier      = 2
+	defaultRetryJitter          = 0.2
+	httpHeaderRetryAfter        = `Retry-After`
+	tracingSpanHttpAttempt      = `http.attempt`
+	tracingAttrHttpAttempt      = `http.attempt.number`
+	tracingAttrHttpStatusCode   = `http.response.status_code`
+)
+
+var (
+	// defaultRetryStatusCodes is the default response status codes that are retried.
+	defaultRetryStatusCodes = []int{
+		http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	}
+
+	// retryTransportConnectionErrors is the messages of connection errors of http.Transport.
+	retryTransportConnectionErrors = []string{
+		"server closed idle connection",
+		"transport connection broken",
+		"connection closed before",
+		"unexpected EOF",
+	}
+)
+
+// withDefaults returns a copy of the policy with default values for the unset fields.
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.InitialInterval <= 0 {
+		p.InitialInterval = defaultRetryInitialInterval
+	}
+	if p.MaxInterval <= 0 {
+		p.MaxInterval = defaultRetryMaxInterval
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = defaultRetryMultiplier
+	}
+	if p.Jitter == 0 {
+		p.Jitter = defaultRetryJitter
+	} else if p.Jitter > 1 {
+		p.Jitter = 1
+	}
+	if p.StatusCodes == nil {
+		p.StatusCodes = defaultRetryStatusCodes
+	}
+	if p.ErrorClasses == 0 {
+		p.ErrorClasses = RetryErrorAll
+	}
+	return p
+}
+
+// isRetryableRequest checks whether `req` can be retried by its method idempotency.
+func (p RetryPolicy) isRetryableRequest(req *http.Request) bool {
+	return p.RetryNonIdempotent || httputil.IsIdempotentRequest(req)
+}
+
+// shouldRetry checks whether the attempt with result `resp` and `err` should be retried.
+func (p RetryPolicy) shouldRetry(resp *http.Response, err error) bool {
+	if p.ShouldRetry != nil {
+		return p.ShouldRetry(resp, err)
+	}
+	if err != nil {
+		return getRetryErrorClass(err)&p.ErrorClasses != 0
+	}
+	for _, code := range p.StatusCodes {
+		if resp.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// retryDelay calculates and returns the delay before the retry after `attempt`.
+// It returns false if it should not be retried as the delay required by server is too long.
+func (p RetryPolicy) retryDelay(attempt int, resp *http.Response) (time.Duration, bool) {
+	if resp != nil {
+		if delay, ok := parseRetryAfter(resp.Header.Get(httpHeaderRetryAfter)); ok {
+			return delay, delay <= p.MaxInterval
+		}
+	}
+	delay := float64(p.InitialInterval) * math.Pow(p.Multiplier, float64(attempt-1))
+	if delay > float64(p.MaxInterval) {
+		delay = float64(p.MaxInterval)
+	}
+	if p.Jitter > 0 {
+		if span := int(delay * p.Jitter); span > 0 {
+			delay += float64(grand.Intn(2*span+1) - span)
+		}
+	}
+	if delay > float64(p.MaxInterval) {
+		delay = float64(p.MaxInterval)
+	}
+	return time.Duration(delay), true
+}
+
+// parseRetryAfter parses the value of header "Retry-After", which is in seconds or HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		delay := time.Until(t)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
+
+// getRetryErrorClass returns the network error class of `err`, which is 0 if it is not a network error.
+func getRetryErrorClass(err error) RetryErrorClass {
+	var (
+		dnsErr *net.DNSError
+		netErr net.Error
+		opErr  *net.OpError
+	)
+	switch {
+	case errors.As(err, &dnsErr):
+		if dnsErr.IsTemporary || dnsErr.IsTimeout {
+			return RetryErrorDNS
+		}
+		return 0
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return RetryErrorTimeout
+	case
+		errors.Is(err, io.EOF),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, syscall.ECONNREFUSED),
+		errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, syscall.ECONNABORTED),
+		errors.Is(err, syscall.EPIPE),
+		errors.Is(err, net.ErrClosed):
+		return RetryErrorConnection
+	// The request is not sent yet if dialing fails, but it might be sent for other operations.
+	case errors.As(err, &opErr) && opErr.Op == "dial":
+		return RetryErrorConnection
+	}
+	// The connection errors of http.Transport are not exported, which are checked by their messages.
+	errMsg := err.Error()
+	for _, msg := range retryTransportConnectionErrors {
+		if strings.Contains(errMsg, msg) {
+			return RetryErrorConnection
+		}
+	}
+	return 0
+}
+
+// callRequestWithRetryPolicy sends `req` using the retry policy of client,
+// and sets the response of the last attempt to `resp`.
+// The request body is re-opened by `req.GetBody` for each retry.
+func (c *Client) callRequestWithRetryPolicy(req *http.Request, resp *Response) (err error) {
+	var (
+		ctx       = req.Context()
+		policy    = c.retryPolicy.withDefaults()
+		retryable = policy.MaxRetries > 0 && policy.isRetryableRequest(req)
+	)
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 {
+			if req.Body, err = req.GetBody(); err != nil {
+				return gerror.Wrapf(err, `re-open request body failed`)
+			}
+		}
+		resp.Response, err = c.doAttempt(req, attempt)
+		// The response might not be nil when err != nil.
+		if err != nil && resp.Response != nil {
+			_ = resp.Response.Body.Close()
+		}
+		if !retryable || attempt > policy.MaxRetries || ctx.Err() != nil {
+			break
+		}
+		if !policy.shouldRetry(resp.Response, err) {
+			break
+		}
+		delay, ok := policy.retryDelay(attempt, resp.Response)
+		if !ok {
+			break
+		}
+		if err == nil {
+			// Drain the body for reusing the connection.
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Response.Body, 4096))
+			_ = resp.Response.Body.Close()
+			resp.Response = nil
+		}
+		c.handleMetricsRetry(req)
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return gerror.Wrap(ctx.Err(), `request canceled while waiting for retry`)
+		}
+	}
+	if err != nil {
+		err = gerror.Wrapf(err, `request failed`)
+	}
+	return err
+}
+
+// doAttempt sends `req` as its `attempt`th attempt in a tracing span.
+func (c *Client) doAttempt(req *http.Request, attempt int) (*http.Response, error) {
+	tr := otel.GetTracerProvider().Tracer(
+		instrumentName,
+		trace.WithInstrumentationVersion(gf.VERSION),
+	)
+	ctx, span := tr.Start(req.Context(), tracingSpanHttpAttempt, trace.WithSpanKind(trace.SpanKindInternal))
+	defer span.End()
+
+	span.SetAttributes(attribute.Int(tracingAttrHttpAttempt, attempt))
+	resp, err := c.send(req.WithContext(ctx))
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return resp, err
+	}
+	span.SetAttributes(attribute.Int(tracingAttrHttpStatusCode, resp.StatusCode))
+	return resp, nil
+}
+
+func (c *Client) handleMetricsRetry(r *http.Request) {
+	if !gmetric.IsEnabled() {
+		return
+	}
+	metricManager.HttpClientRequestRetryTotal.Inc(
+		r.Context(),
+		metricManager.GetMetricOptionForRequest(r),
+	)
+}
@@ -0,0 +1,115 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gclient
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gogf/gf/v2/container/gtype"
+	"github.com/gogf/gf/v2/errors/gerror"
+)
+
+// requestSigner signs request `r` with the signing time `t`.
+type requestSigner func(r *http.Request, t time.Time) error
+
+// signClock is the clock for request signing, which corrects the local clock by the server clock.
+type signClock struct {
+	offset *gtype.Int64 // Offset in nanoseconds from local clock to server clock.
+}
+
+const (
+	// signClockSkewThreshold is the minimum clock skew that is corrected.
+	signClockSkewThreshold = 30 * time.Second
+)
+
+// middlewareSign returns a client middleware that signs requests using `sign`.
+//
+// If the server rejects the request with status 401 or 403, and its "Date" header shows that the
+// local clock is skewed, it corrects the signing clock and retries the request once.
+func middlewareSign(sign requestSigner) HandlerFunc {
+	clock := &signClock{offset: gtype.NewInt64()}
+	return func(c *Client, r *http.Request) (resp *Response, err error) {
+		if err = sign(r, clock.now()); err != nil {
+			return nil, err
+		}
+		reset := resetNext(r)
+		resp, err = c.Next(r)
+		if err != nil || resp == nil || resp.Response == nil || r.GetBody == nil || !clock.correct(resp) {
+			return resp, err
+		}
+		if r.Body, err = r.GetBody(); err != nil {
+			_ = resp.Close()
+			return nil, gerror.Wrap(err, `re-open request body failed`)
+		}
+		_ = resp.Close()
+		if err = sign(r, clock.now()); err != nil {
+			return nil, err
+		}
+		reset()
+		return c.Next(r)
+	}
+}
+
+// now returns the current time of server clock.
+func (c *signClock) now() time.Time {
+	return time.Now().Add(time.Duration(c.offset.Val())).UTC()
+}
+
+// correct updates the clock offset if the request is rejected for clock skew,
+// and returns whether the offset is changed.
+func (c *signClock) correct(resp *Response) bool {
+	if resp.StatusCode != http.StatusUnauthorized && resp.StatusCode != http.StatusForbidden {
+		return false
+	}
+	serverTime, err := http.ParseTime(resp.Header.Get(httpHeaderDate))
+	if err != nil {
+		return false
+	}
+	var (
+		offset = serverTime.Sub(time.Now())
+		change = offset - time.Duration(c.offset.Val())
+	)
+	if change > -signClockSkewThreshold && change < signClockSkewThreshold {
+		return false
+	}
+	c.offset.Set(int64(offset))
+	return true
+}
+
+// requestBodyHash returns the hex encoded SHA256 of the request body, which is read from the
+// replayable body by GetBody. It returns empty string if the body is streaming or not replayable.
+func requestBodyHash(r *http.Request) (string, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		sum := sha256.Sum256(nil)
+		return hex.EncodeToString(sum[:]), nil
+	}
+	if isStreamingBody(r.Body) || r.GetBody == nil {
+		return "", nil
+	}
+	body, err := r.GetBody()
+	if err != nil {
+		return "", gerror.Wrap(err, `re-open request body failed`)
+	}
+	defer body.Close()
+	h := sha256.New()
+	if _, err = io.Copy(h, body); err != nil {
+		return "", gerror.Wrap(err, `read request body failed`)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// requestHost returns the host of request `r` for signing.
+func requestHost(r *http.Request) string {
+	if r.Host != "" {
+		return r.Host
+	}
+	return r.URL.Host
+}
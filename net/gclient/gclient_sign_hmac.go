@@ -0,0 +1,82 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gclient
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gogf/gf/v2/internal/httputil"
+)
+
+// HMACSignOptions is the options for MiddlewareHMACSign.
+type HMACSignOptions struct {
+	// KeyId specifies the identifier of the key, by which the server finds the secret.
+	KeyId string
+
+	// Secret specifies the secret key of signing.
+	Secret string
+
+	// Algorithm specifies the signing algorithm, "HMAC-SHA256" or "HMAC-SHA512".
+	// It's "HMAC-SHA256" in default.
+	Algorithm string
+
+	// SignedHeaders specifies the request headers that are signed besides "Host",
+	// "X-Signature-Timestamp" and "X-Content-SHA256". It's "Content-Type" in default.
+	SignedHeaders []string
+
+	// UnsignedPayload specifies whether the request body is not signed. The streaming body like
+	// Multipart is never signed, and its hash is "UNSIGNED-PAYLOAD", which should be allowed by the server.
+	UnsignedPayload bool
+}
+
+// MiddlewareHMACSign returns a client middleware that signs requests using the HMAC scheme,
+// which can be verified by ghttp.MiddlewareHMACVerify. The signature is sent in header like:
+//
+//	Authorization: HMAC-SHA256 KeyId=<id>, SignedHeaders=<h1;h2>, Signature=<hex>
+func MiddlewareHMACSign(options HMACSignOptions) HandlerFunc {
+	if options.Algorithm == "" {
+		options.Algorithm = httputil.SignAlgorithmHMACSHA256
+	}
+	if len(options.SignedHeaders) == 0 {
+		options.SignedHeaders = []string{httpHeaderContentType}
+	}
+	signedHeaders := append([]string{
+		"host", httputil.SignHeaderTimestamp, httputil.SignHeaderContentSHA256,
+	}, options.SignedHeaders...)
+	return middlewareSign(func(r *http.Request, t time.Time) error {
+		bodyHash := httputil.SignUnsignedPayload
+		if !options.UnsignedPayload {
+			hash, err := requestBodyHash(r)
+			if err != nil {
+				return err
+			}
+			if hash != "" {
+				bodyHash = hash
+			}
+		}
+		timestamp := strconv.FormatInt(t.Unix(), 10)
+		r.Header.Set(httputil.SignHeaderTimestamp, timestamp)
+		r.Header.Set(httputil.SignHeaderContentSHA256, bodyHash)
+		canonicalRequest, signed := httputil.SignCanonicalRequest(
+			r.Method, r.URL, requestHost(r), r.Header, signedHeaders, bodyHash,
+		)
+		signature, err := httputil.SignSignature(options.Algorithm, options.Secret, timestamp, canonicalRequest)
+		if err != nil {
+			return err
+		}
+		r.Header.Set(httpHeaderAuthorization, httputil.SignAuthorization{
+			Algorithm:     options.Algorithm,
+			KeyId:         options.KeyId,
+			SignedHeaders: strings.Split(signed, ";"),
+			Signature:     signature,
+		}.String())
+		return nil
+	})
+}
@@ -49,9 +49,12 @@ func newClientTracerTracing(
 		headers: make(map[string]interface{}),
 	}
 
-	reqBodyContent, _ := io.ReadAll(ct.request.Body)
-	ct.requestBody = reqBodyContent
-	ct.request.Body = utils.NewReadCloser(reqBodyContent, false)
+	// The streaming body is not buffered for tracing.
+	if !isStreamingBody(ct.request.Body) {
+		reqBodyContent, _ := io.ReadAll(ct.request.Body)
+		ct.requestBody = reqBodyContent
+		ct.request.Body = utils.NewReadCloser(reqBodyContent, false)
+	}
 
 	return &httptrace.ClientTrace{
 		GetConn:              ct.GetConn,
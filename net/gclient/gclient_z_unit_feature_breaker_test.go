@@ -0,0 +1,172 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gclient_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/container/gtype"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/gclient"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/net/gsvc"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/gogf/gf/v2/util/guid"
+)
+
+// breakerTestDiscovery is the discovery resolving services to static endpoints.
+type breakerTestDiscovery struct {
+	endpoints gsvc.Endpoints
+}
+
+// breakerTestWatcher is the watcher that never changes.
+type breakerTestWatcher struct{}
+
+func (d *breakerTestDiscovery) Search(ctx context.Context, in gsvc.SearchInput) ([]gsvc.Service, error) {
+	return []gsvc.Service{&gsvc.LocalService{Name: in.Name, Endpoints: d.endpoints}}, nil
+}
+
+func (d *breakerTestDiscovery) Watch(ctx context.Context, key string) (gsvc.Watcher, error) {
+	return &breakerTestWatcher{}, nil
+}
+
+func (w *breakerTestWatcher) Proceed() ([]gsvc.Service, error) {
+	select {}
+}
+
+func (w *breakerTestWatcher) Close() error {
+	return nil
+}
+
+func Test_Client_CircuitBreaker(t *testing.T) {
+	var (
+		counter = gtype.NewInt()
+		s       = g.Server(guid.S())
+	)
+	s.BindHandler("/call", func(r *ghttp.Request) {
+		counter.Add(1)
+		if r.Get("fail").Bool() {
+			r.Response.WriteStatus(http.StatusInternalServerError)
+			return
+		}
+		r.Response.Write("ok")
+	})
+	s.SetDumpRouterMap(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	client := g.Client().Prefix(fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort()))
+	client.Use(gclient.MiddlewareCircuitBreaker(gclient.CircuitBreakerOptions{
+		MinRequests: 2,
+		OpenTimeout: 200 * time.Millisecond,
+	}))
+	// Trips on error rate and fails fast.
+	gtest.C(t, func(t *gtest.T) {
+		t.Assert(client.GetContent(ctx, "/call"), "ok")
+		t.Assert(client.GetContent(ctx, "/call"), "ok")
+		t.Assert(client.GetContent(ctx, "/call?fail=1"), "Internal Server Error")
+		t.Assert(client.GetContent(ctx, "/call?fail=1"), "Internal Server Error")
+
+		counter.Set(0)
+		_, err := client.Get(ctx, "/call")
+		t.AssertNE(err, nil)
+		t.Assert(gerror.Code(err), gcode.CodeServerBusy)
+		t.Assert(errors.Is(err, gclient.ErrCircuitBreakerOpen), true)
+		t.Assert(counter.Val(), 0)
+	})
+	// Half-open state.
+	gtest.C(t, func(t *gtest.T) {
+		time.Sleep(250 * time.Millisecond)
+		t.Assert(client.GetContent(ctx, "/call?fail=1"), "Internal Server Error")
+		_, err := client.Get(ctx, "/call")
+		t.Assert(gerror.Code(err), gcode.CodeServerBusy)
+
+		time.Sleep(250 * time.Millisecond)
+		t.Assert(client.GetContent(ctx, "/call"), "ok")
+		t.Assert(client.GetContent(ctx, "/call"), "ok")
+	})
+}
+
+func Test_Client_CircuitBreaker_Discovery(t *testing.T) {
+	var (
+		healthy   = g.Server(guid.S())
+		unhealthy = g.Server(guid.S())
+		counter   = gtype.NewInt()
+	)
+	healthy.BindHandler("/call", func(r *ghttp.Request) {
+		r.Response.Write("ok")
+	})
+	unhealthy.BindHandler("/call", func(r *ghttp.Request) {
+		counter.Add(1)
+		r.Response.WriteStatus(http.StatusServiceUnavailable)
+	})
+	for _, s := range []*ghttp.Server{healthy, unhealthy} {
+		s.SetDumpRouterMap(false)
+		s.Start()
+		defer s.Shutdown()
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	newClient := func(endpoints string, scope gclient.CircuitBreakerScope) *gclient.Client {
+		client := g.Client().Discovery(&breakerTestDiscovery{endpoints: gsvc.NewEndpoints(endpoints)})
+		client.Use(gclient.MiddlewareCircuitBreaker(gclient.CircuitBreakerOptions{
+			Scope:       scope,
+			MinRequests: 1,
+			OpenTimeout: time.Minute,
+		}))
+		return client
+	}
+	// The tripped endpoint is skipped in selection.
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			url    = fmt.Sprintf("http://%s/call", guid.S())
+			client = newClient(fmt.Sprintf(
+				"127.0.0.1:%d,127.0.0.1:%d", healthy.GetListenedPort(), unhealthy.GetListenedPort(),
+			), gclient.CircuitBreakerScopeHost)
+		)
+		for i := 0; i < 4; i++ {
+			_, _ = client.Get(ctx, url)
+		}
+		t.Assert(counter.Val(), 1)
+		for i := 0; i < 10; i++ {
+			t.Assert(client.GetContent(ctx, url), "ok")
+		}
+		t.Assert(counter.Val(), 1)
+
+		// The breakers of other middleware do not skip the endpoint.
+		other := g.Client().Discovery(&breakerTestDiscovery{endpoints: gsvc.NewEndpoints(fmt.Sprintf(
+			"127.0.0.1:%d,127.0.0.1:%d", healthy.GetListenedPort(), unhealthy.GetListenedPort(),
+		))})
+		other.Use(gclient.MiddlewareCircuitBreaker(gclient.CircuitBreakerOptions{MinRequests: 100}))
+		for i := 0; i < 4; i++ {
+			_, _ = other.Get(ctx, url)
+		}
+		t.Assert(counter.Val(), 3)
+	})
+	// The breaker of service trips for all endpoints.
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			url    = fmt.Sprintf("http://%s/call", guid.S())
+			client = newClient(
+				fmt.Sprintf("127.0.0.1:%d", unhealthy.GetListenedPort()), gclient.CircuitBreakerScopeService,
+			)
+		)
+		counter.Set(0)
+		t.Assert(client.GetContent(ctx, url), "Service Unavailable")
+		_, err := client.Get(ctx, url)
+		t.Assert(gerror.Code(err), gcode.CodeServerBusy)
+		t.Assert(counter.Val(), 1)
+	})
+}
@@ -0,0 +1,167 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gclient_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/container/gtype"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/gclient"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/gogf/gf/v2/util/guid"
+)
+
+func Test_Client_Cache(t *testing.T) {
+	var (
+		counter = gtype.NewInt()
+		failing = gtype.NewBool()
+		s       = g.Server(guid.S())
+	)
+	s.BindHandler("/fresh", func(r *ghttp.Request) {
+		r.Response.Header().Set("Cache-Control", "max-age=60")
+		r.Response.Write(counter.Add(1))
+	})
+	s.BindHandler("/etag", func(r *ghttp.Request) {
+		counter.Add(1)
+		r.Response.Header().Set("Cache-Control", "no-cache")
+		r.Response.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			r.Response.WriteHeader(http.StatusNotModified)
+			return
+		}
+		r.Response.Write("etag")
+	})
+	s.BindHandler("/vary", func(r *ghttp.Request) {
+		counter.Add(1)
+		r.Response.Header().Set("Cache-Control", "max-age=60")
+		r.Response.Header().Set("Vary", "X-Lang")
+		r.Response.Write(r.Header.Get("X-Lang"))
+	})
+	s.BindHandler("/stale", func(r *ghttp.Request) {
+		if failing.Val() {
+			r.Response.WriteStatus(http.StatusInternalServerError)
+			return
+		}
+		// The response is stale at once for header "Age".
+		r.Response.Header().Set("Cache-Control", "max-age=1, "+r.Get("directive").String())
+		r.Response.Header().Set("Age", "1")
+		r.Response.Write(counter.Add(1))
+	})
+	s.BindHandler("/no-store", func(r *ghttp.Request) {
+		r.Response.Header().Set("Cache-Control", "no-store")
+		r.Response.Write(counter.Add(1))
+	})
+	s.BindHandler("/broken", func(r *ghttp.Request) {
+		counter.Add(1)
+		// The connection is closed before the body is completely sent.
+		conn, _, err := r.Response.Hijack()
+		if err != nil {
+			return
+		}
+		_, _ = conn.Write([]byte(
+			"HTTP/1.1 200 OK\r\nCache-Control: max-age=60\r\nContent-Length: 100\r\n\r\npartial",
+		))
+		_ = conn.Close()
+	})
+	s.SetDumpRouterMap(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	newClient := func() *gclient.Client {
+		client := g.Client().Prefix(fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort()))
+		client.Use(gclient.MiddlewareCache())
+		return client
+	}
+	// Fresh responses and invalidation.
+	gtest.C(t, func(t *gtest.T) {
+		counter.Set(0)
+		client := newClient()
+		t.Assert(client.GetContent(ctx, "/fresh"), "1")
+		t.Assert(client.GetContent(ctx, "/fresh"), "1")
+		t.Assert(client.Header(g.MapStrStr{"Cache-Control": "no-cache"}).GetContent(ctx, "/fresh"), "2")
+		t.Assert(client.GetContent(ctx, "/fresh"), "2")
+
+		resp, err := client.Get(ctx, "/fresh")
+		t.AssertNil(err)
+		defer resp.Close()
+		t.AssertNE(resp.Header.Get("Age"), "")
+
+		t.Assert(client.PostContent(ctx, "/fresh"), "3")
+		t.Assert(client.GetContent(ctx, "/fresh"), "4")
+		t.Assert(client.GetContent(ctx, "/no-store"), "5")
+		t.Assert(client.GetContent(ctx, "/no-store"), "6")
+	})
+	// Revalidation with header "ETag".
+	gtest.C(t, func(t *gtest.T) {
+		counter.Set(0)
+		client := newClient()
+		t.Assert(client.GetContent(ctx, "/etag"), "etag")
+		resp, err := client.Get(ctx, "/etag")
+		t.AssertNil(err)
+		defer resp.Close()
+		t.Assert(resp.StatusCode, http.StatusOK)
+		t.Assert(resp.ReadAllString(), "etag")
+		t.Assert(counter.Val(), 2)
+	})
+	// Header "Vary".
+	gtest.C(t, func(t *gtest.T) {
+		counter.Set(0)
+		client := newClient()
+		t.Assert(client.Header(g.MapStrStr{"X-Lang": "en"}).GetContent(ctx, "/vary"), "en")
+		t.Assert(client.Header(g.MapStrStr{"X-Lang": "zh"}).GetContent(ctx, "/vary"), "zh")
+		t.Assert(client.Header(g.MapStrStr{"X-Lang": "en"}).GetContent(ctx, "/vary"), "en")
+		t.Assert(client.Header(g.MapStrStr{"X-Lang": "zh"}).GetContent(ctx, "/vary"), "zh")
+		t.Assert(counter.Val(), 2)
+	})
+	// Directive "stale-while-revalidate".
+	gtest.C(t, func(t *gtest.T) {
+		counter.Set(0)
+		client := newClient()
+		path := "/stale?directive=stale-while-revalidate=60"
+		t.Assert(client.GetContent(ctx, path), "1")
+		t.Assert(client.GetContent(ctx, path), "1")
+		time.Sleep(100 * time.Millisecond)
+		t.Assert(counter.Val(), 2)
+		t.Assert(client.GetContent(ctx, path), "2")
+		// Waits for the background revalidation triggered by the stale response.
+		time.Sleep(100 * time.Millisecond)
+	})
+	// Directive "stale-if-error".
+	gtest.C(t, func(t *gtest.T) {
+		counter.Set(0)
+		client := newClient()
+		path := "/stale?directive=stale-if-error=60"
+		t.Assert(client.GetContent(ctx, path), "1")
+		t.Assert(client.GetContent(ctx, path), "2")
+
+		failing.Set(true)
+		defer failing.Set(false)
+		t.Assert(client.GetContent(ctx, path), "2")
+	})
+	// The reading error of body is returned to caller and it is not cached.
+	gtest.C(t, func(t *gtest.T) {
+		counter.Set(0)
+		client := newClient()
+		for i := 1; i <= 2; i++ {
+			resp, err := client.Get(ctx, "/broken")
+			t.AssertNil(err)
+			body, err := io.ReadAll(resp.Body)
+			t.Assert(string(body), "partial")
+			t.Assert(errors.Is(err, io.ErrUnexpectedEOF), true)
+			resp.Close()
+			t.Assert(counter.Val(), i)
+		}
+	})
+}
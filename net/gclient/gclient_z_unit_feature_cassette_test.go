@@ -0,0 +1,98 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gclient_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/gclient"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/os/gfile"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/gogf/gf/v2/text/gstr"
+	"github.com/gogf/gf/v2/util/guid"
+)
+
+func Test_Client_Cassette(t *testing.T) {
+	s := g.Server(guid.S())
+	s.BindHandler("/echo", func(r *ghttp.Request) {
+		r.Response.Header().Set("Set-Cookie", "session=secret")
+		r.Response.Writef("%s:%s:%s", r.Get("n"), r.Header.Get("X-Tenant"), r.GetBodyString())
+	})
+	s.SetDumpRouterMap(false)
+	s.Start()
+	time.Sleep(100 * time.Millisecond)
+
+	var (
+		prefix  = fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort())
+		dir     = gfile.Temp(guid.S())
+		options = gclient.CassetteOptions{
+			MatchHeaders:   []string{"X-Tenant"},
+			RedactPatterns: []string{`"password":"(.*?)"`},
+		}
+	)
+	defer gfile.Remove(dir)
+	record := func(t *gtest.T, path string) {
+		client := g.Client().Prefix(prefix)
+		options.Path = path
+		options.Mode = gclient.CassetteModeRecord
+		t.AssertNil(client.SetCassette(options))
+		client.SetHeader("Authorization", "Bearer token")
+		t.Assert(client.GetContent(ctx, "/echo?n=1"), "1::")
+		t.Assert(client.Header(g.MapStrStr{"X-Tenant": "a"}).GetContent(ctx, "/echo?n=1"), "1:a:")
+		t.Assert(
+			client.PostContent(ctx, "/echo", `{"user":"john","password":"123456"}`),
+			`::{"user":"john","password":"123456"}`,
+		)
+	}
+	replay := func(t *gtest.T, path string) {
+		client := g.Client().Prefix(prefix)
+		options.Path = path
+		options.Mode = gclient.CassetteModeReplay
+		t.AssertNil(client.SetCassette(options))
+		t.Assert(client.GetContent(ctx, "/echo?n=1"), "1::")
+		t.Assert(client.Header(g.MapStrStr{"X-Tenant": "a"}).GetContent(ctx, "/echo?n=1"), "1:a:")
+		t.Assert(
+			client.PostContent(ctx, "/echo", `{"user":"john","password":"654321"}`),
+			`::{"user":"john","password":"[REDACTED]"}`,
+		)
+
+		_, err := client.Get(ctx, "/echo?n=2")
+		t.AssertNE(err, nil)
+		t.Assert(gerror.Code(err), gcode.CodeNotFound)
+		_, err = client.Header(g.MapStrStr{"X-Tenant": "b"}).Get(ctx, "/echo?n=1")
+		t.Assert(gerror.Code(err), gcode.CodeNotFound)
+	}
+	gtest.C(t, func(t *gtest.T) {
+		record(t, gfile.Join(dir, "cassette.yaml"))
+		record(t, gfile.Join(dir, "cassette.har"))
+
+		content := gfile.GetContents(gfile.Join(dir, "cassette.har"))
+		t.Assert(gstr.Contains(content, `"version": "1.2"`), true)
+		for _, path := range []string{"cassette.yaml", "cassette.har"} {
+			content = gfile.GetContents(gfile.Join(dir, path))
+			t.Assert(gstr.Contains(content, "Bearer token"), false)
+			t.Assert(gstr.Contains(content, "session=secret"), false)
+			t.Assert(gstr.Contains(content, "123456"), false)
+		}
+	})
+	// Replays without network.
+	gtest.C(t, func(t *gtest.T) {
+		s.Shutdown()
+		time.Sleep(100 * time.Millisecond)
+		replay(t, gfile.Join(dir, "cassette.yaml"))
+		replay(t, gfile.Join(dir, "cassette.har"))
+
+		err := g.Client().SetCassette(gclient.CassetteOptions{Path: gfile.Join(dir, "none.yaml")})
+		t.Assert(gerror.Code(err), gcode.CodeNotFound)
+	})
+}
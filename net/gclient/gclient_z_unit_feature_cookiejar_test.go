@@ -0,0 +1,179 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gclient_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/gclient"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/os/gcache"
+	"github.com/gogf/gf/v2/os/gfile"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/gogf/gf/v2/util/guid"
+)
+
+func Test_Client_CookieJar_Rules(t *testing.T) {
+	mustParse := func(rawURL string) *url.URL {
+		u, err := url.Parse(rawURL)
+		gtest.AssertNil(err)
+		return u
+	}
+	cookieNames := func(cookies []*http.Cookie) []string {
+		names := make([]string, len(cookies))
+		for i, cookie := range cookies {
+			names[i] = cookie.Name
+		}
+		return names
+	}
+	// Domain and public suffix.
+	gtest.C(t, func(t *gtest.T) {
+		jar, err := gclient.NewCookieJar()
+		t.AssertNil(err)
+		jar.SetCookies(mustParse("https://www.example.co.uk/"), []*http.Cookie{
+			{Name: "suffix", Value: "1", Domain: "co.uk"},
+			{Name: "other", Value: "1", Domain: "other.co.uk"},
+			{Name: "domain", Value: "1", Domain: ".example.co.uk"},
+			{Name: "host", Value: "1"},
+		})
+		t.Assert(cookieNames(jar.Cookies(mustParse("https://www.example.co.uk/"))), g.SliceStr{"domain", "host"})
+		t.Assert(cookieNames(jar.Cookies(mustParse("https://api.example.co.uk/"))), g.SliceStr{"domain"})
+		t.Assert(len(jar.Cookies(mustParse("https://example.com/"))), 0)
+		t.Assert(len(jar.Entries("example.co.uk")), 2)
+		t.Assert(len(jar.Entries("www.example.co.uk")), 1)
+
+		// The public suffix itself can set host-only cookies.
+		jar.SetCookies(mustParse("https://github.io/"), []*http.Cookie{{Name: "self", Value: "1", Domain: "github.io"}})
+		t.Assert(cookieNames(jar.Cookies(mustParse("https://github.io/"))), g.SliceStr{"self"})
+		t.Assert(len(jar.Cookies(mustParse("https://user.github.io/"))), 0)
+	})
+	// Path, secure and expiry.
+	gtest.C(t, func(t *gtest.T) {
+		jar, err := gclient.NewCookieJar()
+		t.AssertNil(err)
+		jar.SetCookies(mustParse("https://example.com/api/users"), []*http.Cookie{
+			{Name: "default", Value: "1"},
+			{Name: "root", Value: "1", Path: "/"},
+			{Name: "secure", Value: "1", Path: "/", Secure: true},
+			{Name: "expired", Value: "1", Path: "/", Expires: time.Now().Add(-time.Hour)},
+		})
+		t.Assert(cookieNames(jar.Cookies(mustParse("https://example.com/api/users"))), g.SliceStr{"default", "root", "secure"})
+		t.Assert(cookieNames(jar.Cookies(mustParse("http://example.com/api"))), g.SliceStr{"default", "root"})
+		t.Assert(cookieNames(jar.Cookies(mustParse("http://example.com/apis"))), g.SliceStr{"root"})
+
+		// The cookie is deleted by negative max age.
+		jar.SetCookies(mustParse("https://example.com/"), []*http.Cookie{{Name: "root", Path: "/", MaxAge: -1}})
+		t.Assert(cookieNames(jar.Cookies(mustParse("https://example.com/"))), g.SliceStr{"secure"})
+	})
+	// Edits cookies.
+	gtest.C(t, func(t *gtest.T) {
+		jar, err := gclient.NewCookieJar()
+		t.AssertNil(err)
+		t.AssertNE(jar.Set(gclient.CookieJarEntry{Name: "name"}), nil)
+		t.AssertNil(jar.Set(gclient.CookieJarEntry{Name: "token", Value: "v1", Domain: "Example.com"}))
+		t.AssertNil(jar.Set(gclient.CookieJarEntry{Name: "token", Value: "v2", Domain: "example.com"}))
+		cookies := jar.Cookies(mustParse("http://www.example.com/"))
+		t.Assert(len(cookies), 1)
+		t.Assert(cookies[0].Value, "v2")
+
+		entries := jar.Entries()
+		t.Assert(len(entries), 1)
+		t.Assert(entries[0].Path, "/")
+		t.Assert(jar.Delete("example.com", "/", "token"), true)
+		t.Assert(jar.Delete("example.com", "/", "token"), false)
+		t.Assert(len(jar.Entries()), 0)
+	})
+}
+
+func Test_Client_CookieJar_Persistence(t *testing.T) {
+	s := g.Server(guid.S())
+	s.BindHandler("/set", func(r *ghttp.Request) {
+		r.Response.Header().Add("Set-Cookie", "session=1; Path=/")
+		r.Response.Header().Add("Set-Cookie", "persistent=2; Path=/; Max-Age=3600")
+	})
+	s.BindHandler("/get", func(r *ghttp.Request) {
+		r.Response.Write(r.Header.Get("Cookie"))
+	})
+	s.SetDumpRouterMap(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	prefix := fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort())
+	// Persists to file.
+	gtest.C(t, func(t *gtest.T) {
+		file := gfile.Temp(guid.S(), "cookies.json")
+		defer gfile.Remove(gfile.Dir(file))
+
+		jar, err := gclient.NewCookieJar(gclient.CookieJarOptions{File: file})
+		t.AssertNil(err)
+		client := g.Client().CookieJar(jar)
+		t.Assert(client.GetContent(ctx, prefix+"/set"), "")
+		// The jar is shared by cloned clients.
+		t.Assert(client.Prefix(prefix).GetContent(ctx, "/get"), "session=1; persistent=2")
+		t.Assert(gfile.Exists(file), true)
+
+		// The session cookie is not persisted.
+		jar, err = gclient.NewCookieJar(gclient.CookieJarOptions{File: file})
+		t.AssertNil(err)
+		t.Assert(g.Client().CookieJar(jar).GetContent(ctx, prefix+"/get"), "persistent=2")
+		entries := jar.Entries()
+		t.Assert(len(entries), 1)
+		t.Assert(entries[0].Domain, "127.0.0.1")
+		t.Assert(entries[0].HostOnly, true)
+	})
+	// Persists to cache adapter in background.
+	gtest.C(t, func(t *gtest.T) {
+		adapter := gcache.NewAdapterMemory()
+		jar, err := gclient.NewCookieJar(gclient.CookieJarOptions{
+			Adapter:            adapter,
+			SaveInterval:       50 * time.Millisecond,
+			KeepSessionCookies: true,
+		})
+		t.AssertNil(err)
+		t.Assert(g.Client().CookieJar(jar).GetContent(ctx, prefix+"/set"), "")
+		time.Sleep(200 * time.Millisecond)
+
+		jar, err = gclient.NewCookieJar(gclient.CookieJarOptions{Adapter: adapter})
+		t.AssertNil(err)
+		t.Assert(g.Client().CookieJar(jar).GetContent(ctx, prefix+"/get"), "session=1; persistent=2")
+	})
+	// Concurrent saving.
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			file = gfile.Temp(guid.S(), "cookies.json")
+			wg   sync.WaitGroup
+		)
+		defer gfile.Remove(gfile.Dir(file))
+
+		jar, err := gclient.NewCookieJar(gclient.CookieJarOptions{File: file})
+		t.AssertNil(err)
+		u, err := url.Parse("http://example.com/")
+		t.AssertNil(err)
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				jar.SetCookies(u, []*http.Cookie{{Name: fmt.Sprintf("c%d", i), Value: "1", MaxAge: 3600}})
+			}(i)
+		}
+		wg.Wait()
+
+		files, err := gfile.ScanDirFile(gfile.Dir(file), "*")
+		t.AssertNil(err)
+		t.Assert(len(files), 1)
+		jar, err = gclient.NewCookieJar(gclient.CookieJarOptions{File: file})
+		t.AssertNil(err)
+		t.Assert(len(jar.Entries()), 50)
+	})
+}
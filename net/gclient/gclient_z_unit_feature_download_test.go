@@ -0,0 +1,135 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gclient_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/container/garray"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/gclient"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/os/gfile"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/gogf/gf/v2/text/gstr"
+	"github.com/gogf/gf/v2/util/grand"
+	"github.com/gogf/gf/v2/util/guid"
+)
+
+func Test_Client_Download(t *testing.T) {
+	var (
+		content  = []byte(grand.S(1024 * 1024))
+		sum      = sha256.Sum256(content)
+		checksum = "sha256:" + hex.EncodeToString(sum[:])
+		ranges   = garray.NewStrArray(true)
+		modTime  = time.Now()
+		s        = g.Server(guid.S())
+	)
+	s.BindHandler("/file", func(r *ghttp.Request) {
+		ranges.Append(r.Header.Get("Range"))
+		r.Response.ServeContent("file", modTime, bytes.NewReader(content))
+	})
+	s.BindHandler("/empty", func(r *ghttp.Request) {
+		// Some servers like nginx respond the range request of empty file with status 416.
+		if r.Header.Get("Range") != "" {
+			r.Response.Header().Set("Content-Range", "bytes */0")
+			r.Response.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+		}
+	})
+	s.BindHandler("/plain", func(r *ghttp.Request) {
+		r.Response.Write(content)
+	})
+	s.SetDumpRouterMap(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	var (
+		client = g.Client().Prefix(fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort()))
+		dir    = gfile.Temp(guid.S())
+	)
+	defer gfile.Remove(dir)
+	// Parallel segments with checksum and progress.
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			path            = gfile.Join(dir, "parallel", "file")
+			downloaded, all int64
+		)
+		err := client.Download(ctx, "/file", path, gclient.DownloadOptions{
+			Segments: 4,
+			Checksum: checksum,
+			Progress: func(d, total int64) {
+				downloaded, all = d, total
+			},
+		})
+		t.AssertNil(err)
+		t.Assert(gfile.GetBytes(path), content)
+		t.Assert(downloaded, len(content))
+		t.Assert(all, len(content))
+		files, err := gfile.ScanDirFile(gfile.Dir(path), "*.download*")
+		t.AssertNil(err)
+		t.Assert(len(files), 0)
+	})
+	// Empty file, whose range request is responded with status 416.
+	gtest.C(t, func(t *gtest.T) {
+		path := gfile.Join(dir, "empty", "file")
+		t.AssertNil(client.Download(ctx, "/empty", path, gclient.DownloadOptions{Segments: 4}))
+		t.Assert(gfile.Exists(path), true)
+		t.Assert(gfile.Size(path), 0)
+		files, err := gfile.ScanDirFile(gfile.Dir(path), "*.download*")
+		t.AssertNil(err)
+		t.Assert(len(files), 0)
+	})
+	// Resumes the partially downloaded file.
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			path        = gfile.Join(dir, "resume", "file")
+			ctx, cancel = context.WithCancel(ctx)
+		)
+		err := client.Download(ctx, "/file", path, gclient.DownloadOptions{
+			Progress: func(d, total int64) {
+				cancel()
+			},
+		})
+		t.AssertNE(err, nil)
+		t.Assert(gfile.Exists(path), false)
+
+		ranges.Clear()
+		t.AssertNil(client.Download(context.Background(), "/file", path, gclient.DownloadOptions{
+			Checksum: checksum,
+		}))
+		t.Assert(gfile.GetBytes(path), content)
+		t.Assert(ranges.Len(), 2)
+		t.Assert(ranges.At(0), "bytes=0-0")
+		t.Assert(gstr.HasPrefix(ranges.At(1), "bytes=0-"), false)
+	})
+	// Checksum mismatch and server without range support.
+	gtest.C(t, func(t *gtest.T) {
+		path := gfile.Join(dir, "plain", "file")
+		err := client.Download(ctx, "/plain", path, gclient.DownloadOptions{
+			Segments: 4,
+			Checksum: "md5:0123456789abcdef0123456789abcdef",
+		})
+		t.Assert(gerror.Code(err), gcode.CodeValidationFailed)
+		t.Assert(gfile.Exists(path), false)
+
+		t.AssertNil(client.Download(ctx, "/plain", path, gclient.DownloadOptions{
+			Segments: 4,
+			Checksum: checksum,
+		}))
+		t.Assert(gfile.GetBytes(path), content)
+	})
+}
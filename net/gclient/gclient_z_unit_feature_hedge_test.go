@@ -0,0 +1,160 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gclient_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/container/gtype"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/gclient"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/net/gsvc"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/gogf/gf/v2/util/guid"
+)
+
+func Test_Client_Hedge(t *testing.T) {
+	var (
+		counter = gtype.NewInt()
+		s       = g.Server(guid.S())
+	)
+	// The first request is slow.
+	s.BindHandler("/slow-first", func(r *ghttp.Request) {
+		if n := counter.Add(1); n == 1 {
+			time.Sleep(time.Second)
+		}
+		r.Response.Write(r.Method, ":", r.GetBodyString())
+	})
+	s.SetDumpRouterMap(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	client := g.Client().Prefix(fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort())).HedgePolicy(gclient.HedgePolicy{
+		Delay: 50 * time.Millisecond,
+	})
+	// The hedged request wins.
+	gtest.C(t, func(t *gtest.T) {
+		start := time.Now()
+		t.Assert(client.PutContent(ctx, "/slow-first", "data"), "PUT:data")
+		t.AssertLT(time.Since(start), 500*time.Millisecond)
+		t.Assert(counter.Val(), 2)
+	})
+	// The non-idempotent request is not hedged.
+	gtest.C(t, func(t *gtest.T) {
+		counter.Set(0)
+		start := time.Now()
+		t.Assert(client.PostContent(ctx, "/slow-first", "data"), "POST:data")
+		t.AssertGE(time.Since(start), time.Second)
+		t.Assert(counter.Val(), 1)
+	})
+	// The request with header "Idempotency-Key" is hedged.
+	gtest.C(t, func(t *gtest.T) {
+		counter.Set(0)
+		start := time.Now()
+		t.Assert(client.Header(g.MapStrStr{"Idempotency-Key": guid.S()}).PostContent(ctx, "/slow-first", "data"), "POST:data")
+		t.AssertLT(time.Since(start), 500*time.Millisecond)
+		t.Assert(counter.Val(), 2)
+	})
+}
+
+func Test_Client_Hedge_Discovery(t *testing.T) {
+	var (
+		fast = g.Server(guid.S())
+		slow = g.Server(guid.S())
+	)
+	fast.BindHandler("/call", func(r *ghttp.Request) {
+		r.Response.Write("fast")
+	})
+	slow.BindHandler("/call", func(r *ghttp.Request) {
+		time.Sleep(time.Second)
+		r.Response.Write("slow")
+	})
+	for _, s := range []*ghttp.Server{fast, slow} {
+		s.SetDumpRouterMap(false)
+		s.Start()
+		defer s.Shutdown()
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	// The hedged request is sent to another endpoint.
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			url    = fmt.Sprintf("http://%s/call", guid.S())
+			client = g.Client().Discovery(&breakerTestDiscovery{endpoints: gsvc.NewEndpoints(fmt.Sprintf(
+				"127.0.0.1:%d,127.0.0.1:%d", fast.GetListenedPort(), slow.GetListenedPort(),
+			))}).HedgePolicy(gclient.HedgePolicy{Delay: 50 * time.Millisecond})
+		)
+		for i := 0; i < 4; i++ {
+			start := time.Now()
+			t.Assert(client.GetContent(ctx, url), "fast")
+			t.AssertLT(time.Since(start), 500*time.Millisecond)
+		}
+	})
+}
+
+func Test_Client_HostLimit(t *testing.T) {
+	s := g.Server(guid.S())
+	s.BindHandler("/call", func(r *ghttp.Request) {
+		r.Response.Write("ok")
+	})
+	s.SetDumpRouterMap(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	prefix := fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort())
+	// The request times out waiting in queue.
+	gtest.C(t, func(t *gtest.T) {
+		client := g.Client().Prefix(prefix).HostLimit(gclient.HostLimitOptions{
+			MaxInFlight:  1,
+			QueueTimeout: 100 * time.Millisecond,
+		})
+		resp, err := client.Get(ctx, "/call")
+		t.AssertNil(err)
+
+		_, err = client.Get(ctx, "/call")
+		t.Assert(gerror.Is(err, gclient.ErrHostLimitExceeded), true)
+		t.Assert(gerror.Code(err), gcode.CodeServerBusy)
+
+		// The slot is released after the response is closed.
+		t.AssertNil(resp.Close())
+		t.Assert(client.GetContent(ctx, "/call"), "ok")
+	})
+	// The request is rejected as the queue is full.
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			client = g.Client().Prefix(prefix).HostLimit(gclient.HostLimitOptions{
+				MaxInFlight: 1,
+				MaxQueue:    1,
+			})
+			queued = make(chan string)
+		)
+		resp, err := client.Get(ctx, "/call")
+		t.AssertNil(err)
+
+		go func() {
+			queued <- client.GetContent(ctx, "/call")
+		}()
+		time.Sleep(100 * time.Millisecond)
+		_, err = client.Get(ctx, "/call")
+		t.Assert(gerror.Is(err, gclient.ErrHostLimitExceeded), true)
+
+		t.AssertNil(resp.Close())
+		select {
+		case content := <-queued:
+			t.Assert(content, "ok")
+		case <-time.After(time.Second):
+			t.Error("queued request is not sent after the slot is released")
+		}
+	})
+}
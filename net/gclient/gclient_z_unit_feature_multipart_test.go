@@ -0,0 +1,128 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gclient_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/container/gtype"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/gclient"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/os/gfile"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/gogf/gf/v2/util/guid"
+)
+
+func Test_Client_Multipart(t *testing.T) {
+	var (
+		counter = gtype.NewInt()
+		s       = g.Server(guid.S())
+	)
+	s.BindHandler("/upload", func(r *ghttp.Request) {
+		if counter.Add(1) == 1 && r.Get("fail").Bool() {
+			r.Response.WriteStatus(http.StatusServiceUnavailable)
+			return
+		}
+		r.Response.Write(r.Get("name"), ";", r.Header.Get("Content-Length"))
+		for _, name := range []string{"file", "reader", "stream"} {
+			file := r.GetUploadFile(name)
+			if file == nil {
+				continue
+			}
+			f, err := file.Open()
+			if err != nil {
+				r.Response.WriteStatus(http.StatusInternalServerError)
+				return
+			}
+			content, _ := io.ReadAll(f)
+			_ = f.Close()
+			r.Response.Writef(
+				";%s:%s:%s:%s", name, file.Filename, file.Header.Get("Content-Type"), content,
+			)
+		}
+	})
+	s.SetDumpRouterMap(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	var (
+		client = g.Client().Prefix(fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort()))
+		path   = gfile.Temp(guid.S(), "upload.txt")
+	)
+	gtest.AssertNil(gfile.PutContents(path, "file content"))
+	defer gfile.Remove(gfile.Dir(path))
+	// Fields, files and readers with content types and progress.
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			sent, total int64
+			multipart   = gclient.NewMultipart().
+					Field("name", "john").
+					File("file", path).
+					Reader("reader", "data.json", strings.NewReader(`{"id":1}`), "application/json").
+					Progress(func(s, t int64) {
+					sent, total = s, t
+				})
+		)
+		content := client.PostContent(ctx, "/upload", multipart)
+		t.Assert(content, fmt.Sprintf(
+			`john;%d;file:upload.txt:text/plain; charset=utf-8:file content;reader:data.json:application/json:{"id":1}`,
+			multipart.Size(),
+		))
+		t.Assert(sent, multipart.Size())
+		t.Assert(total, multipart.Size())
+	})
+	// Unknown size is sent in chunked encoding.
+	gtest.C(t, func(t *gtest.T) {
+		multipart := gclient.NewMultipart().
+			Field("name", "john").
+			Reader("stream", "data.bin", io.LimitReader(strings.NewReader("streaming"), 6))
+		t.Assert(multipart.Size(), -1)
+		t.Assert(
+			client.PostContent(ctx, "/upload", multipart),
+			"john;;stream:data.bin:application/octet-stream:stream",
+		)
+	})
+	// Re-opens the sources for retries.
+	gtest.C(t, func(t *gtest.T) {
+		retryClient := client.RetryPolicy(gclient.RetryPolicy{
+			MaxRetries:         1,
+			InitialInterval:    10 * time.Millisecond,
+			RetryNonIdempotent: true,
+		})
+		counter.Set(0)
+		multipart := gclient.NewMultipart().
+			Field("name", "john").
+			Reader("reader", "data.txt", bytes.NewReader([]byte("seekable"))).
+			ReaderFunc("stream", "data.bin", 6, func() (io.ReadCloser, error) {
+				return io.NopCloser(strings.NewReader("stream")), nil
+			})
+		t.Assert(
+			retryClient.PostContent(ctx, "/upload?fail=1", multipart),
+			fmt.Sprintf(
+				"john;%d;reader:data.txt:text/plain; charset=utf-8:seekable;stream:data.bin:application/octet-stream:stream",
+				multipart.Size(),
+			),
+		)
+		t.Assert(counter.Val(), 2)
+
+		// The reader that is not seekable cannot be sent again.
+		counter.Set(0)
+		multipart = gclient.NewMultipart().
+			Reader("stream", "data.bin", io.LimitReader(strings.NewReader("stream"), 6))
+		_, err := retryClient.Post(ctx, "/upload?fail=1", multipart)
+		t.AssertNE(err, nil)
+		t.Assert(counter.Val(), 1)
+	})
+}
@@ -0,0 +1,215 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gclient_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/container/gset"
+	"github.com/gogf/gf/v2/container/gtype"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/gclient"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/gogf/gf/v2/text/gstr"
+	"github.com/gogf/gf/v2/util/guid"
+)
+
+func Test_Client_OAuth2(t *testing.T) {
+	var (
+		counter       = gtype.NewInt()
+		revoked       = gset.NewStrSet(true)
+		refreshTokens = gset.NewStrSetFrom([]string{"refresh-1"}, true)
+		privateKey, _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		s             = g.Server(guid.S())
+	)
+	verifyAssertion := func(assertion string) bool {
+		array := gstr.Split(assertion, ".")
+		if len(array) != 3 {
+			return false
+		}
+		signature, err := base64.RawURLEncoding.DecodeString(array[2])
+		if err != nil || len(signature) != 64 {
+			return false
+		}
+		digest := sha256.Sum256([]byte(array[0] + "." + array[1]))
+		return ecdsa.Verify(
+			&privateKey.PublicKey, digest[:],
+			new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:]),
+		)
+	}
+	s.BindHandler("/token", func(r *ghttp.Request) {
+		var refreshToken string
+		switch r.Get("grant_type").String() {
+		case "client_credentials":
+			user, pass, _ := r.Request.BasicAuth()
+			if user != "client" || pass != "secret" {
+				r.Response.Status = http.StatusUnauthorized
+				r.Response.WriteJson(g.Map{"error": "invalid_client"})
+				return
+			}
+		case "refresh_token":
+			if !refreshTokens.Contains(r.Get("refresh_token").String()) || r.Get("client_id").String() != "client" {
+				r.Response.Status = http.StatusBadRequest
+				r.Response.WriteJson(g.Map{"error": "invalid_grant"})
+				return
+			}
+			refreshTokens.Clear()
+			refreshToken = guid.S()
+			refreshTokens.Add(refreshToken)
+		case "urn:ietf:params:oauth:grant-type:jwt-bearer":
+			if !verifyAssertion(r.Get("assertion").String()) {
+				r.Response.Status = http.StatusBadRequest
+				r.Response.WriteJson(g.Map{"error": "invalid_grant"})
+				return
+			}
+		}
+		// The refreshing is slow.
+		if counter.Val() > 0 {
+			time.Sleep(r.Get("delay", 0).Duration())
+		}
+		r.Response.WriteJson(g.Map{
+			"access_token":  fmt.Sprintf("token-%d:%s", counter.Add(1), r.Get("scope")),
+			"token_type":    "bearer",
+			"expires_in":    r.Get("expires_in", 3600).Int(),
+			"refresh_token": refreshToken,
+		})
+	})
+	s.BindHandler("/api", func(r *ghttp.Request) {
+		token := gstr.TrimLeftStr(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" || revoked.Contains(token) {
+			r.Response.WriteStatus(http.StatusUnauthorized)
+			return
+		}
+		r.Response.Write(token, ";", r.GetBodyString())
+	})
+	s.SetDumpRouterMap(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	var (
+		prefix    = fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort())
+		newClient = func(t *gtest.T, config gclient.OAuth2Config) *gclient.Client {
+			source, err := gclient.NewOAuth2TokenSource(config)
+			t.AssertNil(err)
+			return g.Client().Prefix(prefix).Use(gclient.MiddlewareOAuth2(source))
+		}
+	)
+	// Client credentials from configuration map and retrying on 401.
+	gtest.C(t, func(t *gtest.T) {
+		counter.Set(0)
+		revoked.Clear()
+		config, err := gclient.OAuth2ConfigFromMap(g.Map{
+			"tokenURL":      prefix + "/token",
+			"clientId":      "client",
+			"clientSecret":  "secret",
+			"scopes":        g.Slice{"read", "write"},
+			"refreshBefore": "30s",
+		})
+		t.AssertNil(err)
+		t.Assert(config.RefreshBefore, 30*time.Second)
+		client := newClient(t, config)
+		t.Assert(client.PostContent(ctx, "/api", "a"), "token-1:read write;a")
+		t.Assert(client.PostContent(ctx, "/api", "b"), "token-1:read write;b")
+
+		revoked.Add("token-1:read write")
+		t.Assert(client.PostContent(ctx, "/api", "c"), "token-2:read write;c")
+		t.Assert(counter.Val(), 2)
+
+		config.ClientSecret = "invalid"
+		_, err = newClient(t, config).Post(ctx, "/api")
+		t.Assert(gerror.Code(err), gcode.CodeNotAuthorized)
+		t.Assert(gstr.Contains(err.Error(), "invalid_client"), true)
+	})
+	// Proactive refreshing before expiry.
+	gtest.C(t, func(t *gtest.T) {
+		counter.Set(0)
+		revoked.Clear()
+		client := newClient(t, gclient.OAuth2Config{
+			TokenURL:     prefix + "/token",
+			ClientId:     "client",
+			ClientSecret: "secret",
+			Params:       map[string]string{"expires_in": "2"},
+		})
+		t.Assert(client.GetContent(ctx, "/api"), "token-1:;")
+		time.Sleep(1200 * time.Millisecond)
+		t.Assert(client.GetContent(ctx, "/api"), "token-1:;")
+		time.Sleep(200 * time.Millisecond)
+		t.Assert(client.GetContent(ctx, "/api"), "token-2:;")
+		t.Assert(counter.Val(), 2)
+	})
+	// The cached token is used while refreshing in background.
+	gtest.C(t, func(t *gtest.T) {
+		counter.Set(0)
+		revoked.Clear()
+		client := newClient(t, gclient.OAuth2Config{
+			TokenURL:     prefix + "/token",
+			ClientId:     "client",
+			ClientSecret: "secret",
+			Params:       map[string]string{"expires_in": "2", "delay": "1s"},
+		})
+		t.Assert(client.GetContent(ctx, "/api"), "token-1:;")
+		time.Sleep(1100 * time.Millisecond)
+		t.Assert(client.GetContent(ctx, "/api"), "token-1:;")
+		start := time.Now()
+		t.Assert(client.GetContent(ctx, "/api"), "token-1:;")
+		t.AssertLT(time.Since(start), 200*time.Millisecond)
+		time.Sleep(1200 * time.Millisecond)
+		t.Assert(client.GetContent(ctx, "/api"), "token-2:;")
+		t.Assert(counter.Val(), 2)
+	})
+	// Refresh token grant with rotation.
+	gtest.C(t, func(t *gtest.T) {
+		counter.Set(0)
+		revoked.Clear()
+		client := newClient(t, gclient.OAuth2Config{
+			GrantType:    gclient.OAuth2GrantRefreshToken,
+			TokenURL:     prefix + "/token",
+			ClientId:     "client",
+			AuthStyle:    gclient.OAuth2AuthStyleParams,
+			RefreshToken: "refresh-1",
+		})
+		t.Assert(client.GetContent(ctx, "/api"), "token-1:;")
+		revoked.Add("token-1:")
+		t.Assert(client.GetContent(ctx, "/api"), "token-2:;")
+		t.Assert(refreshTokens.Contains("refresh-1"), false)
+	})
+	// JWT bearer grant with signed assertion.
+	gtest.C(t, func(t *gtest.T) {
+		counter.Set(0)
+		revoked.Clear()
+		keyBytes, err := x509.MarshalPKCS8PrivateKey(privateKey)
+		t.AssertNil(err)
+		client := newClient(t, gclient.OAuth2Config{
+			GrantType:  gclient.OAuth2GrantJWTBearer,
+			TokenURL:   prefix + "/token",
+			ClientId:   "service",
+			PrivateKey: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyBytes})),
+		})
+		t.Assert(client.GetContent(ctx, "/api"), "token-1:;")
+
+		_, err = gclient.NewOAuth2TokenSource(gclient.OAuth2Config{
+			GrantType: gclient.OAuth2GrantJWTBearer,
+			TokenURL:  prefix + "/token",
+		})
+		t.Assert(gerror.Code(err), gcode.CodeMissingConfiguration)
+	})
+}
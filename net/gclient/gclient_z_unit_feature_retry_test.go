@@ -0,0 +1,186 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gclient_test
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/container/gtype"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/gclient"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/gogf/gf/v2/util/guid"
+)
+
+func Test_Client_RetryPolicy(t *testing.T) {
+	var (
+		counter = gtype.NewInt()
+		s       = g.Server(guid.S())
+	)
+	s.BindHandler("/flaky", func(r *ghttp.Request) {
+		if counter.Add(1) <= r.Get("failures").Int() {
+			r.Response.WriteStatus(http.StatusServiceUnavailable)
+			return
+		}
+		r.Response.Write("ok:", r.GetBodyString())
+	})
+	s.BindHandler("/retry-after", func(r *ghttp.Request) {
+		if counter.Add(1) == 1 {
+			r.Response.Header().Set("Retry-After", r.Get("seconds").String())
+			r.Response.WriteStatus(http.StatusTooManyRequests)
+			return
+		}
+		r.Response.Write("ok")
+	})
+	s.SetDumpRouterMap(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	client := g.Client().Prefix(fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort())).RetryPolicy(gclient.RetryPolicy{
+		MaxRetries:      3,
+		InitialInterval: 10 * time.Millisecond,
+	})
+	// Retries on status codes.
+	gtest.C(t, func(t *gtest.T) {
+		counter.Set(0)
+		t.Assert(client.GetContent(ctx, "/flaky?failures=2"), "ok:")
+		t.Assert(counter.Val(), 3)
+
+		counter.Set(0)
+		resp, err := client.Get(ctx, "/flaky?failures=10")
+		t.AssertNil(err)
+		defer resp.Close()
+		t.Assert(resp.StatusCode, http.StatusServiceUnavailable)
+		t.Assert(counter.Val(), 4)
+	})
+	// Method idempotency and replayable request body.
+	gtest.C(t, func(t *gtest.T) {
+		counter.Set(0)
+		resp, err := client.Post(ctx, "/flaky?failures=2", "data")
+		t.AssertNil(err)
+		defer resp.Close()
+		t.Assert(resp.StatusCode, http.StatusServiceUnavailable)
+		t.Assert(counter.Val(), 1)
+
+		counter.Set(0)
+		t.Assert(client.Header(g.MapStrStr{"Idempotency-Key": guid.S()}).PostContent(ctx, "/flaky?failures=2", "data"), "ok:data")
+		t.Assert(counter.Val(), 3)
+
+		counter.Set(0)
+		nonIdempotentClient := client.RetryPolicy(gclient.RetryPolicy{
+			MaxRetries:         1,
+			InitialInterval:    10 * time.Millisecond,
+			RetryNonIdempotent: true,
+		})
+		t.Assert(nonIdempotentClient.PostContent(ctx, "/flaky?failures=1", "data"), "ok:data")
+		t.Assert(counter.Val(), 2)
+	})
+	// Header "Retry-After".
+	gtest.C(t, func(t *gtest.T) {
+		counter.Set(0)
+		start := time.Now()
+		t.Assert(client.GetContent(ctx, "/retry-after?seconds=1"), "ok")
+		t.Assert(time.Since(start) >= 900*time.Millisecond, true)
+
+		counter.Set(0)
+		resp, err := client.RetryPolicy(gclient.RetryPolicy{
+			MaxRetries:  3,
+			MaxInterval: 500 * time.Millisecond,
+		}).Get(ctx, "/retry-after?seconds=1")
+		t.AssertNil(err)
+		defer resp.Close()
+		t.Assert(resp.StatusCode, http.StatusTooManyRequests)
+		t.Assert(counter.Val(), 1)
+	})
+}
+
+func Test_Client_RetryPolicy_NetworkError(t *testing.T) {
+	var (
+		counter = gtype.NewInt()
+		s       = g.Server(guid.S())
+	)
+	// The connection is closed before any response bytes are written, which causes connection errors to client.
+	s.BindHandler("/close", func(r *ghttp.Request) {
+		counter.Add(1)
+		conn, _, err := r.Response.Writer.Hijack()
+		if err == nil {
+			_ = conn.Close()
+		}
+	})
+	s.SetDumpRouterMap(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	url := fmt.Sprintf("http://127.0.0.1:%d/close", s.GetListenedPort())
+	gtest.C(t, func(t *gtest.T) {
+		_, err := g.Client().RetryPolicy(gclient.RetryPolicy{
+			MaxRetries:      2,
+			InitialInterval: 10 * time.Millisecond,
+		}).Get(ctx, url)
+		t.AssertNE(err, nil)
+		t.Assert(counter.Val(), 3)
+
+		counter.Set(0)
+		_, err = g.Client().RetryPolicy(gclient.RetryPolicy{
+			MaxRetries:      2,
+			InitialInterval: 10 * time.Millisecond,
+			ErrorClasses:    gclient.RetryErrorTimeout,
+		}).Get(ctx, url)
+		t.AssertNE(err, nil)
+		t.Assert(counter.Val(), 1)
+	})
+	// Custom retry checking.
+	gtest.C(t, func(t *gtest.T) {
+		counter.Set(0)
+		checked := gtype.NewInt()
+		_, err := g.Client().RetryPolicy(gclient.RetryPolicy{
+			MaxRetries:      2,
+			InitialInterval: 10 * time.Millisecond,
+			ShouldRetry: func(resp *http.Response, err error) bool {
+				return checked.Add(1) == 1
+			},
+		}).Get(ctx, url)
+		t.AssertNE(err, nil)
+		t.Assert(counter.Val(), 2)
+		t.Assert(checked.Val(), 2)
+	})
+	// Only the operation errors of dialing are connection errors, as the request might be sent in others.
+	gtest.C(t, func(t *gtest.T) {
+		for op, count := range map[string]int{"dial": 3, "write": 1, "read": 1} {
+			var (
+				attempts = gtype.NewInt()
+				client   = g.Client().RetryPolicy(gclient.RetryPolicy{
+					MaxRetries:      2,
+					InitialInterval: 10 * time.Millisecond,
+				})
+			)
+			client.Transport = testRoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				attempts.Add(1)
+				return nil, &net.OpError{Op: op, Net: "tcp", Err: errors.New("operation failed")}
+			})
+			_, err := client.Get(ctx, url)
+			t.AssertNE(err, nil)
+			t.Assert(attempts.Val(), count)
+		}
+	})
+}
+
+// testRoundTripperFunc implements interface http.RoundTripper with function.
+type testRoundTripperFunc func(req *http.Request) (*http.Response, error)
+
+// RoundTrip implements interface http.RoundTripper.
+func (f testRoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -15,6 +15,7 @@ import (
 
 	"github.com/gorilla/websocket"
 
+	"github.com/gogf/gf/v2/container/garray"
 	"github.com/gogf/gf/v2/container/gmap"
 	"github.com/gogf/gf/v2/container/gtype"
 	"github.com/gogf/gf/v2/errors/gcode"
@@ -32,21 +33,34 @@ import (
 type (
 	// Server wraps the http.Server and provides more rich features.
 	Server struct {
-		instance         string                    // Instance name of current HTTP server.
-		config           ServerConfig              // Server configuration.
-		plugins          []Plugin                  // Plugin array to extend server functionality.
-		servers          []*graceful.Server        // Underlying http.Server array.
-		serverCount      *gtype.Int                // Underlying http.Server number for internal usage.
-		closeChan        chan struct{}             // Used for underlying server closing event notification.
-		serveTree        map[string]interface{}    // The route maps tree.
-		serveCache       *gcache.Cache             // Server caches for internal usage.
-		routesMap        map[string][]*HandlerItem // Route map mainly for route dumps and repeated route checks.
-		statusHandlerMap map[string][]HandlerFunc  // Custom status handler map.
-		sessionManager   *gsession.Manager         // Session manager.
-		openapi          *goai.OpenApiV3           // The OpenApi specification management object.
-		serviceMu        sync.Mutex                // Concurrent safety for operations of attribute service.
-		service          gsvc.Service              // The service for Registry.
-		registrar        gsvc.Registrar            // Registrar for service register.
+		instance         string                     // Instance name of current HTTP server.
+		config           ServerConfig               // Server configuration.
+		plugins          []Plugin                   // Plugin array to extend server functionality.
+		servers          []*graceful.Server         // Underlying http.Server array.
+		serverCount      *gtype.Int                 // Underlying http.Server number for internal usage.
+		closeChan        chan struct{}              // Used for underlying server closing event notification.
+		serveTree        map[string]interface{}     // The route maps tree.
+		serveCache       *gcache.Cache              // Server caches for internal usage.
+		routesMap        map[string][]*HandlerItem  // Route map mainly for route dumps and repeated route checks.
+		statusHandlerMap map[string][]HandlerFunc   // Custom status handler map.
+		sessionManager   *gsession.Manager          // Session manager.
+		openapi          *goai.OpenApiV3            // The OpenApi specification management object.
+		serviceMu        sync.Mutex                 // Concurrent safety for operations of attribute service.
+		service          gsvc.Service               // The service for Registry.
+		registrar        gsvc.Registrar             // Registrar for service register.
+		healthMu         sync.RWMutex               // Concurrent safety for operations of attribute healthCheckers.
+		healthCheckers   []*healthCheckItem         // Registered health checkers.
+		healthEnabled    *gtype.Bool                // Whether the health endpoints are enabled.
+		shuttingDown     *gtype.Bool                // Whether the server is shutting down, which fails readiness probe.
+		prepareMu        sync.Mutex                 // Concurrent safety for preparing the server.
+		prepared         *gtype.Bool                // Whether the server is prepared for serving, by Start or TestClient.
+		internalBound    bool                       // Whether the internal routes like swagger and pprof are bound.
+		pluginsInstalled int                        // Number of installed plugins.
+		testMu           sync.Mutex                 // Protects the in-memory serving of TestDialContext.
+		testListener     *testListener              // In-memory listener for TestDialContext.
+		testServer       *http.Server               // In-memory server for TestDialContext.
+		apiVersions      *garray.SortedStrArray     // Registered API versions in ascending order.
+		openapiVersions  map[string]*goai.OpenApiV3 // The OpenApi specifications of API versions.
 	}
 
 	// Router object.
@@ -101,6 +115,23 @@ type (
 		HookName   HookName        // Hook type name, only available for the hook type.
 		Router     *Router         // Router object.
 		Source     string          // Registering source file `path:line`.
+		Version    string          // API version, which is from router group or meta tag "version" of request struct.
+
+		// versionDispatch marks the handler registered without the version prefix,
+		// which serves only the requests that are resolved to its version.
+		versionDispatch bool
+
+		// timeout is the handling timeout from meta tag "timeout" of request struct,
+		// which is used by MiddlewareTimeout.
+		timeout time.Duration
+
+		// bulkhead is the options from meta tag "bulkhead" of request struct,
+		// which is used by MiddlewareBulkhead.
+		bulkhead *BulkheadOptions
+
+		// circuitBreaker is the options from meta tag "circuitBreaker" of request struct,
+		// which is used by MiddlewareCircuitBreaker.
+		circuitBreaker *CircuitBreakerOptions
 	}
 
 	// HandlerItemParsed is the item parsed from URL.Path.
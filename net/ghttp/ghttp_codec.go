@@ -0,0 +1,237 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package ghttp
+
+import (
+	"mime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/encoding/gxml"
+	"github.com/gogf/gf/v2/internal/intlog"
+	"github.com/gogf/gf/v2/internal/json"
+	"github.com/gogf/gf/v2/util/gconv"
+)
+
+// Codec is the interface for encoding handler response and decoding request body
+// of specified media type.
+//
+// The Codec of JSON, XML and YAML are registered in default. The Codec of other formats should be
+// registered using RegisterCodec, like MessagePack by importing package
+// `github.com/gogf/gf/contrib/codec/msgpack/v2`, or protobuf that depends on the generated messages.
+//
+// The parameter `v` of Encode is commonly the DefaultHandlerResponse object if MiddlewareHandlerResponse
+// is used, a Codec that can only encode certain types, like protobuf, can encode its field `Data` instead.
+type Codec interface {
+	// Encode encodes `v` to bytes.
+	Encode(v interface{}) ([]byte, error)
+	// Decode decodes `data` to pointer `v`.
+	Decode(data []byte, v interface{}) error
+}
+
+// codecJson is the Codec for JSON format.
+type codecJson struct{}
+
+// codecXml is the Codec for XML format.
+type codecXml struct{}
+
+// codecYaml is the Codec for YAML format.
+type codecYaml struct{}
+
+const (
+	contentTypeApplicationXml = "application/xml"
+	contentTypeYaml           = "application/yaml"
+	contentTypeXYaml          = "application/x-yaml"
+	contentTypeTextYaml       = "text/yaml"
+	defaultXmlRootTag         = "xml"
+)
+
+var (
+	// codecMu is the concurrent safety lock for codecMap.
+	codecMu sync.RWMutex
+
+	// codecMap stores the registered Codec, the key is the media type.
+	codecMap = map[string]Codec{
+		contentTypeJson:           codecJson{},
+		contentTypeApplicationXml: codecXml{},
+		contentTypeXml:            codecXml{},
+		contentTypeYaml:           codecYaml{},
+		contentTypeXYaml:          codecYaml{},
+		contentTypeTextYaml:       codecYaml{},
+	}
+)
+
+// RegisterCodec registers custom Codec for media type `mediaType`, like `application/x-protobuf`
+// whose Codec depends on the generated messages. It overwrites the existing Codec if `mediaType`
+// is already registered.
+func RegisterCodec(mediaType string, codec Codec) {
+	codecMu.Lock()
+	defer codecMu.Unlock()
+	codecMap[strings.ToLower(mediaType)] = codec
+}
+
+// GetCodec retrieves and returns the Codec registered for media type `mediaType`.
+// The parameter `mediaType` can be a Content-Type header value with parameters.
+// It returns nil if there's no Codec registered for `mediaType`.
+func GetCodec(mediaType string) Codec {
+	if parsed, _, err := mime.ParseMediaType(mediaType); err == nil {
+		mediaType = parsed
+	}
+	codecMu.RLock()
+	defer codecMu.RUnlock()
+	return codecMap[strings.ToLower(mediaType)]
+}
+
+// SupportedMediaTypes returns all media types that have registered Codec, in sorted order
+// with the default media type `application/json` in the first.
+func SupportedMediaTypes() []string {
+	codecMu.RLock()
+	defer codecMu.RUnlock()
+	mediaTypes := make([]string, 0, len(codecMap))
+	for mediaType := range codecMap {
+		if mediaType != contentTypeJson {
+			mediaTypes = append(mediaTypes, mediaType)
+		}
+	}
+	sort.Strings(mediaTypes)
+	return append([]string{contentTypeJson}, mediaTypes...)
+}
+
+// NegotiateMediaType negotiates and returns the media type for response according to request
+// header `Accept`.
+//
+// It only considers the media types with the highest quality value, and it falls back to default
+// `application/json` if none of them has registered Codec. This avoids browsers receiving XML
+// content as they commonly accept `application/xml` with lower quality value.
+func (r *Request) NegotiateMediaType() string {
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return contentTypeJson
+	}
+	type acceptItem struct {
+		mediaType string
+		quality   float64
+	}
+	var items = make([]acceptItem, 0)
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		item := acceptItem{mediaType: mediaType, quality: 1}
+		if q, ok := params["q"]; ok {
+			item.quality = gconv.Float64(q)
+		}
+		if item.quality > 0 {
+			items = append(items, item)
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].quality > items[j].quality
+	})
+	for _, item := range items {
+		if item.quality < items[0].quality {
+			break
+		}
+		switch {
+		case item.mediaType == "*/*":
+			return contentTypeJson
+		case strings.HasSuffix(item.mediaType, "/*"):
+			prefix := strings.TrimSuffix(item.mediaType, "*")
+			for _, mediaType := range SupportedMediaTypes() {
+				if strings.HasPrefix(mediaType, prefix) {
+					return mediaType
+				}
+			}
+		default:
+			if GetCodec(item.mediaType) != nil {
+				return item.mediaType
+			}
+		}
+	}
+	return contentTypeJson
+}
+
+// WriteNegotiated writes `content` to the response using the Codec of media type negotiated
+// by request header `Accept`. It uses JSON format in default, and it falls back to JSON format
+// if the Codec fails encoding `content`.
+func (r *Response) WriteNegotiated(content interface{}) {
+	var (
+		mediaType = r.Request.NegotiateMediaType()
+		codec     = GetCodec(mediaType)
+	)
+	if mediaType == contentTypeJson || codec == nil {
+		r.WriteJson(content)
+		return
+	}
+	b, err := codec.Encode(content)
+	if err != nil {
+		intlog.Errorf(r.Request.Context(), `encode response in "%s" failed, fall back to JSON: %+v`, mediaType, err)
+		r.WriteJson(content)
+		return
+	}
+	r.Header().Set("Content-Type", mediaType)
+	r.Write(b)
+}
+
+// Encode implements interface Codec.
+func (codecJson) Encode(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Decode implements interface Codec.
+func (codecJson) Decode(data []byte, v interface{}) error {
+	return json.UnmarshalUseNumber(data, v)
+}
+
+// Encode implements interface Codec.
+func (codecXml) Encode(v interface{}) ([]byte, error) {
+	j, err := loadJsonCompatible(v)
+	if err != nil {
+		return nil, err
+	}
+	return j.ToXml(defaultXmlRootTag)
+}
+
+// Decode implements interface Codec.
+func (codecXml) Decode(data []byte, v interface{}) error {
+	m, err := gxml.DecodeWithoutRoot(data)
+	if err != nil {
+		return err
+	}
+	return gconv.Scan(m, v)
+}
+
+// Encode implements interface Codec.
+func (codecYaml) Encode(v interface{}) ([]byte, error) {
+	j, err := loadJsonCompatible(v)
+	if err != nil {
+		return nil, err
+	}
+	return j.ToYaml()
+}
+
+// Decode implements interface Codec.
+func (codecYaml) Decode(data []byte, v interface{}) error {
+	j, err := gjson.LoadContentType(gjson.ContentTypeYaml, data)
+	if err != nil {
+		return err
+	}
+	return j.Scan(v)
+}
+
+// loadJsonCompatible loads `v` as Json object using JSON marshaling, so that the encoded content
+// of other formats keeps the same field names as JSON format, which respects the `json` tags.
+func loadJsonCompatible(v interface{}) (*gjson.Json, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return gjson.LoadContentType(gjson.ContentTypeJson, b)
+}
@@ -26,6 +26,16 @@ func BuildParams(params interface{}, noUrlEncode ...bool) (encodedParamStr strin
 	return httputil.BuildParams(params, noUrlEncode...)
 }
 
+// RegisterRouteConstraint registers named constraint `name` for route field with regular expression
+// `pattern`, which can be used in route like `/user/{id:name}`. It overwrites the build-in constraints
+// `int`, `uint`, `float`, `alpha`, `alnum`, `slug` and `uuid` if `name` is one of them.
+//
+// Note that it should be called before the routes are registered, and the `pattern` should not
+// contain char '/' as the route is matched by segments.
+func RegisterRouteConstraint(name, pattern string) {
+	httputil.SetRouteConstraint(name, pattern)
+}
+
 // niceCallFunc calls function `f` with exception capture logic.
 func niceCallFunc(f func()) {
 	defer func() {
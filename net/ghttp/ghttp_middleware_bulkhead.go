@@ -0,0 +1,162 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package ghttp
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gogf/gf/v2/container/gtype"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/os/gmetric"
+)
+
+// BulkheadOptions is the options for MiddlewareBulkhead.
+type BulkheadOptions struct {
+	// MaxConcurrent specifies the maximum number of requests handled concurrently.
+	// The bulkhead is disabled if it is not positive.
+	MaxConcurrent int `json:"maxConcurrent"`
+
+	// MaxQueue specifies the maximum number of requests waiting for handling when
+	// the concurrent requests reach MaxConcurrent. The requests exceeding it are rejected at once.
+	// It's 0 in default, which means no waiting.
+	MaxQueue int `json:"maxQueue"`
+
+	// QueueTimeout specifies the maximum waiting duration of requests in the queue.
+	// It's 0 in default, which means waiting until the request context is done.
+	QueueTimeout time.Duration `json:"queueTimeout"`
+}
+
+var (
+	// ErrBulkheadFull is the error that indicates the request is rejected as the bulkhead is full.
+	ErrBulkheadFull = gerror.NewWithOption(gerror.Option{
+		Text: "too many concurrent requests",
+		Code: gcode.CodeServerBusy,
+	})
+)
+
+// bulkhead limits the concurrent requests with a waiting queue.
+type bulkhead struct {
+	options BulkheadOptions
+	tokens  chan struct{} // Tokens for concurrent requests.
+	queued  *gtype.Int64  // Number of requests waiting in the queue.
+}
+
+// MiddlewareBulkhead creates and returns a middleware that limits the number of concurrent requests,
+// which isolates the handlers from each other and prevents requests from piling up when they degrade.
+//
+// The options are from, in priority order: meta tag "bulkhead" of the request struct like
+// `bulkhead:"maxConcurrent=10,maxQueue=100,queueTimeout=1s"`, parameter `options` and the server
+// configuration Bulkhead.
+//
+// All the routes that the middleware is bound to share the same bulkhead, for example, the routes of
+// a router group, except the routes having meta tag "bulkhead", each of which has its own bulkhead.
+//
+// The requests exceeding the limits are rejected with HTTP status 503 and ErrBulkheadFull,
+// which is written by MiddlewareHandlerResponse if it is used before this middleware.
+func MiddlewareBulkhead(options ...BulkheadOptions) HandlerFunc {
+	var (
+		defaultOptions *BulkheadOptions
+		bulkheads      sync.Map // Route key -> *bulkhead
+	)
+	if len(options) > 0 {
+		defaultOptions = &options[0]
+	}
+	return func(r *Request) {
+		var (
+			key             string
+			bulkheadOptions = defaultOptions
+		)
+		if bulkheadOptions == nil {
+			bulkheadOptions = &r.Server.config.Bulkhead
+		}
+		if r.serveHandler != nil && r.serveHandler.Handler.bulkhead != nil {
+			key = getHandlerRouteKey(r.serveHandler.Handler)
+			bulkheadOptions = r.serveHandler.Handler.bulkhead
+		}
+		if bulkheadOptions.MaxConcurrent <= 0 {
+			r.Middleware.Next()
+			return
+		}
+		v, ok := bulkheads.Load(key)
+		if !ok {
+			v, _ = bulkheads.LoadOrStore(key, newBulkhead(*bulkheadOptions))
+		}
+		b := v.(*bulkhead)
+		if !b.acquire(r) {
+			r.Server.handleMetricsBulkheadRejected(r)
+			r.Response.ClearBuffer()
+			r.Response.WriteHeader(http.StatusServiceUnavailable)
+			r.SetError(ErrBulkheadFull)
+			return
+		}
+		defer b.release()
+		r.Middleware.Next()
+	}
+}
+
+func newBulkhead(options BulkheadOptions) *bulkhead {
+	return &bulkhead{
+		options: options,
+		tokens:  make(chan struct{}, options.MaxConcurrent),
+		queued:  gtype.NewInt64(),
+	}
+}
+
+// acquire acquires a token for request `r`, which waits in the queue if necessary.
+// It returns false if the request is rejected.
+func (b *bulkhead) acquire(r *Request) bool {
+	select {
+	case b.tokens <- struct{}{}:
+		return true
+	default:
+	}
+	if b.queued.Add(1) > int64(b.options.MaxQueue) {
+		b.queued.Add(-1)
+		return false
+	}
+	defer b.queued.Add(-1)
+	var timeout <-chan time.Time
+	if b.options.QueueTimeout > 0 {
+		timer := time.NewTimer(b.options.QueueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case b.tokens <- struct{}{}:
+		return true
+	case <-timeout:
+		return false
+	case <-r.Context().Done():
+		return false
+	}
+}
+
+// release releases the token acquired.
+func (b *bulkhead) release() {
+	<-b.tokens
+}
+
+// getHandlerRouteKey returns the unique key of the route of `handler`.
+func getHandlerRouteKey(handler *HandlerItem) string {
+	if handler.Router == nil {
+		return handler.Name
+	}
+	return handler.Router.Method + ":" + handler.Router.Uri + "@" + handler.Router.Domain
+}
+
+func (s *Server) handleMetricsBulkheadRejected(r *Request) {
+	if !gmetric.IsEnabled() {
+		return
+	}
+	metricManager.HttpServerBulkheadRejected.Inc(
+		r.Context(),
+		metricManager.GetMetricOptionForRequest(r),
+	)
+}
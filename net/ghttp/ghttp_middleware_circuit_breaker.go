@@ -0,0 +1,217 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package ghttp
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/internal/breaker"
+	"github.com/gogf/gf/v2/os/gmetric"
+)
+
+// CircuitBreakerOptions is the options for MiddlewareCircuitBreaker.
+type CircuitBreakerOptions struct {
+	// ErrorRate specifies the ratio of failed requests in the statistics window that trips the breaker.
+	// It's 0.5 in default.
+	ErrorRate float64 `json:"errorRate"`
+
+	// SlowThreshold specifies the handling duration over which the request is considered slow.
+	// It's 0 in default, which means the breaker does not trip on latency.
+	SlowThreshold time.Duration `json:"slowThreshold"`
+
+	// SlowRate specifies the ratio of slow requests in the statistics window that trips the breaker.
+	// It takes effect only if SlowThreshold is positive. It's 0.5 in default.
+	SlowRate float64 `json:"slowRate"`
+
+	// MinRequests specifies the minimum number of requests in the statistics window
+	// before the breaker calculates the ratios. It's 20 in default.
+	MinRequests int `json:"minRequests"`
+
+	// Window specifies the duration of the statistics window. It's 10 seconds in default.
+	Window time.Duration `json:"window"`
+
+	// OpenTimeout specifies the duration that the breaker keeps open before it allows
+	// trial requests in half-open state. It's 30 seconds in default.
+	OpenTimeout time.Duration `json:"openTimeout"`
+
+	// HalfOpenRequests specifies the number of trial requests in half-open state.
+	// The breaker closes if all of them succeed, or else it opens again. It's 1 in default.
+	HalfOpenRequests int `json:"halfOpenRequests"`
+
+	// IsFailure checks whether the handled request `r` is failed.
+	// It's failed in default if the response status is 5XX, or the handler returns an error
+	// that is not caused by the client, like validation error.
+	IsFailure func(r *Request) bool `json:"-"`
+}
+
+// CircuitBreakerState is the state of circuit breaker.
+type CircuitBreakerState int
+
+const (
+	CircuitBreakerStateClosed   CircuitBreakerState = 0 // Requests are handled and the failures are counted.
+	CircuitBreakerStateOpen     CircuitBreakerState = 1 // Requests are rejected at once.
+	CircuitBreakerStateHalfOpen CircuitBreakerState = 2 // Limited trial requests are handled to detect the recovery.
+)
+
+var (
+	// ErrCircuitBreakerOpen is the error that indicates the request is rejected as the circuit breaker is open.
+	ErrCircuitBreakerOpen = gerror.NewWithOption(gerror.Option{
+		Text: "circuit breaker is open",
+		Code: gcode.CodeServerBusy,
+	})
+
+	// circuitBreakerClientErrorCodes is the error codes caused by the client,
+	// which are not considered as failures in default.
+	circuitBreakerClientErrorCodes = map[int]struct{}{
+		gcode.CodeValidationFailed.Code():         {},
+		gcode.CodeInvalidParameter.Code():         {},
+		gcode.CodeMissingParameter.Code():         {},
+		gcode.CodeInvalidRequest.Code():           {},
+		gcode.CodeNotAuthorized.Code():            {},
+		gcode.CodeNotFound.Code():                 {},
+		gcode.CodeSecurityReason.Code():           {},
+		gcode.CodeBusinessValidationFailed.Code(): {},
+	}
+)
+
+// String returns the name of the state.
+func (s CircuitBreakerState) String() string {
+	switch s {
+	case CircuitBreakerStateOpen:
+		return "open"
+	case CircuitBreakerStateHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// MiddlewareCircuitBreaker creates and returns a middleware that stops handling requests of a route
+// for a while if the route degrades, which gives it time to recover and fails fast the requests.
+//
+// The breaker of each route trips to open state if the ratio of failed requests or slow requests in the
+// statistics window exceeds the threshold. In open state, the requests are rejected with HTTP status 503
+// and ErrCircuitBreakerOpen, which is written by MiddlewareHandlerResponse if it is used before this middleware.
+// After OpenTimeout, it turns to half-open state that allows limited trial requests, and closes if they succeed.
+//
+// The options are from, in priority order: meta tag "circuitBreaker" of the request struct like
+// `circuitBreaker:"errorRate=0.3,slowThreshold=1s"`, parameter `options` and the server configuration
+// CircuitBreaker. The state of breakers is exposed as metric "http.server.circuit_breaker.state".
+func MiddlewareCircuitBreaker(options ...CircuitBreakerOptions) HandlerFunc {
+	var (
+		defaultOptions *CircuitBreakerOptions
+		breakers       sync.Map // Route key -> *circuitBreaker
+	)
+	if len(options) > 0 {
+		defaultOptions = &options[0]
+	}
+	return func(r *Request) {
+		if r.serveHandler == nil {
+			r.Middleware.Next()
+			return
+		}
+		var (
+			handler        = r.serveHandler.Handler
+			key            = getHandlerRouteKey(handler)
+			breakerOptions = defaultOptions
+		)
+		if breakerOptions == nil {
+			breakerOptions = &r.Server.config.CircuitBreaker
+		}
+		if handler.circuitBreaker != nil {
+			breakerOptions = handler.circuitBreaker
+		}
+		v, ok := breakers.Load(key)
+		if !ok {
+			v, _ = breakers.LoadOrStore(key, newCircuitBreaker(r, *breakerOptions))
+		}
+		b := v.(*circuitBreaker)
+		if !b.Allow() {
+			r.Server.handleMetricsCircuitBreakerRejected(r)
+			r.Response.ClearBuffer()
+			r.Response.WriteHeader(http.StatusServiceUnavailable)
+			r.SetError(ErrCircuitBreakerOpen)
+			return
+		}
+		start := time.Now()
+		r.Middleware.Next()
+		b.Done(b.isFailure(r), time.Since(start))
+	}
+}
+
+// circuitBreaker is the circuit breaker of a route.
+type circuitBreaker struct {
+	*breaker.Breaker
+	isFailure func(r *Request) bool
+}
+
+// newCircuitBreaker creates and returns a circuit breaker for the route of request `r`.
+func newCircuitBreaker(r *Request, options CircuitBreakerOptions) *circuitBreaker {
+	if options.IsFailure == nil {
+		options.IsFailure = isCircuitBreakerFailure
+	}
+	var (
+		ctx          = context.WithoutCancel(r.Context())
+		metricOption gmetric.Option
+	)
+	if gmetric.IsEnabled() {
+		metricOption = metricManager.GetMetricOptionForCircuitBreaker(r)
+	}
+	return &circuitBreaker{
+		Breaker: breaker.New(breaker.Options{
+			ErrorRate:        options.ErrorRate,
+			SlowThreshold:    options.SlowThreshold,
+			SlowRate:         options.SlowRate,
+			MinRequests:      options.MinRequests,
+			Window:           options.Window,
+			OpenTimeout:      options.OpenTimeout,
+			HalfOpenRequests: options.HalfOpenRequests,
+			OnStateChange: func(from, to breaker.State) {
+				r.Server.handleMetricsCircuitBreakerState(ctx, metricOption, from, to)
+			},
+		}),
+		isFailure: options.IsFailure,
+	}
+}
+
+// isCircuitBreakerFailure is the default function checking whether the handled request `r` is failed.
+func isCircuitBreakerFailure(r *Request) bool {
+	if r.Response.Status >= http.StatusInternalServerError {
+		return true
+	}
+	if err := r.GetError(); err != nil {
+		_, ok := circuitBreakerClientErrorCodes[gerror.Code(err).Code()]
+		return !ok
+	}
+	return false
+}
+
+func (s *Server) handleMetricsCircuitBreakerRejected(r *Request) {
+	if !gmetric.IsEnabled() {
+		return
+	}
+	metricManager.HttpServerCircuitBreakerRejected.Inc(
+		r.Context(),
+		metricManager.GetMetricOptionForRequest(r),
+	)
+}
+
+// handleMetricsCircuitBreakerState records the state change of breaker,
+// which makes the metric value of route the number of current state.
+func (s *Server) handleMetricsCircuitBreakerState(
+	ctx context.Context, option gmetric.Option, from, to breaker.State,
+) {
+	if !gmetric.IsEnabled() {
+		return
+	}
+	metricManager.HttpServerCircuitBreakerState.Add(ctx, float64(to-from), option)
+}
@@ -0,0 +1,226 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package ghttp
+
+import (
+	"bytes"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gogf/gf/v2/encoding/gcompress"
+)
+
+// CompressOptions is the options for response compression.
+type CompressOptions struct {
+	// Encodings specifies the content encodings in server preference order,
+	// which is used to choose among encodings of the same quality value from request header "Accept-Encoding".
+	// Only the encodings of which codec registered in package gcompress take effect.
+	// It's "br", "zstd", "gzip" in default.
+	Encodings []string
+	// Levels specifies the compression level of each encoding, which is specific to the algorithm.
+	// It uses the default level of the codec if the level of encoding is not specified.
+	Levels map[string]int
+	// MinSize specifies the minimum size in bytes of response content for compression, it's 1KB in default.
+	MinSize int
+	// ContentTypes specifies the media types of response content for compression, which support
+	// suffix wildcard like "text/*". It's common text media types in default.
+	ContentTypes []string
+}
+
+const (
+	defaultCompressMinSize = 1024
+)
+
+var (
+	// defaultCompressEncodings is the default content encodings in server preference order.
+	defaultCompressEncodings = []string{gcompress.EncodingBrotli, gcompress.EncodingZstd, gcompress.EncodingGzip}
+
+	// defaultCompressContentTypes is the default media types for compression.
+	defaultCompressContentTypes = []string{
+		"text/*",
+		"application/json",
+		"application/javascript",
+		"application/x-javascript",
+		"application/xml",
+		"application/wasm",
+		"image/svg+xml",
+	}
+
+	// precompressedExtensions is the file extensions of precompressed static files for encodings.
+	precompressedExtensions = map[string]string{
+		gcompress.EncodingBrotli: ".br",
+		gcompress.EncodingZstd:   ".zst",
+		gcompress.EncodingGzip:   ".gz",
+	}
+)
+
+// MiddlewareCompress creates and returns a middleware that compresses the response content
+// with the encoding negotiated by request header "Accept-Encoding" and its quality values.
+//
+// Note that it does not compress responses if:
+// 1. The response is already encoded (Content-Encoding header is set) or written to client.
+// 2. The client accepts none of the configured encodings of which codec registered.
+// 3. The response content is smaller than MinSize, or its media type is not in ContentTypes.
+func MiddlewareCompress(options ...CompressOptions) HandlerFunc {
+	var option CompressOptions
+	if len(options) > 0 {
+		option = options[0]
+	}
+	if len(option.Encodings) == 0 {
+		option.Encodings = defaultCompressEncodings
+	}
+	if option.MinSize <= 0 {
+		option.MinSize = defaultCompressMinSize
+	}
+	if len(option.ContentTypes) == 0 {
+		option.ContentTypes = defaultCompressContentTypes
+	}
+	return func(r *Request) {
+		r.Middleware.Next()
+
+		var (
+			header = r.Response.Header()
+			buffer = r.Response.Buffer()
+		)
+		if header.Get("Content-Encoding") != "" || r.Response.Writer.BytesWritten() > 0 {
+			return
+		}
+		switch r.Response.Status {
+		case http.StatusNoContent, http.StatusNotModified, http.StatusPartialContent:
+			return
+		}
+		// The content type is detected before compression,
+		// as it cannot be detected from the compressed content.
+		contentType := header.Get("Content-Type")
+		if contentType == "" && len(buffer) > 0 {
+			contentType = http.DetectContentType(buffer)
+			header.Set("Content-Type", contentType)
+		}
+		if !matchCompressContentType(contentType, option.ContentTypes) {
+			return
+		}
+		// The response varies on the request header whether it is compressed or not.
+		addVaryHeader(header, "Accept-Encoding")
+		if len(buffer) < option.MinSize {
+			return
+		}
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"), option.Encodings, true)
+		if encoding == "" {
+			return
+		}
+		level, ok := option.Levels[encoding]
+		if !ok {
+			level = gcompress.LevelDefault
+		}
+		var (
+			compressed  bytes.Buffer
+			ctx         = r.Context()
+			writer, err = gcompress.GetCodec(encoding).NewWriter(&compressed, level)
+		)
+		if err == nil {
+			if _, err = writer.Write(buffer); err == nil {
+				err = writer.Close()
+			}
+		}
+		if err != nil {
+			r.Server.Logger().Warningf(ctx, `%s compression failed: %+v`, encoding, err)
+			return
+		}
+		r.Response.ClearBuffer()
+		header.Set("Content-Encoding", encoding)
+		header.Del("Content-Length")
+		r.Response.Write(compressed.Bytes())
+	}
+}
+
+// negotiateEncoding returns the most preferred content encoding from `encodings` by `acceptEncoding`,
+// which is the value of request header "Accept-Encoding". The encodings of the same quality value are
+// chosen in the order of `encodings`. It returns empty string if no encoding is acceptable.
+//
+// If `registered` is true, only the encodings of which codec registered in package gcompress are chosen.
+func negotiateEncoding(acceptEncoding string, encodings []string, registered bool) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+	var (
+		qualities       = make(map[string]float64)
+		wildcardQuality = -1.0
+	)
+	for _, item := range strings.Split(acceptEncoding, ",") {
+		var (
+			coding  = item
+			quality = 1.0
+		)
+		if pos := strings.IndexByte(item, ';'); pos >= 0 {
+			coding = item[:pos]
+			for _, param := range strings.Split(item[pos+1:], ";") {
+				key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if strings.EqualFold(key, "q") {
+					if v, err := strconv.ParseFloat(value, 64); err == nil {
+						quality = v
+					}
+				}
+			}
+		}
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding == "*" {
+			wildcardQuality = quality
+		} else if coding != "" {
+			qualities[coding] = quality
+		}
+	}
+	var (
+		bestEncoding string
+		bestQuality  float64
+	)
+	for _, encoding := range encodings {
+		quality, ok := qualities[encoding]
+		if !ok {
+			quality = wildcardQuality
+		}
+		if quality <= bestQuality {
+			continue
+		}
+		if registered && gcompress.GetCodec(encoding) == nil {
+			continue
+		}
+		bestEncoding, bestQuality = encoding, quality
+	}
+	return bestEncoding
+}
+
+// matchCompressContentType checks whether the media type of `contentType` matches any of `patterns`.
+func matchCompressContentType(contentType string, patterns []string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(mediaType, prefix) {
+				return true
+			}
+		} else if mediaType == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// addVaryHeader adds `value` to response header "Vary" if it does not exist.
+func addVaryHeader(header http.Header, value string) {
+	for _, vary := range header.Values("Vary") {
+		for _, v := range strings.Split(vary, ",") {
+			if strings.EqualFold(strings.TrimSpace(v), value) {
+				return
+			}
+		}
+	}
+	header.Add("Vary", value)
+}
@@ -0,0 +1,216 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package ghttp
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/util/grand"
+)
+
+// CSRFMode is the token storing mode for CSRF protection.
+type CSRFMode string
+
+const (
+	// CSRFModeSession stores the token in session, which is validated per session.
+	CSRFModeSession CSRFMode = "session"
+	// CSRFModeCookie stores the token in cookie, which is validated using double-submit cookie pattern.
+	CSRFModeCookie CSRFMode = "cookie"
+)
+
+const (
+	// CSRFTemplateVarName is the build-in template variable name for CSRF token.
+	CSRFTemplateVarName = "csrf_token"
+
+	defaultCSRFTokenLength = 32
+	defaultCSRFHeaderName  = "X-CSRF-Token"
+	defaultCSRFFieldName   = "_csrf"
+	defaultCSRFCookieName  = "_csrf"
+	defaultCSRFSessionKey  = "_csrf_token"
+)
+
+// CSRFOptions is the options for CSRF protection feature.
+type CSRFOptions struct {
+	Mode         CSRFMode                    // Token storing mode, it's CSRFModeSession in default.
+	TokenLength  int                         // Length of the random token, it's 32 in default.
+	HeaderName   string                      // Request header name for submitted token, it's "X-CSRF-Token" in default.
+	FieldName    string                      // Form field name for submitted token, it's "_csrf" in default.
+	CookieName   string                      // Cookie name for token in CSRFModeCookie, it's "_csrf" in default.
+	CookieMaxAge time.Duration               // Cookie TTL for token in CSRFModeCookie, it uses server cookie max age if 0.
+	SessionKey   string                      // Session key for token in CSRFModeSession, it's "_csrf_token" in default.
+	ExemptPaths  []string                    // Request paths that are not validated, eg: "/api/*", "/callback/pay". See MiddlewareCSRFWithOptions.
+	ExemptFunc   func(r *Request) bool       // Custom checking function for exemption, it's exempted if it returns true.
+	ErrorHandler func(r *Request, err error) // Custom handler for validation failure.
+}
+
+var (
+	// ErrCSRFTokenInvalid is the error that indicates the CSRF token of the request is missing or invalid.
+	ErrCSRFTokenInvalid = gerror.NewWithOption(gerror.Option{
+		Text: "invalid or missing CSRF token",
+		Code: gcode.CodeSecurityReason,
+	})
+
+	// csrfSafeMethods are the HTTP methods that do not need CSRF validation.
+	csrfSafeMethods = map[string]struct{}{
+		http.MethodGet:     {},
+		http.MethodHead:    {},
+		http.MethodOptions: {},
+		http.MethodTrace:   {},
+	}
+)
+
+// MiddlewareCSRF is a middleware handler for CSRF protection with default options.
+func MiddlewareCSRF(r *Request) {
+	handleCSRF(r, CSRFOptions{})
+}
+
+// MiddlewareCSRFWithOptions creates and returns a middleware handler for CSRF protection
+// with custom options.
+//
+// It issues a token for every request, which can be retrieved using Request.GetCSRFToken
+// or template variable `csrf_token`. The token should be submitted using request header or
+// form field for unsafe HTTP methods like POST, PUT, PATCH and DELETE.
+//
+// Note that the option ExemptPaths is matched against the request URL path, not the router pattern,
+// and pattern ending with "/*" matches the path and all its sub paths. It's suggested binding the
+// middleware to certain route groups instead of exempting paths, which scopes it the same as other
+// middlewares.
+func MiddlewareCSRFWithOptions(options CSRFOptions) HandlerFunc {
+	return func(r *Request) {
+		handleCSRF(r, options)
+	}
+}
+
+// GetCSRFToken returns the CSRF token issued for current request.
+// It returns empty string if CSRF middleware is not used.
+func (r *Request) GetCSRFToken() string {
+	return r.csrfToken
+}
+
+// handleCSRF issues and validates the CSRF token for request `r`.
+func handleCSRF(r *Request, options CSRFOptions) {
+	options = csrfOptionsWithDefault(options)
+	token, err := csrfIssueToken(r, options)
+	if err != nil {
+		r.Response.WriteHeader(http.StatusInternalServerError)
+		r.SetError(err)
+		return
+	}
+	r.csrfToken = token
+	if _, ok := csrfSafeMethods[r.Method]; ok || csrfIsExempted(r, options) {
+		r.Middleware.Next()
+		return
+	}
+	submitted := r.Header.Get(options.HeaderName)
+	if submitted == "" {
+		submitted = r.GetForm(options.FieldName).String()
+	}
+	if submitted == "" || subtle.ConstantTimeCompare([]byte(submitted), []byte(token)) != 1 {
+		if options.ErrorHandler != nil {
+			options.ErrorHandler(r, ErrCSRFTokenInvalid)
+			return
+		}
+		r.Response.WriteHeader(http.StatusForbidden)
+		r.SetError(ErrCSRFTokenInvalid)
+		return
+	}
+	r.Middleware.Next()
+}
+
+// csrfOptionsWithDefault fills empty attributes of `options` with default values.
+func csrfOptionsWithDefault(options CSRFOptions) CSRFOptions {
+	if options.Mode == "" {
+		options.Mode = CSRFModeSession
+	}
+	if options.TokenLength <= 0 {
+		options.TokenLength = defaultCSRFTokenLength
+	}
+	if options.HeaderName == "" {
+		options.HeaderName = defaultCSRFHeaderName
+	}
+	if options.FieldName == "" {
+		options.FieldName = defaultCSRFFieldName
+	}
+	if options.CookieName == "" {
+		options.CookieName = defaultCSRFCookieName
+	}
+	if options.SessionKey == "" {
+		options.SessionKey = defaultCSRFSessionKey
+	}
+	return options
+}
+
+// csrfIssueToken retrieves the token from storage of current request,
+// or else creates and stores a new one.
+func csrfIssueToken(r *Request, options CSRFOptions) (string, error) {
+	switch options.Mode {
+	case CSRFModeCookie:
+		if token := r.Cookie.Get(options.CookieName).String(); token != "" {
+			return token, nil
+		}
+		var (
+			token  = grand.S(options.TokenLength)
+			maxAge = options.CookieMaxAge
+		)
+		if maxAge == 0 {
+			maxAge = r.Server.GetCookieMaxAge()
+		}
+		// The cookie should be readable by javascript for double-submit pattern,
+		// so it is never HttpOnly.
+		r.Cookie.SetCookie(
+			options.CookieName,
+			token,
+			r.Server.GetCookieDomain(),
+			r.Server.GetCookiePath(),
+			maxAge,
+			CookieOptions{
+				SameSite: r.Server.GetCookieSameSite(),
+				Secure:   r.Server.GetCookieSecure(),
+			},
+		)
+		return token, nil
+
+	default:
+		v, err := r.Session.Get(options.SessionKey)
+		if err != nil {
+			return "", gerror.WrapCode(gcode.CodeInternalError, err, `retrieve CSRF token from session failed`)
+		}
+		if token := v.String(); token != "" {
+			return token, nil
+		}
+		token := grand.S(options.TokenLength)
+		if err = r.Session.Set(options.SessionKey, token); err != nil {
+			return "", gerror.WrapCode(gcode.CodeInternalError, err, `store CSRF token to session failed`)
+		}
+		return token, nil
+	}
+}
+
+// csrfIsExempted checks and returns whether current request is exempted from CSRF validation.
+func csrfIsExempted(r *Request, options CSRFOptions) bool {
+	if options.ExemptFunc != nil && options.ExemptFunc(r) {
+		return true
+	}
+	for _, pattern := range options.ExemptPaths {
+		if strings.HasSuffix(pattern, "/*") {
+			prefix := pattern[:len(pattern)-2]
+			if r.URL.Path == prefix || strings.HasPrefix(r.URL.Path, prefix+"/") {
+				return true
+			}
+			continue
+		}
+		if r.URL.Path == pattern {
+			return true
+		}
+	}
+	return false
+}
@@ -33,6 +33,8 @@ var (
 )
 
 // MiddlewareHandlerResponse is the default middleware handling handler response object and its error.
+// The response content is encoded in the media type negotiated by request header `Accept`,
+// which is JSON in default. See Request.NegotiateMediaType.
 func MiddlewareHandlerResponse(r *Request) {
 	r.Middleware.Next()
 
@@ -79,7 +81,7 @@ func MiddlewareHandlerResponse(r *Request) {
 		msg = code.Message()
 	}
 
-	r.Response.WriteJson(DefaultHandlerResponse{
+	r.Response.WriteNegotiated(DefaultHandlerResponse{
 		Code:    code.Code(),
 		Message: msg,
 		Data:    res,
@@ -0,0 +1,142 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package ghttp
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/internal/httputil"
+)
+
+// HMACVerifyOptions is the options for HMAC request signature verification,
+// which verifies the requests signed by gclient.MiddlewareHMACSign.
+type HMACVerifyOptions struct {
+	Secrets              map[string]string                              // Secrets by key id.
+	SecretFunc           func(r *Request, keyId string) (string, error) // Custom function retrieving secret by key id, which takes priority over Secrets.
+	ClockSkew            time.Duration                                  // Allowed clock skew of request timestamp, it's 5 minutes in default.
+	SignedHeaders        []string                                       // Headers that must be signed besides "Host", "X-Signature-Timestamp" and "X-Content-SHA256".
+	AllowUnsignedPayload bool                                           // Whether the request with "UNSIGNED-PAYLOAD" body hash is allowed.
+	ErrorHandler         func(r *Request, err error)                    // Custom handler for verification failure.
+}
+
+const (
+	defaultHMACClockSkew = 5 * time.Minute
+)
+
+var (
+	// ErrHMACSignatureInvalid is the error that indicates the request signature is missing or invalid.
+	ErrHMACSignatureInvalid = gerror.NewWithOption(gerror.Option{
+		Text: "invalid or missing request signature",
+		Code: gcode.CodeNotAuthorized,
+	})
+
+	// hmacRequiredSignedHeaders are the headers that are always signed.
+	hmacRequiredSignedHeaders = []string{
+		"host",
+		strings.ToLower(httputil.SignHeaderTimestamp),
+		strings.ToLower(httputil.SignHeaderContentSHA256),
+	}
+)
+
+// MiddlewareHMACVerify creates and returns a middleware handler verifying the HMAC signature
+// of requests, whose key id can be retrieved using Request.GetHMACKeyId after verification.
+//
+// It verifies the signed headers, the request timestamp in the allowed clock skew, and the body hash.
+// The failed request is responded with status 401 and the server clock in header "Date",
+// by which the client corrects its clock.
+func MiddlewareHMACVerify(options HMACVerifyOptions) HandlerFunc {
+	if options.ClockSkew <= 0 {
+		options.ClockSkew = defaultHMACClockSkew
+	}
+	return func(r *Request) {
+		keyId, err := hmacVerify(r, options)
+		if err != nil {
+			if options.ErrorHandler != nil {
+				options.ErrorHandler(r, err)
+				return
+			}
+			r.Response.Header().Set("Date", time.Now().UTC().Format(http.TimeFormat))
+			r.Response.WriteHeader(http.StatusUnauthorized)
+			r.SetError(err)
+			return
+		}
+		r.hmacKeyId = keyId
+		r.Middleware.Next()
+	}
+}
+
+// GetHMACKeyId returns the key id of request signature verified by HMAC verification middleware.
+// It returns empty string if the middleware is not used.
+func (r *Request) GetHMACKeyId() string {
+	return r.hmacKeyId
+}
+
+// hmacVerify verifies the HMAC signature of request `r` and returns its key id.
+func hmacVerify(r *Request, options HMACVerifyOptions) (keyId string, err error) {
+	authorization, err := httputil.ParseSignAuthorization(r.Header.Get("Authorization"))
+	if err != nil {
+		return "", ErrHMACSignatureInvalid
+	}
+	signedHeaders := make(map[string]struct{}, len(authorization.SignedHeaders))
+	for _, name := range authorization.SignedHeaders {
+		signedHeaders[name] = struct{}{}
+	}
+	for _, name := range append(hmacRequiredSignedHeaders, options.SignedHeaders...) {
+		if _, ok := signedHeaders[strings.ToLower(name)]; !ok {
+			return "", gerror.Wrapf(ErrHMACSignatureInvalid, `header "%s" is not signed`, name)
+		}
+	}
+	// Request timestamp.
+	var timestamp = r.Header.Get(httputil.SignHeaderTimestamp)
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return "", gerror.Wrap(ErrHMACSignatureInvalid, `invalid request timestamp`)
+	}
+	if skew := time.Since(time.Unix(seconds, 0)); skew > options.ClockSkew || skew < -options.ClockSkew {
+		return "", gerror.Wrap(ErrHMACSignatureInvalid, `request timestamp is out of allowed clock skew`)
+	}
+	// Body hash.
+	bodyHash := r.Header.Get(httputil.SignHeaderContentSHA256)
+	if bodyHash == httputil.SignUnsignedPayload {
+		if !options.AllowUnsignedPayload {
+			return "", gerror.Wrap(ErrHMACSignatureInvalid, `unsigned payload is not allowed`)
+		}
+	} else if subtle.ConstantTimeCompare([]byte(bodyHash), []byte(httputil.SignHashHex(r.GetBody()))) != 1 {
+		return "", gerror.Wrap(ErrHMACSignatureInvalid, `request body hash mismatch`)
+	}
+	// Signature.
+	var secret string
+	if options.SecretFunc != nil {
+		if secret, err = options.SecretFunc(r, authorization.KeyId); err != nil {
+			return "", gerror.Wrap(ErrHMACSignatureInvalid, err.Error())
+		}
+	} else {
+		secret = options.Secrets[authorization.KeyId]
+	}
+	if secret == "" {
+		return "", gerror.Wrapf(ErrHMACSignatureInvalid, `unknown key id "%s"`, authorization.KeyId)
+	}
+	u := *r.URL
+	u.Path = r.originUrlPath
+	canonicalRequest, _ := httputil.SignCanonicalRequest(
+		r.Method, &u, r.Host, r.Header, authorization.SignedHeaders, bodyHash,
+	)
+	signature, err := httputil.SignSignature(authorization.Algorithm, secret, timestamp, canonicalRequest)
+	if err != nil {
+		return "", gerror.Wrap(ErrHMACSignatureInvalid, err.Error())
+	}
+	if subtle.ConstantTimeCompare([]byte(signature), []byte(authorization.Signature)) != 1 {
+		return "", gerror.Wrap(ErrHMACSignatureInvalid, `signature mismatch`)
+	}
+	return authorization.KeyId, nil
+}
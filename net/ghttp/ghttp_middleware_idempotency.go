@@ -0,0 +1,249 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package ghttp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/internal/json"
+	"github.com/gogf/gf/v2/os/gcache"
+)
+
+// IdempotencyOptions is the options for idempotency-key feature.
+type IdempotencyOptions struct {
+	// Adapter specifies the cache adapter storing the keys and responses,
+	// which can be memory or redis adapter of package gcache.
+	// It uses memory adapter in default.
+	Adapter gcache.Adapter
+	// HeaderName specifies the request header name of the key, it's "Idempotency-Key" in default.
+	HeaderName string
+	// Methods specifies the HTTP methods that are handled, it's POST and PATCH in default.
+	Methods []string
+	// TTL specifies the expiration of stored responses, it's 24 hours in default.
+	TTL time.Duration
+	// LockTTL specifies the maximum locking duration of a key in execution, it's 1 minute in default.
+	// It should be greater than the maximum execution time of the handler.
+	LockTTL time.Duration
+	// Required specifies whether the key header is required for handled methods.
+	Required bool
+	// KeyFunc customizes the storage key with given request key, which can be used to scope the key,
+	// for example, with the authenticated user id.
+	KeyFunc func(r *Request, key string) string
+}
+
+// idempotencyRecord is the stored response of the first execution for an idempotency key.
+type idempotencyRecord struct {
+	Fingerprint string      `json:"fingerprint"` // Fingerprint of the request.
+	Status      int         `json:"status"`      // Response status.
+	Header      http.Header `json:"header"`      // Response header.
+	Body        []byte      `json:"body"`        // Response body.
+}
+
+const (
+	// HeaderIdempotentReplayed is the response header marking the response is replayed from stored result.
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+	defaultIdempotencyHeaderName = "Idempotency-Key"
+	defaultIdempotencyTTL        = 24 * time.Hour
+	defaultIdempotencyLockTTL    = time.Minute
+	idempotencyCacheKeyPrefix    = "ghttp:idempotency:"
+	idempotencyLockKeyPrefix     = "ghttp:idempotency:lock:"
+)
+
+var (
+	// ErrIdempotencyKeyMissing is the error that indicates the idempotency key is required but missing.
+	ErrIdempotencyKeyMissing = gerror.NewWithOption(gerror.Option{
+		Text: "missing idempotency key",
+		Code: gcode.CodeMissingParameter,
+	})
+
+	// ErrIdempotencyKeyInProgress is the error that indicates the request with the same key is in progress.
+	ErrIdempotencyKeyInProgress = gerror.NewWithOption(gerror.Option{
+		Text: "request with the same idempotency key is in progress",
+		Code: gcode.CodeInvalidOperation,
+	})
+
+	// ErrIdempotencyKeyReused is the error that indicates the key is reused with different request payload.
+	ErrIdempotencyKeyReused = gerror.NewWithOption(gerror.Option{
+		Text: "idempotency key is already used with different request payload",
+		Code: gcode.CodeInvalidRequest,
+	})
+)
+
+// MiddlewareIdempotency creates and returns a middleware handler that honours idempotency key
+// for unsafe methods.
+//
+// The first request with a key is executed and its final response is stored. Subsequent requests
+// with the same key are replayed with the stored response without executing the handler.
+// Concurrent requests with the same key are rejected with HTTP status 409, and requests reusing
+// the key with different payload are rejected with HTTP status 422.
+//
+// Note that failed requests, like responses with HTTP status 5XX or with handler error, are not stored,
+// so the client can retry them.
+func MiddlewareIdempotency(options ...IdempotencyOptions) HandlerFunc {
+	var option IdempotencyOptions
+	if len(options) > 0 {
+		option = options[0]
+	}
+	if option.Adapter == nil {
+		option.Adapter = gcache.NewAdapterMemory()
+	}
+	if option.HeaderName == "" {
+		option.HeaderName = defaultIdempotencyHeaderName
+	}
+	if len(option.Methods) == 0 {
+		option.Methods = []string{http.MethodPost, http.MethodPatch}
+	}
+	if option.TTL <= 0 {
+		option.TTL = defaultIdempotencyTTL
+	}
+	if option.LockTTL <= 0 {
+		option.LockTTL = defaultIdempotencyLockTTL
+	}
+	var (
+		cache   = gcache.NewWithAdapter(option.Adapter)
+		methods = make(map[string]struct{}, len(option.Methods))
+	)
+	for _, method := range option.Methods {
+		methods[method] = struct{}{}
+	}
+	return func(r *Request) {
+		if _, ok := methods[r.Method]; !ok {
+			r.Middleware.Next()
+			return
+		}
+		key := r.Header.Get(option.HeaderName)
+		if key == "" {
+			if option.Required {
+				r.Response.WriteHeader(http.StatusBadRequest)
+				r.SetError(ErrIdempotencyKeyMissing)
+				return
+			}
+			r.Middleware.Next()
+			return
+		}
+		if option.KeyFunc != nil {
+			key = option.KeyFunc(r, key)
+		}
+		var (
+			ctx         = r.Context()
+			cacheKey    = idempotencyCacheKeyPrefix + key
+			lockKey     = idempotencyLockKeyPrefix + key
+			fingerprint = idempotencyFingerprint(r)
+		)
+		// Replay the stored response.
+		if idempotencyReplay(r, cache, cacheKey, fingerprint) {
+			return
+		}
+		locked, err := cache.SetIfNotExist(ctx, lockKey, fingerprint, option.LockTTL)
+		if err != nil {
+			r.Response.WriteHeader(http.StatusInternalServerError)
+			r.SetError(err)
+			return
+		}
+		if !locked {
+			// The first request might be just done between the replaying and locking.
+			if idempotencyReplay(r, cache, cacheKey, fingerprint) {
+				return
+			}
+			r.Response.WriteHeader(http.StatusConflict)
+			r.SetError(ErrIdempotencyKeyInProgress)
+			return
+		}
+		// The response is stored after it's finalized by the following middlewares and the server,
+		// like the handler response and error are written to response.
+		r.outputFuncs = append(r.outputFuncs, func() {
+			defer func() {
+				if _, err := cache.Remove(ctx, lockKey); err != nil {
+					r.Server.Logger().Errorf(ctx, `%+v`, err)
+				}
+			}()
+			idempotencyStore(r, cache, cacheKey, fingerprint, option.TTL)
+		})
+		r.Middleware.Next()
+	}
+}
+
+// idempotencyStore stores the final response of request with `cacheKey`.
+// The failed request and stream response are not stored.
+func idempotencyStore(r *Request, cache *gcache.Cache, cacheKey, fingerprint string, ttl time.Duration) {
+	if r.GetError() != nil || r.Response.Status >= http.StatusInternalServerError ||
+		r.Response.BytesWritten() > 0 {
+		return
+	}
+	var (
+		ctx    = r.Context()
+		record = idempotencyRecord{
+			Fingerprint: fingerprint,
+			Status:      r.Response.Status,
+			Header:      r.Response.Header().Clone(),
+			Body:        r.Response.Buffer(),
+		}
+	)
+	if record.Status == 0 {
+		record.Status = http.StatusOK
+	}
+	// Cookies should not be shared with other requests.
+	record.Header.Del("Set-Cookie")
+	content, err := json.Marshal(record)
+	if err != nil {
+		r.Server.Logger().Errorf(ctx, `%+v`, err)
+		return
+	}
+	if err = cache.Set(ctx, cacheKey, string(content), ttl); err != nil {
+		r.Server.Logger().Errorf(ctx, `%+v`, err)
+	}
+}
+
+// idempotencyReplay writes the stored response with `cacheKey` to response,
+// it returns true if the request is handled.
+func idempotencyReplay(r *Request, cache *gcache.Cache, cacheKey, fingerprint string) bool {
+	v, err := cache.Get(r.Context(), cacheKey)
+	if err != nil {
+		r.Response.WriteHeader(http.StatusInternalServerError)
+		r.SetError(err)
+		return true
+	}
+	if v.IsNil() {
+		return false
+	}
+	var record idempotencyRecord
+	if err = json.Unmarshal(v.Bytes(), &record); err != nil {
+		r.Response.WriteHeader(http.StatusInternalServerError)
+		r.SetError(err)
+		return true
+	}
+	if record.Fingerprint != fingerprint {
+		r.Response.WriteHeader(http.StatusUnprocessableEntity)
+		r.SetError(ErrIdempotencyKeyReused)
+		return true
+	}
+	header := r.Response.Header()
+	for k, values := range record.Header {
+		header[k] = values
+	}
+	header.Set(HeaderIdempotentReplayed, "true")
+	r.Response.WriteHeader(record.Status)
+	r.Response.SetBuffer(record.Body)
+	return true
+}
+
+// idempotencyFingerprint calculates and returns the fingerprint of request payload.
+func idempotencyFingerprint(r *Request) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method))
+	h.Write([]byte{0})
+	h.Write([]byte(r.URL.Path))
+	h.Write([]byte{0})
+	h.Write(r.GetBody())
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,77 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package ghttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+)
+
+var (
+	// ErrRequestTimeout is the error that indicates the request is not handled in its timeout.
+	ErrRequestTimeout = gerror.NewWithOption(gerror.Option{
+		Text: "request timeout",
+		Code: gcode.CodeServerBusy,
+	})
+)
+
+// MiddlewareTimeout creates and returns a middleware that limits the handling duration of requests.
+//
+// The timeout is from, in priority order: meta tag "timeout" of the request struct like `timeout:"3s"`,
+// parameter `timeout` and the server configuration RequestTimeout. There's no timeout if it is not positive.
+//
+// It sets a deadline to the request context, which is propagated to the calls made with the context,
+// like gclient requests and gdb queries, and cancels them when the deadline exceeds. Note that the handler
+// is not interrupted, it should return in time by respecting the context.
+//
+// If the deadline exceeds during handling, the response is HTTP status 504 with ErrRequestTimeout,
+// and if the deadline has already exceeded before handling, for example by the deadline of outer middleware,
+// the handler is not called and the response is HTTP status 503 with ErrRequestTimeout.
+// The error is written by MiddlewareHandlerResponse if it is used before this middleware.
+func MiddlewareTimeout(timeout ...time.Duration) HandlerFunc {
+	var defaultTimeout time.Duration
+	if len(timeout) > 0 {
+		defaultTimeout = timeout[0]
+	}
+	return func(r *Request) {
+		var requestTimeout = defaultTimeout
+		if requestTimeout <= 0 {
+			requestTimeout = r.Server.config.RequestTimeout
+		}
+		if r.serveHandler != nil && r.serveHandler.Handler.timeout > 0 {
+			requestTimeout = r.serveHandler.Handler.timeout
+		}
+		if requestTimeout <= 0 {
+			r.Middleware.Next()
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), requestTimeout)
+		defer cancel()
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			setRequestTimeoutError(r, http.StatusServiceUnavailable)
+			return
+		}
+		r.SetCtx(ctx)
+		r.Middleware.Next()
+		// The timeout error might be already set by the inner timeout middleware.
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && r.GetError() != ErrRequestTimeout {
+			setRequestTimeoutError(r, http.StatusGatewayTimeout)
+		}
+	}
+}
+
+// setRequestTimeoutError discards the response content and sets ErrRequestTimeout with HTTP `status`.
+func setRequestTimeoutError(r *Request, status int) {
+	r.Response.ClearBuffer()
+	r.Response.WriteHeader(status)
+	r.SetError(ErrRequestTimeout)
+}
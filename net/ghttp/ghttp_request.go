@@ -61,6 +61,11 @@ type Request struct {
 	viewObject      *gview.View            // Custom template view engine object for this response.
 	viewParams      gview.Params           // Custom template view variables for this response.
 	originUrlPath   string                 // Original URL path that passed from client.
+	csrfToken       string                 // CSRF token issued by CSRF middleware for current request.
+	hmacKeyId       string                 // Key id of the request signature verified by HMAC verification middleware.
+	accessLog       *accessLogCapture      // Access log capture, which is nil if access log is disabled or not sampled.
+	apiVersion      string                 // API version resolved from request, which is empty if there are no versioned routes.
+	outputFuncs     []func()               // Functions called with the final response before it is output.
 }
 
 // staticFile is the file struct for static file service.
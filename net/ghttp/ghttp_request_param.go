@@ -247,6 +247,12 @@ func (r *Request) parseBody() {
 		if body[0] == '<' && body[len(body)-1] == '>' {
 			r.bodyMap, _ = gxml.DecodeWithoutRoot(body)
 		}
+		// Registered codec decoding according to the content type.
+		if r.bodyMap == nil {
+			if codec := GetCodec(r.Header.Get("Content-Type")); codec != nil {
+				_ = codec.Decode(body, &r.bodyMap)
+			}
+		}
 		// Default parameters decoding.
 		if contentType := r.Header.Get("Content-Type"); (contentType == "" || !gstr.Contains(contentType, "multipart/")) && r.bodyMap == nil {
 			r.bodyMap, _ = gstr.Parse(r.GetBodyString())
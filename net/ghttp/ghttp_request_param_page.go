@@ -42,7 +42,7 @@ func (r *Request) GetPage(totalSize, pageSize int) *gpage.Page {
 				if len(match) > len(r.Router.RegNames) {
 					urlTemplate = r.Router.Uri
 					for i, name := range r.Router.RegNames {
-						rule := fmt.Sprintf(`[:\*]%s|\{%s\}`, name, name)
+						rule := fmt.Sprintf(`[:\*]%s|\{%s(:[^/]*?)?\}`, name, name)
 						if name == gpage.DefaultPageName {
 							urlTemplate, err = gregex.ReplaceString(rule, gpage.DefaultPagePlaceHolder, urlTemplate)
 						} else {
@@ -131,5 +131,8 @@ func (r *Response) Flush() {
 	if r.Server.config.ServerAgent != "" {
 		r.Header().Set("Server", r.Server.config.ServerAgent)
 	}
+	if r.Request != nil && r.Request.accessLog != nil && r.Request.accessLog.responseBody != nil {
+		_, _ = r.Request.accessLog.responseBody.Write(r.Buffer())
+	}
 	r.BufferWriter.Flush()
 }
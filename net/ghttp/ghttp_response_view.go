@@ -93,6 +93,10 @@ func (r *Response) buildInVars(params ...map[string]interface{}) map[string]inte
 		"Cookie":  r.Request.Cookie.Map(),
 		"Session": sessionMap,
 	})
+	// CSRF token is only available if CSRF middleware is used.
+	if r.Request.csrfToken != "" {
+		m[CSRFTemplateVarName] = r.Request.csrfToken
+	}
 	// Note that it should assign no Config variable to a template
 	// if there's no configuration file.
 	if v, _ := gcfg.Instance().Data(r.Request.Context()); len(v) > 0 {
@@ -106,7 +106,12 @@ func GetServer(name ...interface{}) *Server {
 			serveCache:       gcache.New(),
 			routesMap:        make(map[string][]*HandlerItem),
 			openapi:          goai.New(),
+			openapiVersions:  make(map[string]*goai.OpenApiV3),
+			apiVersions:      garray.NewSortedStrArrayComparator(compareApiVersion, true).SetUnique(true),
 			registrar:        gsvc.GetRegistry(),
+			healthEnabled:    gtype.NewBool(),
+			shuttingDown:     gtype.NewBool(),
+			prepared:         gtype.NewBool(),
 		}
 		// Initialize the server using default configurations.
 		if err := s.SetConfig(NewConfig()); err != nil {
@@ -124,81 +129,14 @@ func GetServer(name ...interface{}) *Server {
 func (s *Server) Start() error {
 	var ctx = gctx.GetInitCtx()
 
-	// Swagger UI.
-	if s.config.SwaggerPath != "" {
-		swaggerui.Init()
-		s.AddStaticPath(s.config.SwaggerPath, swaggerUIPackedPath)
-		s.BindHookHandler(s.config.SwaggerPath+"/*", HookBeforeServe, s.swaggerUI)
-	}
-
-	// OpenApi specification json producing handler.
-	if s.config.OpenApiPath != "" {
-		s.BindHandler(s.config.OpenApiPath, s.openapiSpec)
-	}
-
-	// Register group routes.
-	s.handlePreBindItems(ctx)
-
-	// Server process initialization, which can only be initialized once.
-	serverProcessInit()
-
 	// Server can only be run once.
 	if s.Status() == ServerStatusRunning {
 		return gerror.NewCode(gcode.CodeInvalidOperation, "server is already running")
 	}
-
-	// Logging path setting check.
-	if s.config.LogPath != "" && s.config.LogPath != s.config.Logger.GetPath() {
-		if err := s.config.Logger.SetPath(s.config.LogPath); err != nil {
-			return err
-		}
-	}
-	// Default session storage.
-	if s.config.SessionStorage == nil {
-		sessionStoragePath := ""
-		if s.config.SessionPath != "" {
-			sessionStoragePath = gfile.Join(s.config.SessionPath, s.config.Name)
-			if !gfile.Exists(sessionStoragePath) {
-				if err := gfile.Mkdir(sessionStoragePath); err != nil {
-					return gerror.Wrapf(err, `mkdir failed for "%s"`, sessionStoragePath)
-				}
-			}
-		}
-		s.config.SessionStorage = gsession.NewStorageFile(sessionStoragePath, s.config.SessionMaxAge)
-	}
-	// Initialize session manager when start running.
-	s.sessionManager = gsession.New(
-		s.config.SessionMaxAge,
-		s.config.SessionStorage,
-	)
-
-	// PProf feature.
-	if s.config.PProfEnabled {
-		s.EnablePProf(s.config.PProfPattern)
-	}
-
-	// Default HTTP handler.
-	if s.config.Handler == nil {
-		s.config.Handler = s.ServeHTTP
-	}
-
-	// Install external plugins.
-	for _, p := range s.plugins {
-		if err := p.Install(s); err != nil {
-			s.Logger().Fatalf(ctx, `%+v`, err)
-		}
+	if err := s.prepare(ctx); err != nil {
+		return err
 	}
-	// Check the group routes again for internally registered routes.
-	s.handlePreBindItems(ctx)
 
-	// If there's no route registered and no static service enabled,
-	// it then returns an error of invalid usage of server.
-	if len(s.routesMap) == 0 && !s.config.FileServerEnabled {
-		return gerror.NewCode(
-			gcode.CodeInvalidOperation,
-			`there's no route set or static feature enabled, did you forget import the router?`,
-		)
-	}
 	// ================================================================================================
 	// Start the HTTP server.
 	// ================================================================================================
@@ -260,13 +198,101 @@ func (s *Server) Start() error {
 			}
 		})
 	}
-	s.initOpenApi()
 	s.doServiceRegister()
 	s.doRouterMapDump()
 
 	return nil
 }
 
+// prepare prepares the server for serving, like registering the routes, initializing the session
+// manager and plugins. It is called by Start and TestClient, and it takes effect only once it succeeds.
+// The group routes registered after the server is prepared are bound each time it is called.
+func (s *Server) prepare(ctx context.Context) error {
+	s.prepareMu.Lock()
+	defer s.prepareMu.Unlock()
+	if s.prepared.Val() {
+		s.handlePreBindItems(ctx)
+		return nil
+	}
+	// The internal routes are bound only once, as it may be prepared again after failure.
+	if !s.internalBound {
+		// Swagger UI.
+		if s.config.SwaggerPath != "" {
+			swaggerui.Init()
+			s.AddStaticPath(s.config.SwaggerPath, swaggerUIPackedPath)
+			s.BindHookHandler(s.config.SwaggerPath+"/*", HookBeforeServe, s.swaggerUI)
+		}
+
+		// OpenApi specification json producing handler.
+		if s.config.OpenApiPath != "" {
+			s.BindHandler(s.config.OpenApiPath, s.openapiSpec)
+		}
+
+		// PProf feature.
+		if s.config.PProfEnabled {
+			s.EnablePProf(s.config.PProfPattern)
+		}
+		s.internalBound = true
+	}
+
+	// Register group routes.
+	s.handlePreBindItems(ctx)
+
+	// Server process initialization, which can only be initialized once.
+	serverProcessInit()
+
+	// Logging path setting check.
+	if s.config.LogPath != "" && s.config.LogPath != s.config.Logger.GetPath() {
+		if err := s.config.Logger.SetPath(s.config.LogPath); err != nil {
+			return err
+		}
+	}
+	// Default session storage.
+	if s.config.SessionStorage == nil {
+		sessionStoragePath := ""
+		if s.config.SessionPath != "" {
+			sessionStoragePath = gfile.Join(s.config.SessionPath, s.config.Name)
+			if !gfile.Exists(sessionStoragePath) {
+				if err := gfile.Mkdir(sessionStoragePath); err != nil {
+					return gerror.Wrapf(err, `mkdir failed for "%s"`, sessionStoragePath)
+				}
+			}
+		}
+		s.config.SessionStorage = gsession.NewStorageFile(sessionStoragePath, s.config.SessionMaxAge)
+	}
+	// Initialize session manager when start running.
+	s.sessionManager = gsession.New(
+		s.config.SessionMaxAge,
+		s.config.SessionStorage,
+	)
+
+	// Default HTTP handler.
+	if s.config.Handler == nil {
+		s.config.Handler = s.ServeHTTP
+	}
+
+	// Install external plugins, the installed ones are not installed again.
+	for ; s.pluginsInstalled < len(s.plugins); s.pluginsInstalled++ {
+		if err := s.plugins[s.pluginsInstalled].Install(s); err != nil {
+			s.Logger().Fatalf(ctx, `%+v`, err)
+		}
+	}
+	// Check the group routes again for internally registered routes.
+	s.handlePreBindItems(ctx)
+
+	// If there's no route registered and no static service enabled,
+	// it then returns an error of invalid usage of server.
+	if len(s.routesMap) == 0 && !s.config.FileServerEnabled {
+		return gerror.NewCode(
+			gcode.CodeInvalidOperation,
+			`there's no route set or static feature enabled, did you forget import the router?`,
+		)
+	}
+	s.initOpenApi()
+	s.prepared.Set(true)
+	return nil
+}
+
 func (s *Server) getLocalListenedAddress() string {
 	return fmt.Sprintf(`http://127.0.0.1:%d`, s.GetListenedPort())
 }
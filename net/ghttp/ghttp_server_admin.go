@@ -101,12 +101,16 @@ func (s *Server) Shutdown() error {
 		}
 	}
 
+	if delay := s.markShuttingDown(); delay > 0 {
+		time.Sleep(delay)
+	}
 	s.doServiceDeregister()
 	// Only shut down current servers.
 	// It may have multiple underlying http servers.
 	for _, v := range s.servers {
 		v.Shutdown(ctx)
 	}
+	s.shutdownTestServer(ctx)
 	s.Logger().Infof(ctx, "pid[%d]: all servers shutdown", gproc.Pid())
 	return nil
 }
@@ -263,15 +263,31 @@ func shutdownWebServersGracefully(ctx context.Context, signal os.Signal) {
 	} else {
 		glog.Printf(ctx, "pid[%d]: server gracefully shutting down by api", gproc.Pid())
 	}
+	// All servers are marked shutting down first, and then it waits the longest readiness delay
+	// only once without locking the server mapping.
+	var (
+		servers  = make([]*Server, 0)
+		maxDelay time.Duration
+	)
 	serverMapping.RLockFunc(func(m map[string]interface{}) {
 		for _, v := range m {
 			server := v.(*Server)
-			server.doServiceDeregister()
-			for _, s := range server.servers {
-				s.Shutdown(ctx)
+			if delay := server.markShuttingDown(); delay > maxDelay {
+				maxDelay = delay
 			}
+			servers = append(servers, server)
 		}
 	})
+	if maxDelay > 0 {
+		time.Sleep(maxDelay)
+	}
+	for _, server := range servers {
+		server.doServiceDeregister()
+		for _, s := range server.servers {
+			s.Shutdown(ctx)
+		}
+		server.shutdownTestServer(ctx)
+	}
 }
 
 // forceCloseWebServers forced shuts down all servers.
@@ -281,6 +297,7 @@ func forceCloseWebServers(ctx context.Context) {
 			for _, s := range v.(*Server).servers {
 				s.Close(ctx)
 			}
+			v.(*Server).closeTestServer()
 		}
 	})
 }
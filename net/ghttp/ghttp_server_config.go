@@ -116,6 +116,23 @@ type ServerConfig struct {
 	// KeepAlive enables HTTP keep-alive.
 	KeepAlive bool `json:"keepAlive"`
 
+	// H2C enables cleartext HTTP/2 (h2c) for HTTP service, which supports both
+	// HTTP/2 with prior knowledge and upgrade from HTTP/1.1 using header "Upgrade: h2c".
+	// It's usually used between services and sidecars that do not use TLS.
+	H2C bool `json:"h2c"`
+
+	// HTTP2MaxConcurrentStreams optionally specifies the number of concurrent
+	// streams that each HTTP/2 client may have open at a time.
+	// It's 250 in default if it is zero.
+	HTTP2MaxConcurrentStreams uint32 `json:"http2MaxConcurrentStreams"`
+
+	// HTTP2MaxReadFrameSize optionally specifies the largest HTTP/2 frame the server
+	// is willing to read, which is valid between 16KB and 16MB.
+	//
+	// It can be configured in configuration file using string like: 16kb, 1m etc.
+	// It's 1MB in default if it is zero.
+	HTTP2MaxReadFrameSize uint32 `json:"http2MaxReadFrameSize"`
+
 	// ServerAgent specifies the server agent information, which is wrote to
 	// HTTP response header as "Server".
 	ServerAgent string `json:"serverAgent"`
@@ -146,6 +163,10 @@ type ServerConfig struct {
 	// StaticPaths specifies URI to directory mapping array.
 	StaticPaths []staticPathItem `json:"staticPaths"`
 
+	// StaticPrecompressed enables serving precompressed sibling files of static files, like "app.js.br",
+	// "app.js.zst" and "app.js.gz", with the encoding negotiated by request header "Accept-Encoding".
+	StaticPrecompressed bool `json:"staticPrecompressed"`
+
 	// FileServerEnabled is the global switch for static service.
 	// It is automatically set enabled if any static path is set.
 	FileServerEnabled bool `json:"fileServerEnabled"`
@@ -215,6 +236,38 @@ type ServerConfig struct {
 	AccessLogEnabled bool         `json:"accessLogEnabled"` // AccessLogEnabled enables access logging content to files.
 	AccessLogPattern string       `json:"accessLogPattern"` // AccessLogPattern specifies the error log file pattern like: access-{Ymd}.log
 
+	// AccessLogFormat specifies the access log format, which can be "combined" for Apache combined format,
+	// "json" for JSON format, or custom template like "{clientIp} {method} {uri} {status} {latency}".
+	// It uses the default format if it is empty.
+	AccessLogFormat string `json:"accessLogFormat"`
+
+	// AccessLogRequestHeaders specifies the request headers that are logged in access log.
+	AccessLogRequestHeaders []string `json:"accessLogRequestHeaders"`
+
+	// AccessLogResponseHeaders specifies the response headers that are logged in access log.
+	AccessLogResponseHeaders []string `json:"accessLogResponseHeaders"`
+
+	// AccessLogRequestBody enables capturing request body in access log.
+	AccessLogRequestBody bool `json:"accessLogRequestBody"`
+
+	// AccessLogResponseBody enables capturing response body in access log.
+	AccessLogResponseBody bool `json:"accessLogResponseBody"`
+
+	// AccessLogBodyLimit specifies the max size in bytes of captured body in access log, it's 4KB in default.
+	AccessLogBodyLimit int64 `json:"accessLogBodyLimit"`
+
+	// AccessLogRedactFields specifies the sensitive fields in captured body that are redacted, using JSON path
+	// like "user.password", in which "*" matches any key. A field without char '.' matches the key in any depth.
+	AccessLogRedactFields []string `json:"accessLogRedactFields"`
+
+	// AccessLogSampleRate specifies the sampling rate of access log in range (0, 1),
+	// like 0.1 logs 10% of the requests. Other values log all the requests.
+	AccessLogSampleRate float64 `json:"accessLogSampleRate"`
+
+	// AccessLogHandler specifies the logging handler for access log, which integrates access log with
+	// custom outputs. The handler can retrieve *AccessLogEntry from HandlerInput.Values for structured fields.
+	AccessLogHandler glog.Handler `json:"-"`
+
 	// ======================================================================================================
 	// PProf.
 	// ======================================================================================================
@@ -230,6 +283,26 @@ type ServerConfig struct {
 	SwaggerPath       string `json:"swaggerPath"`       // SwaggerPath specifies the swagger UI path for route registering.
 	SwaggerUITemplate string `json:"swaggerUITemplate"` // SwaggerUITemplate specifies the swagger UI custom template
 
+	// OpenApiCodecMediaTypes specifies whether the OpenApi specification lists the media types of all
+	// registered codecs for request and response content, instead of only "application/json".
+	OpenApiCodecMediaTypes bool `json:"openapiCodecMediaTypes"`
+
+	// ApiVersionHeader specifies the request header name for API version resolution,
+	// which is "Accept-Version" in default.
+	ApiVersionHeader string `json:"apiVersionHeader"`
+
+	// ApiVersionDefault specifies the API version for requests that specify no version.
+	// It uses the latest registered version if it is empty.
+	ApiVersionDefault string `json:"apiVersionDefault"`
+
+	// ApiVersionFallback enables falling back to the nearest lower version
+	// if the requested version has no route for the request.
+	ApiVersionFallback bool `json:"apiVersionFallback"`
+
+	// ApiVersionDeprecations specifies the deprecated API versions, which respond with
+	// "Deprecation" and "Sunset" headers, and are marked deprecated in OpenApi specification.
+	ApiVersionDeprecations map[string]ApiVersionDeprecation `json:"apiVersionDeprecations"`
+
 	// ======================================================================================================
 	// Graceful reload & shutdown.
 	// ======================================================================================================
@@ -243,6 +316,11 @@ type ServerConfig struct {
 	// GracefulShutdownTimeout set the maximum survival time (seconds) before stopping the server.
 	GracefulShutdownTimeout int `json:"gracefulShutdownTimeout"`
 
+	// HealthShutdownDelay specifies the waiting duration after readiness probe flips to failing
+	// and before the server deregisters from Registry and stops serving when shutting down.
+	// It only makes sense if health endpoints are enabled using EnableHealth.
+	HealthShutdownDelay time.Duration `json:"healthShutdownDelay"`
+
 	// ======================================================================================================
 	// Other.
 	// ======================================================================================================
@@ -258,6 +336,19 @@ type ServerConfig struct {
 	// It's 1MB in default.
 	FormParsingMemory int64 `json:"formParsingMemory"`
 
+	// RequestTimeout specifies the default handling timeout of requests for MiddlewareTimeout,
+	// which can be overwritten by the middleware parameter or meta tag "timeout" of request struct.
+	// It's 0 in default, which means no timeout.
+	RequestTimeout time.Duration `json:"requestTimeout"`
+
+	// Bulkhead specifies the default options of MiddlewareBulkhead,
+	// which can be overwritten by the middleware parameter or meta tag "bulkhead" of request struct.
+	Bulkhead BulkheadOptions `json:"bulkhead"`
+
+	// CircuitBreaker specifies the default options of MiddlewareCircuitBreaker,
+	// which can be overwritten by the middleware parameter or meta tag "circuitBreaker" of request struct.
+	CircuitBreaker CircuitBreakerOptions `json:"circuitBreaker"`
+
 	// NameToUriType specifies the type for converting struct method name to URI when
 	// registering routes.
 	NameToUriType int `json:"nameToUriType"`
@@ -306,7 +397,11 @@ func NewConfig() ServerConfig {
 		ErrorLogPattern:         "error-{Ymd}.log",
 		AccessLogEnabled:        false,
 		AccessLogPattern:        "access-{Ymd}.log",
+		AccessLogBodyLimit:      4096, // 4KB
+		AccessLogRedactFields:   []string{"password", "token"},
+		AccessLogSampleRate:     1,
 		DumpRouterMap:           true,
+		ApiVersionHeader:        "Accept-Version",
 		ClientMaxBodySize:       8 * 1024 * 1024, // 8MB
 		FormParsingMemory:       1024 * 1024,     // 1MB
 		Rewrites:                make(map[string]string),
@@ -337,12 +432,18 @@ func (s *Server) SetConfigWithMap(m map[string]interface{}) error {
 	if k, v := gutil.MapPossibleItemByKey(m, "MaxHeaderBytes"); k != "" {
 		m[k] = gfile.StrToSize(gconv.String(v))
 	}
+	if k, v := gutil.MapPossibleItemByKey(m, "HTTP2MaxReadFrameSize"); k != "" {
+		m[k] = gfile.StrToSize(gconv.String(v))
+	}
 	if k, v := gutil.MapPossibleItemByKey(m, "ClientMaxBodySize"); k != "" {
 		m[k] = gfile.StrToSize(gconv.String(v))
 	}
 	if k, v := gutil.MapPossibleItemByKey(m, "FormParsingMemory"); k != "" {
 		m[k] = gfile.StrToSize(gconv.String(v))
 	}
+	if k, v := gutil.MapPossibleItemByKey(m, "AccessLogBodyLimit"); k != "" {
+		m[k] = gfile.StrToSize(gconv.String(v))
+	}
 	if _, v := gutil.MapPossibleItemByKey(m, "Logger"); v == nil {
 		intlog.Printf(context.TODO(), "SetConfigWithMap: set Logger nil")
 	}
@@ -531,6 +632,21 @@ func (s *Server) SetKeepAlive(enabled bool) {
 	s.config.KeepAlive = enabled
 }
 
+// SetH2C enables/disables cleartext HTTP/2 (h2c) for HTTP service.
+func (s *Server) SetH2C(enabled bool) {
+	s.config.H2C = enabled
+}
+
+// SetHTTP2MaxConcurrentStreams sets the HTTP2MaxConcurrentStreams for the server.
+func (s *Server) SetHTTP2MaxConcurrentStreams(n uint32) {
+	s.config.HTTP2MaxConcurrentStreams = n
+}
+
+// SetHTTP2MaxReadFrameSize sets the HTTP2MaxReadFrameSize for the server.
+func (s *Server) SetHTTP2MaxReadFrameSize(size uint32) {
+	s.config.HTTP2MaxReadFrameSize = size
+}
+
 // SetView sets the View for the server.
 func (s *Server) SetView(view *gview.View) {
 	s.config.View = view
@@ -6,6 +6,15 @@
 
 package ghttp
 
+import "time"
+
+// ApiVersionDeprecation is the deprecation information of API version.
+type ApiVersionDeprecation struct {
+	Date   time.Time `json:"date"`   // Deprecation date, which is responded as "true" if it is zero.
+	Sunset time.Time `json:"sunset"` // Optional date after which the version is unavailable.
+	Link   string    `json:"link"`   // Optional link to the deprecation documents.
+}
+
 // SetSwaggerPath sets the SwaggerPath for server.
 func (s *Server) SetSwaggerPath(path string) {
 	s.config.SwaggerPath = path
@@ -26,3 +35,37 @@ func (s *Server) SetOpenApiPath(path string) {
 func (s *Server) GetOpenApiPath() string {
 	return s.config.OpenApiPath
 }
+
+// SetOpenApiCodecMediaTypes enables/disables listing the media types of all registered codecs
+// in the OpenApi specification.
+func (s *Server) SetOpenApiCodecMediaTypes(enabled bool) {
+	s.config.OpenApiCodecMediaTypes = enabled
+}
+
+// SetApiVersionHeader sets the request header name for API version resolution.
+func (s *Server) SetApiVersionHeader(header string) {
+	s.config.ApiVersionHeader = header
+}
+
+// SetApiVersionDefault sets the API version for requests that specify no version.
+func (s *Server) SetApiVersionDefault(version string) {
+	s.config.ApiVersionDefault = version
+}
+
+// SetApiVersionFallback enables/disables falling back to the nearest lower API version.
+func (s *Server) SetApiVersionFallback(enabled bool) {
+	s.config.ApiVersionFallback = enabled
+}
+
+// SetApiVersionDeprecation marks API `version` deprecated.
+func (s *Server) SetApiVersionDeprecation(version string, deprecation ApiVersionDeprecation) {
+	if s.config.ApiVersionDeprecations == nil {
+		s.config.ApiVersionDeprecations = make(map[string]ApiVersionDeprecation)
+	}
+	s.config.ApiVersionDeprecations[version] = deprecation
+}
+
+// GetApiVersions returns the registered API versions in ascending order.
+func (s *Server) GetApiVersions() []string {
+	return s.apiVersions.Slice()
+}
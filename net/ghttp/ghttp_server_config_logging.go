@@ -51,6 +51,16 @@ func (s *Server) SetAccessLogEnabled(enabled bool) {
 	s.config.AccessLogEnabled = enabled
 }
 
+// SetAccessLogFormat sets the access log format, which can be "combined", "json" or custom template.
+func (s *Server) SetAccessLogFormat(format string) {
+	s.config.AccessLogFormat = format
+}
+
+// SetAccessLogHandler sets the logging handler for access log.
+func (s *Server) SetAccessLogHandler(handler glog.Handler) {
+	s.config.AccessLogHandler = handler
+}
+
 // SetErrorLogEnabled enables/disables the error log.
 func (s *Server) SetErrorLogEnabled(enabled bool) {
 	s.config.ErrorLogEnabled = enabled
@@ -6,6 +6,8 @@
 
 package ghttp
 
+import "time"
+
 // SetNameToUriType sets the NameToUriType for server.
 func (s *Server) SetNameToUriType(t int) {
 	s.config.NameToUriType = t
@@ -27,6 +29,26 @@ func (s *Server) SetFormParsingMemory(maxMemory int64) {
 	s.config.FormParsingMemory = maxMemory
 }
 
+// SetRequestTimeout sets the RequestTimeout for server.
+func (s *Server) SetRequestTimeout(timeout time.Duration) {
+	s.config.RequestTimeout = timeout
+}
+
+// GetRequestTimeout returns the RequestTimeout for server.
+func (s *Server) GetRequestTimeout() time.Duration {
+	return s.config.RequestTimeout
+}
+
+// SetBulkhead sets the default options of MiddlewareBulkhead for server.
+func (s *Server) SetBulkhead(options BulkheadOptions) {
+	s.config.Bulkhead = options
+}
+
+// SetCircuitBreaker sets the default options of MiddlewareCircuitBreaker for server.
+func (s *Server) SetCircuitBreaker(options CircuitBreakerOptions) {
+	s.config.CircuitBreaker = options
+}
+
 // SetGraceful sets the Graceful for server.
 func (s *Server) SetGraceful(graceful bool) {
 	s.config.Graceful = graceful
@@ -47,6 +47,11 @@ func (s *Server) SetFileServerEnabled(enabled bool) {
 	s.config.FileServerEnabled = enabled
 }
 
+// SetStaticPrecompressed enables/disables serving precompressed sibling files of static files.
+func (s *Server) SetStaticPrecompressed(enabled bool) {
+	s.config.StaticPrecompressed = enabled
+}
+
 // SetServerRoot sets the document root for static service.
 func (s *Server) SetServerRoot(root string) {
 	var (
@@ -14,15 +14,18 @@ func (s *Server) newGracefulServer(address string, fd int) *graceful.Server {
 	var (
 		loggerWriter = &errorLogger{logger: s.config.Logger}
 		serverConfig = graceful.ServerConfig{
-			Listeners:               s.config.Listeners,
-			Handler:                 s.config.Handler,
-			ReadTimeout:             s.config.ReadTimeout,
-			WriteTimeout:            s.config.WriteTimeout,
-			IdleTimeout:             s.config.IdleTimeout,
-			GracefulShutdownTimeout: s.config.GracefulTimeout,
-			MaxHeaderBytes:          s.config.MaxHeaderBytes,
-			KeepAlive:               s.config.KeepAlive,
-			Logger:                  s.config.Logger,
+			Listeners:                 s.config.Listeners,
+			Handler:                   s.config.Handler,
+			ReadTimeout:               s.config.ReadTimeout,
+			WriteTimeout:              s.config.WriteTimeout,
+			IdleTimeout:               s.config.IdleTimeout,
+			GracefulShutdownTimeout:   s.config.GracefulTimeout,
+			MaxHeaderBytes:            s.config.MaxHeaderBytes,
+			KeepAlive:                 s.config.KeepAlive,
+			H2C:                       s.config.H2C,
+			HTTP2MaxConcurrentStreams: s.config.HTTP2MaxConcurrentStreams,
+			HTTP2MaxReadFrameSize:     s.config.HTTP2MaxReadFrameSize,
+			Logger:                    s.config.Logger,
 		}
 	)
 	return graceful.New(address, fd, loggerWriter, serverConfig)
@@ -41,9 +41,15 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var (
+		accessLog = s.initAccessLog(r)     // Access log capture, which might wrap the request body.
 		request   = newRequest(s, r, w)    // Create a new request object.
 		sessionId = request.GetSessionId() // Get sessionId before user handler
 	)
+	request.accessLog = accessLog
+	// The request is dispatched by TestClient.
+	if record, ok := r.Context().Value(testRecordCtxKey{}).(*TestRecord); ok {
+		record.Request = request
+	}
 	defer s.handleAfterRequestDone(request)
 
 	// ============================================================
@@ -71,6 +77,11 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		request.isFileRequest = false
 	}
 
+	// Deprecation headers for deprecated API version.
+	if request.hasServeHandler && !request.isFileRequest {
+		s.setApiVersionDeprecationHeaders(request)
+	}
+
 	// Metrics.
 	s.handleMetricsBeforeRequest(request)
 
@@ -163,6 +174,10 @@ func (s *Server) handleResponse(request *Request, sessionId string) {
 			}
 		}
 	}
+	// Functions with the final response.
+	for _, f := range request.outputFuncs {
+		f()
+	}
 	// Output the cookie content to the client.
 	request.Cookie.Flush()
 	// Output the buffer content to the client.
@@ -295,6 +310,12 @@ func (s *Server) serveFile(r *Request, f *staticFile, allowIndex ...bool) {
 			}
 		} else {
 			info := f.File.FileInfo()
+			if s.config.StaticPrecompressed {
+				if file := s.searchPrecompressedResource(r, f.File.Name()); file != nil {
+					r.Response.ServeContent(info.Name(), file.FileInfo().ModTime(), file)
+					return
+				}
+			}
 			r.Response.ServeContent(info.Name(), info.ModTime(), f.File)
 		}
 		return
@@ -321,10 +342,67 @@ func (s *Server) serveFile(r *Request, f *staticFile, allowIndex ...bool) {
 			r.Response.WriteStatus(http.StatusForbidden)
 		}
 	} else {
+		if s.config.StaticPrecompressed {
+			if path := s.searchPrecompressedFile(r, f.Path); path != "" {
+				if compressedFile, err := os.Open(path); err == nil {
+					defer compressedFile.Close()
+					if compressedInfo, err := compressedFile.Stat(); err == nil {
+						r.Response.ServeContent(info.Name(), compressedInfo.ModTime(), compressedFile)
+						return
+					}
+				}
+				r.Response.Header().Del("Content-Encoding")
+			}
+		}
 		r.Response.ServeContent(info.Name(), info.ModTime(), file)
 	}
 }
 
+// searchPrecompressedFile searches and returns the path of precompressed sibling file of `path`
+// with the encoding negotiated by the request, and sets the response headers for the encoding.
+func (s *Server) searchPrecompressedFile(r *Request, path string) string {
+	encoding := s.negotiatePrecompressed(r, func(ext string) bool {
+		return gfile.IsFile(path + ext)
+	})
+	if encoding == "" {
+		return ""
+	}
+	return path + precompressedExtensions[encoding]
+}
+
+// searchPrecompressedResource searches and returns the precompressed sibling resource file of `name`
+// with the encoding negotiated by the request, and sets the response headers for the encoding.
+func (s *Server) searchPrecompressedResource(r *Request, name string) *gres.File {
+	encoding := s.negotiatePrecompressed(r, func(ext string) bool {
+		return gres.Contains(name + ext)
+	})
+	if encoding == "" {
+		return nil
+	}
+	return gres.Get(name + precompressedExtensions[encoding])
+}
+
+// negotiatePrecompressed negotiates the encoding among the existing precompressed sibling files,
+// which are checked by `exists` with file extension of the encoding.
+func (s *Server) negotiatePrecompressed(r *Request, exists func(ext string) bool) string {
+	var encodings = make([]string, 0, len(defaultCompressEncodings))
+	for _, encoding := range defaultCompressEncodings {
+		if exists(precompressedExtensions[encoding]) {
+			encodings = append(encodings, encoding)
+		}
+	}
+	if len(encodings) == 0 {
+		return ""
+	}
+	header := r.Response.Header()
+	addVaryHeader(header, "Accept-Encoding")
+	encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"), encodings, false)
+	if encoding != "" {
+		header.Set("Content-Encoding", encoding)
+	}
+	return encoding
+}
+
 // listDir lists the sub files of specified directory as HTML content to the client.
 func (s *Server) listDir(r *Request, f http.File) {
 	files, err := f.Readdir(-1)
@@ -0,0 +1,308 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package ghttp
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gogf/gf/v2/database/gredis"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/net/gsvc"
+)
+
+// HealthChecker is the interface for component that contributes health checking.
+type HealthChecker interface {
+	// CheckHealth checks and returns the health status of the component.
+	// It returns nil if the component is healthy.
+	CheckHealth(ctx context.Context) error
+}
+
+// HealthCheckFunc is the function implementing HealthChecker.
+type HealthCheckFunc func(ctx context.Context) error
+
+// HealthCheckOption is the option for health checker registering.
+type HealthCheckOption struct {
+	// Timeout specifies the maximum duration of single checking, it's 3 seconds in default.
+	Timeout time.Duration
+	// Liveness specifies whether the checker also affects liveness probe.
+	// Checkers only affect readiness probe in default, as a failing dependency
+	// should not commonly cause the process restarting.
+	Liveness bool
+}
+
+// HealthStatus is the aggregated health checking result.
+type HealthStatus struct {
+	Status string                       `json:"status"`           // Aggregated status, "up" or "down".
+	Checks map[string]HealthCheckStatus `json:"checks,omitempty"` // Result of each checker.
+}
+
+// HealthCheckStatus is the checking result of single checker.
+type HealthCheckStatus struct {
+	Status   string `json:"status"`          // Checking status, "up" or "down".
+	Duration string `json:"duration"`        // Checking cost duration.
+	Error    string `json:"error,omitempty"` // Error message if checking fails.
+}
+
+// healthCheckItem is the registered item for health checker.
+type healthCheckItem struct {
+	Name    string
+	Checker HealthChecker
+	Option  HealthCheckOption
+}
+
+// utilHealth is the health endpoints implementer.
+type utilHealth struct {
+	server *Server
+}
+
+const (
+	HealthStatusUp   = "up"   // HealthStatusUp marks the component or service healthy.
+	HealthStatusDown = "down" // HealthStatusDown marks the component or service unhealthy.
+
+	defaultHealthPattern      = "/"
+	defaultHealthCheckTimeout = 3 * time.Second
+	healthCheckNameShutdown   = "shutdown"
+)
+
+// CheckHealth implements interface HealthChecker.
+func (f HealthCheckFunc) CheckHealth(ctx context.Context) error {
+	return f(ctx)
+}
+
+// EnableHealth enables health probing endpoints for server.
+// The optional parameter `pattern` specifies the URI prefix for the endpoints, which is "/" in default.
+//
+// It registers the following endpoints:
+// /healthz: all registered checkers.
+// /livez  : checkers marked as liveness.
+// /readyz : all registered checkers, and it fails as soon as the server starts shutting down.
+//
+// It responses HTTP status 200 with aggregated JSON result if healthy, or else 503.
+func (s *Server) EnableHealth(pattern ...string) {
+	p := defaultHealthPattern
+	if len(pattern) > 0 && pattern[0] != "" {
+		p = pattern[0]
+	}
+	uh := &utilHealth{server: s}
+	_, _, uri, _ := s.parsePattern(p)
+	uri = strings.TrimRight(uri, "/")
+	s.Group(uri, func(group *RouterGroup) {
+		group.GET("/healthz", uh.Health)
+		group.GET("/livez", uh.Liveness)
+		group.GET("/readyz", uh.Readiness)
+	})
+	s.healthEnabled.Set(true)
+}
+
+// AddHealthChecker registers a named checker for health probing.
+// It overwrites the existing checker if `name` is already registered.
+func (s *Server) AddHealthChecker(name string, checker HealthChecker, option ...HealthCheckOption) {
+	item := &healthCheckItem{
+		Name:    name,
+		Checker: checker,
+	}
+	if len(option) > 0 {
+		item.Option = option[0]
+	}
+	if item.Option.Timeout <= 0 {
+		item.Option.Timeout = defaultHealthCheckTimeout
+	}
+	s.healthMu.Lock()
+	defer s.healthMu.Unlock()
+	for i, v := range s.healthCheckers {
+		if v.Name == name {
+			s.healthCheckers[i] = item
+			return
+		}
+	}
+	s.healthCheckers = append(s.healthCheckers, item)
+}
+
+// RemoveHealthChecker removes the checker with given `name`.
+func (s *Server) RemoveHealthChecker(name string) {
+	s.healthMu.Lock()
+	defer s.healthMu.Unlock()
+	for i, v := range s.healthCheckers {
+		if v.Name == name {
+			s.healthCheckers = append(s.healthCheckers[:i], s.healthCheckers[i+1:]...)
+			return
+		}
+	}
+}
+
+// CheckHealth runs the registered checkers concurrently and returns the aggregated result.
+// It only runs liveness checkers if `livenessOnly` is true.
+func (s *Server) CheckHealth(ctx context.Context, livenessOnly bool) HealthStatus {
+	s.healthMu.RLock()
+	items := make([]*healthCheckItem, 0, len(s.healthCheckers))
+	for _, item := range s.healthCheckers {
+		if livenessOnly && !item.Option.Liveness {
+			continue
+		}
+		items = append(items, item)
+	}
+	s.healthMu.RUnlock()
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		result = HealthStatus{
+			Status: HealthStatusUp,
+			Checks: make(map[string]HealthCheckStatus, len(items)),
+		}
+	)
+	for _, item := range items {
+		wg.Add(1)
+		go func(item *healthCheckItem) {
+			defer wg.Done()
+			var (
+				startTime = time.Now()
+				err       = doHealthCheck(ctx, item)
+				status    = HealthCheckStatus{
+					Status:   HealthStatusUp,
+					Duration: time.Since(startTime).String(),
+				}
+			)
+			if err != nil {
+				status.Status = HealthStatusDown
+				status.Error = err.Error()
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			result.Checks[item.Name] = status
+			if err != nil {
+				result.Status = HealthStatusDown
+			}
+		}(item)
+	}
+	wg.Wait()
+	return result
+}
+
+// IsShuttingDown checks and returns whether the server is shutting down.
+func (s *Server) IsShuttingDown() bool {
+	return s.shuttingDown.Val()
+}
+
+// markShuttingDown marks the server shutting down, which makes readiness probe fail.
+// It returns the configured readiness delay that the caller should wait, which makes the orchestration
+// system having chance removing the server from load balancing before it deregisters from
+// the Registry and stops serving. It returns 0 if the server is already marked.
+func (s *Server) markShuttingDown() time.Duration {
+	if !s.shuttingDown.Cas(false, true) {
+		return 0
+	}
+	if s.config.HealthShutdownDelay > 0 && s.healthEnabled.Val() {
+		return s.config.HealthShutdownDelay
+	}
+	return 0
+}
+
+// doHealthCheck runs single checker with timeout.
+func doHealthCheck(ctx context.Context, item *healthCheckItem) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, item.Option.Timeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if exception := recover(); exception != nil {
+				done <- gerror.NewCodef(gcode.CodeInternalPanic, "%+v", exception)
+			}
+		}()
+		done <- item.Checker.CheckHealth(ctx)
+	}()
+	select {
+	case err = <-done:
+		return err
+	case <-ctx.Done():
+		return gerror.WrapCodef(gcode.CodeOperationFailed, ctx.Err(), `health check "%s" timeout`, item.Name)
+	}
+}
+
+// Health handles the overall health probing.
+func (h *utilHealth) Health(r *Request) {
+	h.writeStatus(r, h.server.CheckHealth(r.Context(), false))
+}
+
+// Liveness handles the liveness probing.
+func (h *utilHealth) Liveness(r *Request) {
+	h.writeStatus(r, h.server.CheckHealth(r.Context(), true))
+}
+
+// Readiness handles the readiness probing.
+func (h *utilHealth) Readiness(r *Request) {
+	if h.server.IsShuttingDown() {
+		h.writeStatus(r, HealthStatus{
+			Status: HealthStatusDown,
+			Checks: map[string]HealthCheckStatus{
+				healthCheckNameShutdown: {
+					Status: HealthStatusDown,
+					Error:  "server is shutting down",
+				},
+			},
+		})
+		return
+	}
+	h.writeStatus(r, h.server.CheckHealth(r.Context(), false))
+}
+
+func (h *utilHealth) writeStatus(r *Request, status HealthStatus) {
+	r.Response.Header().Set("Cache-Control", "no-store")
+	if status.Status != HealthStatusUp {
+		r.Response.WriteHeader(http.StatusServiceUnavailable)
+	}
+	r.Response.WriteJson(status)
+}
+
+// HealthCheckerDB creates and returns a HealthChecker for database, which checks the
+// health by pinging the master node with the probe context. The parameter `db` is commonly a gdb.DB object.
+func HealthCheckerDB(db interface {
+	Master(schema ...string) (*sql.DB, error)
+}) HealthChecker {
+	return HealthCheckFunc(func(ctx context.Context) error {
+		master, err := db.Master()
+		if err != nil {
+			return err
+		}
+		if err = master.PingContext(ctx); err != nil {
+			return gerror.WrapCode(gcode.CodeDbOperationError, err, `master.Ping failed`)
+		}
+		return nil
+	})
+}
+
+// HealthCheckerRedis creates and returns a HealthChecker for redis, which checks the
+// health using command PING.
+func HealthCheckerRedis(redis *gredis.Redis) HealthChecker {
+	return HealthCheckFunc(func(ctx context.Context) error {
+		_, err := redis.Do(ctx, "PING")
+		return err
+	})
+}
+
+// HealthCheckerService creates and returns a HealthChecker for service registered in Registry,
+// which checks that there's at least one available instance of service `name`.
+func HealthCheckerService(name string) HealthChecker {
+	return HealthCheckFunc(func(ctx context.Context) error {
+		services, err := gsvc.Search(ctx, gsvc.SearchInput{Name: name})
+		if err != nil {
+			return err
+		}
+		for _, service := range services {
+			if len(service.GetEndpoints()) > 0 {
+				return nil
+			}
+		}
+		return gerror.NewCodef(gcode.CodeNotFound, `no available instance found for service "%s"`, name)
+	})
+}
@@ -20,24 +20,27 @@ func (s *Server) handleAccessLog(r *Request) {
 	if !s.IsAccessLogEnabled() {
 		return
 	}
-	var (
-		scheme            = r.GetSchema()
-		loggerInstanceKey = fmt.Sprintf(`Acccess Logger Of Server:%s`, s.instance)
-	)
-	content := fmt.Sprintf(
-		`%d "%s %s %s %s %s" %.3f, %s, "%s", "%s"`,
-		r.Response.Status, r.Method, scheme, r.Host, r.URL.String(), r.Proto,
-		float64(r.LeaveTime.Sub(r.EnterTime).Milliseconds())/1000,
-		r.GetClientIp(), r.Referer(), r.UserAgent(),
-	)
+	// The request is not sampled.
+	if r.accessLog == nil {
+		return
+	}
+	var loggerInstanceKey = fmt.Sprintf(`Acccess Logger Of Server:%s`, s.instance)
 	logger := instance.GetOrSetFuncLock(loggerInstanceKey, func() interface{} {
-		l := s.Logger()
+		l := s.Logger().Clone()
 		l.SetFile(s.config.AccessLogPattern)
 		l.SetStdoutPrint(s.config.LogStdout)
 		l.SetLevelPrint(false)
+		switch s.config.AccessLogFormat {
+		case AccessLogFormatCombined, AccessLogFormatJson:
+			// These formats contain the time field themselves.
+			l.SetHeaderPrint(false)
+		}
+		if s.config.AccessLogHandler != nil {
+			l.SetHandlers(s.config.AccessLogHandler)
+		}
 		return l
 	}).(*glog.Logger)
-	logger.Print(r.Context(), content)
+	logger.Print(r.Context(), s.newAccessLogEntry(r))
 }
 
 // handleErrorLog handles the error logging for server.
@@ -0,0 +1,460 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package ghttp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gogf/gf/v2/internal/json"
+	"github.com/gogf/gf/v2/util/grand"
+)
+
+// AccessLogEntry is the structured fields of an access log.
+//
+// It is passed as the logging value to the access logger, so a custom glog.Handler configured by
+// ServerConfig.AccessLogHandler can retrieve it from HandlerInput.Values for structured outputs.
+type AccessLogEntry struct {
+	Time            time.Time         // Time entering the request.
+	Status          int               // Response status.
+	Method          string            // Request method.
+	Scheme          string            // Request scheme, http or https.
+	Host            string            // Request host.
+	Uri             string            // Request URI with query string.
+	Proto           string            // Request protocol, like HTTP/1.1.
+	Latency         time.Duration     // Duration of serving the request.
+	ClientIp        string            // Client ip.
+	Referer         string            // Request header Referer.
+	UserAgent       string            // Request header User-Agent.
+	Bytes           int64             // Size of response body.
+	RequestHeaders  map[string]string // Configured request headers.
+	ResponseHeaders map[string]string // Configured response headers.
+	RequestBody     string            // Captured and redacted request body.
+	ResponseBody    string            // Captured and redacted response body.
+	format          string            // Format of the access log.
+}
+
+const (
+	// AccessLogFormatCombined is the Apache combined log format.
+	AccessLogFormatCombined = "combined"
+	// AccessLogFormatJson is the JSON log format, which outputs one JSON object per line.
+	AccessLogFormatJson = "json"
+
+	accessLogRedactedValue      = "***"
+	defaultAccessLogBodyLimit   = 4096
+	accessLogCombinedTimeFormat = "02/Jan/2006:15:04:05 -0700"
+)
+
+// accessLogTemplateRegex matches the placeholders in custom access log template.
+var accessLogTemplateRegex = regexp.MustCompile(`\{([\w\.\-]+)\}`)
+
+// accessLogCapture holds the captured content of a sampled request for access logging.
+type accessLogCapture struct {
+	requestBody  *accessLogBodyBuffer // Captured request body, which is nil if not enabled.
+	responseBody *accessLogBodyBuffer // Captured response body, which is nil if not enabled.
+}
+
+// accessLogBodyBuffer captures the body content up to limit size.
+type accessLogBodyBuffer struct {
+	buffer    bytes.Buffer
+	limit     int64
+	truncated bool
+}
+
+// accessLogBodyReader is the request body reader that captures the read content.
+type accessLogBodyReader struct {
+	io.ReadCloser
+	capture *accessLogBodyBuffer
+}
+
+// initAccessLog initializes the access log capture for request `r`, which wraps the request body
+// for capturing if necessary. It does nothing if the access log is disabled or the request is not sampled.
+func (s *Server) initAccessLog(r *http.Request) *accessLogCapture {
+	if !s.IsAccessLogEnabled() {
+		return nil
+	}
+	if rate := s.config.AccessLogSampleRate; rate > 0 && rate < 1 && grand.Intn(1000000) >= int(rate*1000000) {
+		return nil
+	}
+	var (
+		capture = &accessLogCapture{}
+		limit   = s.config.AccessLogBodyLimit
+	)
+	if limit <= 0 {
+		limit = defaultAccessLogBodyLimit
+	}
+	if s.config.AccessLogRequestBody && r.Body != nil && r.Body != http.NoBody {
+		capture.requestBody = &accessLogBodyBuffer{limit: limit}
+		r.Body = &accessLogBodyReader{ReadCloser: r.Body, capture: capture.requestBody}
+	}
+	if s.config.AccessLogResponseBody {
+		capture.responseBody = &accessLogBodyBuffer{limit: limit}
+	}
+	return capture
+}
+
+// newAccessLogEntry creates and returns the access log entry of request `r`.
+func (s *Server) newAccessLogEntry(r *Request) *AccessLogEntry {
+	entry := &AccessLogEntry{
+		Time:      r.EnterTime.Time,
+		Status:    r.Response.Status,
+		Method:    r.Method,
+		Scheme:    r.GetSchema(),
+		Host:      r.Host,
+		Uri:       r.URL.String(),
+		Proto:     r.Proto,
+		Latency:   r.LeaveTime.Sub(r.EnterTime),
+		ClientIp:  r.GetClientIp(),
+		Referer:   r.Referer(),
+		UserAgent: r.UserAgent(),
+		Bytes:     r.Response.BytesWritten(),
+		format:    s.config.AccessLogFormat,
+	}
+	if len(s.config.AccessLogRequestHeaders) > 0 {
+		entry.RequestHeaders = make(map[string]string)
+		for _, name := range s.config.AccessLogRequestHeaders {
+			if v := r.Header.Get(name); v != "" {
+				entry.RequestHeaders[name] = v
+			}
+		}
+	}
+	if len(s.config.AccessLogResponseHeaders) > 0 {
+		entry.ResponseHeaders = make(map[string]string)
+		for _, name := range s.config.AccessLogResponseHeaders {
+			if v := r.Response.Header().Get(name); v != "" {
+				entry.ResponseHeaders[name] = v
+			}
+		}
+	}
+	if capture := r.accessLog; capture != nil {
+		if capture.requestBody != nil {
+			entry.RequestBody = capture.requestBody.content(
+				r.Header.Get("Content-Type"), s.config.AccessLogRedactFields,
+			)
+		}
+		if capture.responseBody != nil {
+			entry.ResponseBody = capture.responseBody.content(
+				r.Response.Header().Get("Content-Type"), s.config.AccessLogRedactFields,
+			)
+		}
+	}
+	return entry
+}
+
+// String formats the access log entry according to its format.
+// It implements interface fmt.Stringer, which is used by the logger for content output.
+func (e *AccessLogEntry) String() string {
+	switch e.format {
+	case "":
+		return fmt.Sprintf(
+			`%d "%s %s %s %s %s" %.3f, %s, "%s", "%s"`,
+			e.Status, e.Method, e.Scheme, e.Host, e.Uri, e.Proto,
+			float64(e.Latency.Milliseconds())/1000,
+			e.ClientIp, e.Referer, e.UserAgent,
+		) + e.extraString()
+
+	case AccessLogFormatCombined:
+		var size = "-"
+		if e.Bytes > 0 {
+			size = strconv.FormatInt(e.Bytes, 10)
+		}
+		return fmt.Sprintf(
+			`%s - - [%s] "%s %s %s" %d %s "%s" "%s"`,
+			e.ClientIp, e.Time.Format(accessLogCombinedTimeFormat), e.Method, e.Uri, e.Proto,
+			e.Status, size, e.Referer, e.UserAgent,
+		) + e.extraString()
+
+	case AccessLogFormatJson:
+		b, _ := json.Marshal(e.Map())
+		return string(b)
+
+	default:
+		return accessLogTemplateRegex.ReplaceAllStringFunc(e.format, func(s string) string {
+			return e.templateValue(s[1 : len(s)-1])
+		})
+	}
+}
+
+// Map returns the fields of the entry as map, in which the latency is in seconds.
+func (e *AccessLogEntry) Map() map[string]interface{} {
+	m := map[string]interface{}{
+		"time":      e.Time.Format(time.RFC3339Nano),
+		"status":    e.Status,
+		"method":    e.Method,
+		"scheme":    e.Scheme,
+		"host":      e.Host,
+		"uri":       e.Uri,
+		"proto":     e.Proto,
+		"latency":   e.Latency.Seconds(),
+		"clientIp":  e.ClientIp,
+		"referer":   e.Referer,
+		"userAgent": e.UserAgent,
+		"bytes":     e.Bytes,
+	}
+	if len(e.RequestHeaders) > 0 {
+		m["requestHeaders"] = e.RequestHeaders
+	}
+	if len(e.ResponseHeaders) > 0 {
+		m["responseHeaders"] = e.ResponseHeaders
+	}
+	if e.RequestBody != "" {
+		m["requestBody"] = e.RequestBody
+	}
+	if e.ResponseBody != "" {
+		m["responseBody"] = e.ResponseBody
+	}
+	return m
+}
+
+// MarshalJSON implements the interface MarshalJSON for json.Marshal, which uses the fields of Map.
+func (e *AccessLogEntry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.Map())
+}
+
+// templateValue returns the value of placeholder `name` in custom template.
+// The headers can be referred by `requestHeader.{Name}` and `responseHeader.{Name}`.
+func (e *AccessLogEntry) templateValue(name string) string {
+	switch {
+	case strings.HasPrefix(name, "requestHeader."):
+		return e.RequestHeaders[strings.TrimPrefix(name, "requestHeader.")]
+	case strings.HasPrefix(name, "responseHeader."):
+		return e.ResponseHeaders[strings.TrimPrefix(name, "responseHeader.")]
+	case name == "latency":
+		return fmt.Sprintf(`%.3f`, e.Latency.Seconds())
+	case name == "time":
+		return e.Time.Format(time.RFC3339)
+	}
+	if v, ok := e.Map()[name]; ok {
+		return fmt.Sprint(v)
+	}
+	return "{" + name + "}"
+}
+
+// extraString returns the configured headers and captured bodies as string for text formats.
+func (e *AccessLogEntry) extraString() string {
+	var buffer bytes.Buffer
+	for _, headers := range []map[string]string{e.RequestHeaders, e.ResponseHeaders} {
+		for name, value := range headers {
+			buffer.WriteString(fmt.Sprintf(`, %s=%s`, name, strconv.Quote(value)))
+		}
+	}
+	if e.RequestBody != "" {
+		buffer.WriteString(`, requestBody=` + strconv.Quote(e.RequestBody))
+	}
+	if e.ResponseBody != "" {
+		buffer.WriteString(`, responseBody=` + strconv.Quote(e.ResponseBody))
+	}
+	return buffer.String()
+}
+
+// Read implements interface io.Reader.
+func (r *accessLogBodyReader) Read(p []byte) (n int, err error) {
+	n, err = r.ReadCloser.Read(p)
+	if n > 0 {
+		_, _ = r.capture.Write(p[:n])
+	}
+	return
+}
+
+// Write implements interface io.Writer, which only captures content up to the limit.
+func (b *accessLogBodyBuffer) Write(p []byte) (int, error) {
+	if left := b.limit - int64(b.buffer.Len()); left < int64(len(p)) {
+		b.truncated = true
+		if left > 0 {
+			b.buffer.Write(p[:left])
+		}
+		return len(p), nil
+	}
+	return b.buffer.Write(p)
+}
+
+// content returns the captured content with sensitive fields redacted.
+// The binary content like multipart files are not captured.
+func (b *accessLogBodyBuffer) content(contentType string, redactFields []string) string {
+	if b.buffer.Len() == 0 {
+		return ""
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if strings.HasPrefix(mediaType, "multipart/") || mediaType == "application/octet-stream" ||
+		mediaType == tusContentType {
+		return "[binary]"
+	}
+	var content = b.buffer.String()
+	if len(redactFields) > 0 {
+		content = redactAccessLogBody(content, mediaType, redactFields)
+	}
+	if b.truncated {
+		content += "..."
+	}
+	return content
+}
+
+// redactAccessLogBody replaces the values of `fields` in `content` with redacted value.
+//
+// The field is a JSON path like `user.password`, in which `*` matches any key, the array is transparent
+// in the path. A field without char '.' matches the key in any depth.
+func redactAccessLogBody(content, mediaType string, fields []string) string {
+	if mediaType == "application/x-www-form-urlencoded" {
+		if values, err := url.ParseQuery(content); err == nil {
+			for key := range values {
+				if matchAccessLogRedactField([]string{key}, fields) {
+					values.Set(key, accessLogRedactedValue)
+				}
+			}
+			return values.Encode()
+		}
+	}
+	var data interface{}
+	if err := json.UnmarshalUseNumber([]byte(content), &data); err == nil {
+		if b, err := json.Marshal(redactAccessLogValue(data, nil, fields)); err == nil {
+			return string(b)
+		}
+	}
+	// The content might be truncated or not JSON, it then redacts the JSON-like values of any type
+	// with the last key of the fields.
+	for _, field := range fields {
+		var (
+			array = strings.Split(field, ".")
+			key   = array[len(array)-1]
+		)
+		if key == "*" {
+			// The values of wildcard field cannot be located, it drops the whole JSON-like content.
+			if trimmed := strings.TrimSpace(content); strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+				return accessLogRedactedValue
+			}
+			continue
+		}
+		re, err := regexp.Compile(`"` + regexp.QuoteMeta(key) + `"\s*:\s*`)
+		if err != nil {
+			continue
+		}
+		var (
+			buffer = bytes.NewBuffer(nil)
+			last   = 0
+		)
+		for _, match := range re.FindAllStringIndex(content, -1) {
+			if match[0] < last {
+				continue
+			}
+			buffer.WriteString(content[last:match[1]])
+			buffer.WriteString(`"` + accessLogRedactedValue + `"`)
+			last = skipAccessLogJsonValue(content, match[1])
+		}
+		buffer.WriteString(content[last:])
+		content = buffer.String()
+	}
+	return content
+}
+
+// skipAccessLogJsonValue returns the end position of the JSON value starting at `pos` in `content`,
+// which is the end of content if the value is truncated.
+func skipAccessLogJsonValue(content string, pos int) int {
+	if pos >= len(content) {
+		return pos
+	}
+	switch content[pos] {
+	case '"':
+		for i := pos + 1; i < len(content); i++ {
+			switch content[i] {
+			case '\\':
+				i++
+			case '"':
+				return i + 1
+			}
+		}
+		return len(content)
+
+	case '{', '[':
+		var (
+			depth    = 0
+			inString = false
+		)
+		for i := pos; i < len(content); i++ {
+			c := content[i]
+			if inString {
+				if c == '\\' {
+					i++
+				} else if c == '"' {
+					inString = false
+				}
+				continue
+			}
+			switch c {
+			case '"':
+				inString = true
+			case '{', '[':
+				depth++
+			case '}', ']':
+				if depth--; depth == 0 {
+					return i + 1
+				}
+			}
+		}
+		return len(content)
+
+	default:
+		if i := strings.IndexAny(content[pos:], ",}] \t\r\n"); i >= 0 {
+			return pos + i
+		}
+		return len(content)
+	}
+}
+
+// redactAccessLogValue redacts `value` recursively, the `path` is the key path of `value`.
+func redactAccessLogValue(value interface{}, path []string, fields []string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			itemPath := append(path[:len(path):len(path)], key)
+			if matchAccessLogRedactField(itemPath, fields) {
+				v[key] = accessLogRedactedValue
+			} else {
+				v[key] = redactAccessLogValue(item, itemPath, fields)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactAccessLogValue(item, path, fields)
+		}
+	}
+	return value
+}
+
+// matchAccessLogRedactField checks whether key path `path` matches any of `fields`.
+func matchAccessLogRedactField(path []string, fields []string) bool {
+	for _, field := range fields {
+		if !strings.Contains(field, ".") {
+			if strings.EqualFold(field, path[len(path)-1]) {
+				return true
+			}
+			continue
+		}
+		array := strings.Split(field, ".")
+		if len(array) != len(path) {
+			continue
+		}
+		matched := true
+		for i, key := range array {
+			if key != "*" && !strings.EqualFold(key, path[i]) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
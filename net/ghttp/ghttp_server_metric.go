@@ -23,6 +23,10 @@ type localMetricManager struct {
 	HttpServerRequestDurationTotal gmetric.Counter
 	HttpServerRequestBodySize      gmetric.Counter
 	HttpServerResponseBodySize     gmetric.Counter
+
+	HttpServerBulkheadRejected       gmetric.Counter
+	HttpServerCircuitBreakerRejected gmetric.Counter
+	HttpServerCircuitBreakerState    gmetric.UpDownCounter
 }
 
 const (
@@ -114,6 +118,30 @@ func newMetricManager() *localMetricManager {
 				Attributes: gmetric.Attributes{},
 			},
 		),
+		HttpServerBulkheadRejected: meter.MustCounter(
+			"http.server.bulkhead.rejected",
+			gmetric.MetricOption{
+				Help:       "Total request number rejected by bulkhead.",
+				Unit:       "",
+				Attributes: gmetric.Attributes{},
+			},
+		),
+		HttpServerCircuitBreakerRejected: meter.MustCounter(
+			"http.server.circuit_breaker.rejected",
+			gmetric.MetricOption{
+				Help:       "Total request number rejected by circuit breaker.",
+				Unit:       "",
+				Attributes: gmetric.Attributes{},
+			},
+		),
+		HttpServerCircuitBreakerState: meter.MustUpDownCounter(
+			"http.server.circuit_breaker.state",
+			gmetric.MetricOption{
+				Help:       "State of circuit breaker of route, 0: closed, 1: open, 2: half-open.",
+				Unit:       "",
+				Attributes: gmetric.Attributes{},
+			},
+		),
 	}
 	return mm
 }
@@ -127,6 +155,17 @@ func (m *localMetricManager) GetMetricOptionForRequestDurationByMap(attrMap gmet
 	}
 }
 
+func (m *localMetricManager) GetMetricOptionForCircuitBreaker(r *Request) gmetric.Option {
+	return gmetric.Option{
+		Attributes: m.GetMetricAttributeMap(r).Pick(
+			metricAttrKeyServerAddress,
+			metricAttrKeyServerPort,
+			metricAttrKeyHttpRoute,
+			metricAttrKeyHttpRequestMethod,
+		),
+	}
+}
+
 func (m *localMetricManager) GetMetricOptionForRequest(r *Request) gmetric.Option {
 	attrMap := m.GetMetricAttributeMap(r)
 	return m.GetMetricOptionForRequestByMap(attrMap)
@@ -8,6 +8,7 @@ package ghttp
 
 import (
 	"context"
+	"net/http"
 
 	"github.com/gogf/gf/v2/net/goai"
 	"github.com/gogf/gf/v2/text/gstr"
@@ -23,35 +24,104 @@ func (s *Server) initOpenApi() {
 		err     error
 		methods []string
 	)
+	// It uses the media types of registered codecs if enabled and there's no custom configuration.
+	if s.config.OpenApiCodecMediaTypes {
+		if isDefaultOpenApiContentTypes(s.openapi.Config.ReadContentTypes) {
+			s.openapi.Config.ReadContentTypes = SupportedMediaTypes()
+		}
+		if isDefaultOpenApiContentTypes(s.openapi.Config.WriteContentTypes) {
+			s.openapi.Config.WriteContentTypes = SupportedMediaTypes()
+		}
+	}
+	// Each API version has its own specification, which is derived from the server one.
+	for _, version := range s.apiVersions.Slice() {
+		s.openapiVersions[version] = s.newVersionOpenApi(version)
+	}
 	for _, item := range s.GetRoutes() {
 		switch item.Type {
 		case HandlerTypeMiddleware, HandlerTypeHook:
 			continue
 		}
+		// The routes dispatched by version share the route of other versions,
+		// which are documented with their version prefix.
+		if item.Handler.versionDispatch {
+			continue
+		}
 		if item.Handler.Info.IsStrictRoute {
+			var (
+				_, deprecated = s.config.ApiVersionDeprecations[item.Handler.Version]
+				openapiArray  = []*goai.OpenApiV3{s.openapi}
+			)
+			if item.Handler.Version != "" {
+				openapiArray = append(openapiArray, s.openapiVersions[item.Handler.Version])
+			} else {
+				// Routes without version are available for all versions.
+				for _, version := range s.apiVersions.Slice() {
+					openapiArray = append(openapiArray, s.openapiVersions[version])
+				}
+			}
 			methods = []string{item.Method}
 			if gstr.Equal(item.Method, defaultMethod) {
 				methods = SupportedMethods()
 			}
-			for _, method := range methods {
-				err = s.openapi.Add(goai.AddInput{
-					Path:   item.Route,
-					Method: method,
-					Object: item.Handler.Info.Value.Interface(),
-				})
-				if err != nil {
-					s.Logger().Fatalf(ctx, `%+v`, err)
+			for _, oai := range openapiArray {
+				for _, method := range methods {
+					err = oai.Add(goai.AddInput{
+						Path:       item.Route,
+						Method:     method,
+						Object:     item.Handler.Info.Value.Interface(),
+						Deprecated: deprecated,
+					})
+					if err != nil {
+						s.Logger().Fatalf(ctx, `%+v`, err)
+					}
 				}
 			}
 		}
 	}
 }
 
+// newVersionOpenApi creates and returns the OpenApi specification for API `version`,
+// which inherits the configuration and information of the server specification.
+func (s *Server) newVersionOpenApi(version string) *goai.OpenApiV3 {
+	oai := goai.New()
+	oai.Config = s.openapi.Config
+	oai.Info = s.openapi.Info
+	oai.Info.Version = version
+	oai.Security = s.openapi.Security
+	oai.Servers = s.openapi.Servers
+	oai.Tags = s.openapi.Tags
+	oai.ExternalDocs = s.openapi.ExternalDocs
+	return oai
+}
+
+// GetOpenApiByVersion returns the OpenApi specification of API `version`,
+// which is nil if the version does not exist or the OpenApi feature is disabled.
+// Note that the specifications of API versions are generated when the server starts.
+func (s *Server) GetOpenApiByVersion(version string) *goai.OpenApiV3 {
+	return s.openapiVersions[s.normalizeApiVersion(version)]
+}
+
 // openapiSpec is a build-in handler automatic producing for openapi specification json file.
 func (s *Server) openapiSpec(r *Request) {
 	if s.config.OpenApiPath == "" {
 		r.Response.Write(`OpenApi specification file producing is disabled`)
-	} else {
-		r.Response.WriteJson(s.openapi)
+		return
 	}
+	// Specification of certain API version, like: /api.json?version=v1
+	if version := r.GetQuery(apiVersionMediaTypeParam).String(); version != "" {
+		if oai := s.GetOpenApiByVersion(version); oai != nil {
+			r.Response.WriteJson(oai)
+		} else {
+			r.Response.WriteStatus(http.StatusNotFound)
+		}
+		return
+	}
+	r.Response.WriteJson(s.openapi)
+}
+
+// isDefaultOpenApiContentTypes checks and returns whether given `contentTypes` is the default
+// content types of goai, which is only `application/json`.
+func isDefaultOpenApiContentTypes(contentTypes []string) bool {
+	return len(contentTypes) == 0 || (len(contentTypes) == 1 && contentTypes[0] == contentTypeJson)
 }
@@ -0,0 +1,576 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package ghttp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	internalhttputil "github.com/gogf/gf/v2/internal/httputil"
+	"github.com/gogf/gf/v2/internal/intlog"
+	"github.com/gogf/gf/v2/net/gsel"
+	"github.com/gogf/gf/v2/net/gsvc"
+	"github.com/gogf/gf/v2/net/gtrace"
+)
+
+// ProxyOptions is the options for reverse proxy handler.
+type ProxyOptions struct {
+	// Upstreams are the additional upstream addresses like "127.0.0.1:8000" or "http://127.0.0.1:8000",
+	// which are load balanced with the host of the target. It is ignored if Discovery is set.
+	Upstreams []string
+
+	// Discovery resolves the host of the target as service name, and load balances among
+	// the service endpoints which are kept updated by watching the discovery.
+	Discovery gsvc.Discovery
+
+	// Builder builds the selector for load balancing, which is gsel.GetBuilder() in default.
+	Builder gsel.Builder
+
+	// Transport is the transport for requests to the upstreams, which is a clone of http.DefaultTransport in default.
+	Transport http.RoundTripper
+
+	// StripPrefix is the prefix removed from the request path before it is joined with the path of the target.
+	StripPrefix string
+
+	// RewritePath rewrites the request path after StripPrefix, before it is joined with the path of the target.
+	RewritePath func(path string) string
+
+	// PreserveHost forwards the Host header of the request to the upstreams,
+	// or else the Host header is the address of the upstream.
+	PreserveHost bool
+
+	RequestHeaders        map[string]string // RequestHeaders are set to the requests to the upstreams.
+	RemoveRequestHeaders  []string          // RemoveRequestHeaders are removed from the requests to the upstreams.
+	ResponseHeaders       map[string]string // ResponseHeaders are set to the responses from the upstreams.
+	RemoveResponseHeaders []string          // RemoveResponseHeaders are removed from the responses from the upstreams.
+
+	// Retry is the retry count when the upstream cannot be connected or responds 502, 503 or 504.
+	// Each retry picks the upstream again, so it commonly forwards the request to another upstream.
+	// Only the requests of idempotent methods or with header "Idempotency-Key" are retried in default.
+	// Note that the request with body that is not read by the server is not retried,
+	// as the body is streamed to the upstream.
+	Retry int
+
+	// RetryNonIdempotent specifies whether to retry the requests of non-idempotent methods like POST and PATCH,
+	// which may repeat their side effects on the upstreams.
+	RetryNonIdempotent bool
+
+	// RetryInterval is the interval between retries.
+	RetryInterval time.Duration
+
+	// Timeout is the timeout of each attempt waiting for the response header from the upstream,
+	// the response body is not limited by the timeout, so it works with streaming responses.
+	Timeout time.Duration
+
+	// FlushInterval is the interval flushing the response body to the client. A negative value flushes
+	// immediately after each write. The streaming responses like "text/event-stream" are always flushed immediately.
+	FlushInterval time.Duration
+
+	// ModifyRequest modifies the request to the upstream before it is sent.
+	ModifyRequest func(r *Request, req *http.Request)
+
+	// ModifyResponse modifies the response from the upstream before it is copied to the client.
+	// If it returns error, the ErrorHandler is called.
+	ModifyResponse func(r *Request, resp *http.Response) error
+
+	// ErrorHandler handles the error proxying the request. The default handler responds 503 if there's
+	// no upstream available, 504 if the upstream times out, or else 502.
+	ErrorHandler func(r *Request, err error)
+}
+
+// ProxyHandler is the handler forwarding requests to the upstreams.
+type ProxyHandler struct {
+	target   *url.URL     // Target url, whose host is the service name if discovery is used.
+	options  ProxyOptions // Proxy options.
+	proxy    *httputil.ReverseProxy
+	mu       sync.RWMutex  // Mutex for selector updating.
+	selector gsel.Selector // Selector for load balancing.
+	nodes    string        // Endpoints of the discovered service that the selector is updated with.
+}
+
+// proxyNode is the gsel.Node of the upstream.
+type proxyNode struct {
+	service gsvc.Service
+	address string
+}
+
+// proxyTransport is the http.RoundTripper picking upstreams and retrying requests.
+type proxyTransport struct {
+	handler *ProxyHandler
+}
+
+// proxyResponseWriter is the writer for proxied responses, which writes to the raw writer
+// of the response directly and records the status.
+type proxyResponseWriter struct {
+	response *Response
+}
+
+// proxyBody is the response body canceling the attempt context when it's closed.
+type proxyBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// proxyUpgradeBody is the response body of protocol switching, like WebSocket.
+type proxyUpgradeBody struct {
+	io.ReadWriteCloser
+	cancel context.CancelFunc
+}
+
+// proxyRequestCtxKey is the context key for the Request proxied.
+type proxyRequestCtxKey struct{}
+
+const (
+	proxyTracingAttrUpstream = "http.proxy.upstream"
+	proxyTracingAttrAttempt  = "http.proxy.attempt"
+)
+
+// BindProxy binds reverse proxy handler to `pattern` for all methods, which forwards requests to `target`
+// like "http://127.0.0.1:8000/api", the request path is joined with the path of the target.
+// The `pattern` commonly uses fuzzy matching to forward sub paths, like "/api/*".
+// It returns the created handler, and it panics if `target` is invalid.
+//
+// The handler should not be used with MiddlewareHandlerResponse, as the response is written
+// to the client directly, which supports WebSocket and streaming bodies.
+func (s *Server) BindProxy(pattern string, target string, options ...ProxyOptions) *ProxyHandler {
+	handler, err := NewProxyHandler(target, options...)
+	if err != nil {
+		panic(err)
+	}
+	s.BindHandler(pattern, handler.Handle)
+	return handler
+}
+
+// Proxy binds reverse proxy handler to `pattern` of the group for all methods.
+// It panics if `target` is invalid. See Server.BindProxy.
+func (g *RouterGroup) Proxy(pattern string, target string, options ...ProxyOptions) *RouterGroup {
+	handler, err := NewProxyHandler(target, options...)
+	if err != nil {
+		panic(err)
+	}
+	return g.ALL(pattern, handler.Handle)
+}
+
+// NewProxyHandler creates and returns a reverse proxy handler forwarding requests to `target`.
+func NewProxyHandler(target string, options ...ProxyOptions) (*ProxyHandler, error) {
+	targetUrl, err := url.Parse(target)
+	if err != nil {
+		return nil, gerror.WrapCodef(gcode.CodeInvalidParameter, err, `invalid proxy target "%s"`, target)
+	}
+	if targetUrl.Scheme == "" || targetUrl.Host == "" {
+		return nil, gerror.NewCodef(
+			gcode.CodeInvalidParameter,
+			`invalid proxy target "%s", target is like "http://127.0.0.1:8000"`,
+			target,
+		)
+	}
+	h := &ProxyHandler{
+		target: targetUrl,
+	}
+	if len(options) > 0 {
+		h.options = options[0]
+	}
+	if h.options.Builder == nil {
+		h.options.Builder = gsel.GetBuilder()
+	}
+	if h.options.Transport == nil {
+		h.options.Transport = http.DefaultTransport.(*http.Transport).Clone()
+	}
+	h.selector = h.options.Builder.Build()
+	if h.options.Discovery == nil {
+		var (
+			service = gsvc.NewServiceWithName(targetUrl.Host)
+			nodes   = gsel.Nodes{&proxyNode{service: service, address: targetUrl.Host}}
+		)
+		for _, upstream := range h.options.Upstreams {
+			address := upstream
+			if strings.Contains(upstream, "://") {
+				upstreamUrl, err := url.Parse(upstream)
+				if err != nil {
+					return nil, gerror.WrapCodef(gcode.CodeInvalidParameter, err, `invalid proxy upstream "%s"`, upstream)
+				}
+				address = upstreamUrl.Host
+			}
+			nodes = append(nodes, &proxyNode{service: service, address: address})
+		}
+		if err = h.selector.Update(context.Background(), nodes); err != nil {
+			return nil, err
+		}
+	}
+	h.proxy = &httputil.ReverseProxy{
+		Rewrite:        h.rewrite,
+		Transport:      &proxyTransport{handler: h},
+		FlushInterval:  h.options.FlushInterval,
+		ModifyResponse: h.modifyResponse,
+		ErrorHandler:   h.handleError,
+	}
+	return h, nil
+}
+
+// Handle is the HandlerFunc forwarding the request to the upstreams.
+func (h *ProxyHandler) Handle(r *Request) {
+	ctx, span := gtrace.NewSpan(
+		r.Context(), "ghttp.proxy",
+		trace.WithSpanKind(trace.SpanKindClient),
+	)
+	defer span.End()
+	span.SetAttributes(gtrace.CommonLabels()...)
+
+	ctx = context.WithValue(ctx, proxyRequestCtxKey{}, r)
+	h.proxy.ServeHTTP(&proxyResponseWriter{response: r.Response}, r.Request.WithContext(ctx))
+	if err := r.GetError(); err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// Target returns the target url of the handler.
+func (h *ProxyHandler) Target() *url.URL {
+	return h.target
+}
+
+// rewrite rewrites the request to the upstream.
+func (h *ProxyHandler) rewrite(pr *httputil.ProxyRequest) {
+	r := pr.In.Context().Value(proxyRequestCtxKey{}).(*Request)
+	pr.SetXForwarded()
+
+	// Path rewriting.
+	path := pr.In.URL.Path
+	if h.options.StripPrefix != "" {
+		path = strings.TrimPrefix(path, h.options.StripPrefix)
+		if path == "" || path[0] != '/' {
+			path = "/" + path
+		}
+	}
+	if h.options.RewritePath != nil {
+		path = h.options.RewritePath(path)
+	}
+	pr.Out.URL.Scheme = h.target.Scheme
+	pr.Out.URL.Host = h.target.Host
+	pr.Out.URL.Path = joinProxyPath(h.target.Path, path)
+	pr.Out.URL.RawPath = ""
+	if h.target.RawQuery != "" {
+		if pr.Out.URL.RawQuery == "" {
+			pr.Out.URL.RawQuery = h.target.RawQuery
+		} else {
+			pr.Out.URL.RawQuery = h.target.RawQuery + "&" + pr.Out.URL.RawQuery
+		}
+	}
+	if h.options.PreserveHost {
+		pr.Out.Host = pr.In.Host
+	}
+
+	// The body that is read by the server is repeatable for retries.
+	if r.bodyContent != nil {
+		body := r.bodyContent
+		pr.Out.Body = io.NopCloser(bytes.NewReader(body))
+		pr.Out.ContentLength = int64(len(body))
+		pr.Out.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+
+	// Header rewriting.
+	for _, key := range h.options.RemoveRequestHeaders {
+		pr.Out.Header.Del(key)
+	}
+	for key, value := range h.options.RequestHeaders {
+		pr.Out.Header.Set(key, value)
+	}
+	// Tracing propagation.
+	otel.GetTextMapPropagator().Inject(pr.Out.Context(), propagation.HeaderCarrier(pr.Out.Header))
+
+	if h.options.ModifyRequest != nil {
+		h.options.ModifyRequest(r, pr.Out)
+	}
+}
+
+// modifyResponse modifies the response from the upstream.
+func (h *ProxyHandler) modifyResponse(resp *http.Response) error {
+	for _, key := range h.options.RemoveResponseHeaders {
+		resp.Header.Del(key)
+	}
+	for key, value := range h.options.ResponseHeaders {
+		resp.Header.Set(key, value)
+	}
+	if h.options.ModifyResponse != nil {
+		r := resp.Request.Context().Value(proxyRequestCtxKey{}).(*Request)
+		return h.options.ModifyResponse(r, resp)
+	}
+	return nil
+}
+
+// handleError handles the error proxying the request.
+func (h *ProxyHandler) handleError(_ http.ResponseWriter, req *http.Request, err error) {
+	r := req.Context().Value(proxyRequestCtxKey{}).(*Request)
+	if h.options.ErrorHandler != nil {
+		h.options.ErrorHandler(r, err)
+		return
+	}
+	var status = http.StatusBadGateway
+	switch {
+	case gerror.Is(err, context.DeadlineExceeded):
+		status = http.StatusGatewayTimeout
+	case gerror.Code(err) == gcode.CodeNotFound:
+		status = http.StatusServiceUnavailable
+	}
+	r.Response.WriteStatus(status)
+	r.SetError(gerror.WrapCodef(gcode.CodeOperationFailed, err, `proxy request to "%s" failed`, h.target.String()))
+}
+
+// pick picks and returns an upstream node.
+func (h *ProxyHandler) pick(ctx context.Context) (gsel.Node, gsel.DoneFunc, error) {
+	if h.options.Discovery != nil {
+		if err := h.updateDiscoveryNodes(ctx); err != nil {
+			return nil, nil, err
+		}
+	}
+	h.mu.RLock()
+	selector := h.selector
+	h.mu.RUnlock()
+	node, done, err := selector.Pick(ctx)
+	if err != nil {
+		return nil, nil, gerror.WrapCodef(gcode.CodeNotFound, err, `no upstream available for "%s"`, h.target.Host)
+	}
+	if node == nil {
+		return nil, nil, gerror.NewCodef(gcode.CodeNotFound, `no upstream available for "%s"`, h.target.Host)
+	}
+	return node, done, nil
+}
+
+// updateDiscoveryNodes updates the selector with the endpoints of the discovered service if they are changed.
+func (h *ProxyHandler) updateDiscoveryNodes(ctx context.Context) error {
+	service, err := gsvc.GetAndWatchWithDiscovery(
+		ctx, h.options.Discovery, h.target.Host, func(service gsvc.Service) {
+			intlog.Printf(ctx, `http proxy watching service "%s" changed`, service.GetPrefix())
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if service == nil {
+		return gerror.NewCodef(gcode.CodeNotFound, `service not found with name "%s"`, h.target.Host)
+	}
+	endpoints := service.GetEndpoints().String()
+	h.mu.RLock()
+	changed := endpoints != h.nodes
+	h.mu.RUnlock()
+	if !changed {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if endpoints == h.nodes {
+		return nil
+	}
+	nodes := make(gsel.Nodes, 0)
+	for _, endpoint := range service.GetEndpoints() {
+		nodes = append(nodes, &proxyNode{
+			service: service,
+			address: endpoint.String(),
+		})
+	}
+	if err = h.selector.Update(ctx, nodes); err != nil {
+		return err
+	}
+	h.nodes = endpoints
+	return nil
+}
+
+// joinProxyPath joins the target path and request path with single slash.
+func joinProxyPath(targetPath, path string) string {
+	if targetPath == "" {
+		return path
+	}
+	return strings.TrimRight(targetPath, "/") + "/" + strings.TrimLeft(path, "/")
+}
+
+// Service returns the service of the node.
+func (n *proxyNode) Service() gsvc.Service {
+	return n.service
+}
+
+// Address returns the address of the node.
+func (n *proxyNode) Address() string {
+	return n.address
+}
+
+// RoundTrip implements interface http.RoundTripper.
+func (t *proxyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var (
+		h        = t.handler
+		ctx      = req.Context()
+		span     = trace.SpanFromContext(ctx)
+		canRetry = (req.Body == nil || req.Body == http.NoBody || req.GetBody != nil) &&
+			(h.options.RetryNonIdempotent || internalhttputil.IsIdempotentRequest(req))
+	)
+	for attempt := 0; ; attempt++ {
+		outReq := req
+		if attempt > 0 {
+			outReq = req.Clone(ctx)
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				outReq.Body = body
+			}
+		}
+		node, done, err := h.pick(ctx)
+		if err != nil {
+			return nil, err
+		}
+		outReq.URL.Host = node.Address()
+		span.SetAttributes(
+			attribute.String(proxyTracingAttrUpstream, node.Address()),
+			attribute.Int(proxyTracingAttrAttempt, attempt),
+		)
+		resp, err := t.roundTripWithTimeout(outReq)
+		if done != nil {
+			done(ctx, gsel.DoneInfo{Err: err, BytesSent: true, BytesReceived: resp != nil})
+		}
+		if attempt >= h.options.Retry || !canRetry || !isProxyRetryable(resp, err) {
+			return resp, err
+		}
+		if resp != nil {
+			_ = resp.Body.Close()
+		}
+		intlog.Printf(ctx, `http proxy retry request to "%s": %v`, node.Address(), err)
+		if h.options.RetryInterval > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(h.options.RetryInterval):
+			}
+		}
+	}
+}
+
+// roundTripWithTimeout sends the request to the upstream, which times out if the response header
+// is not received in time.
+func (t *proxyTransport) roundTripWithTimeout(req *http.Request) (*http.Response, error) {
+	var (
+		timeout     = t.handler.options.Timeout
+		ctx, cancel = context.WithCancel(req.Context())
+		timer       *time.Timer
+	)
+	if timeout > 0 {
+		timer = time.AfterFunc(timeout, cancel)
+	}
+	resp, err := t.handler.options.Transport.RoundTrip(req.WithContext(ctx))
+	if timer != nil && !timer.Stop() {
+		// The timer has fired, it waits for the cancellation that is done by the timer.
+		<-ctx.Done()
+	}
+	// The context is cancelled by the timer but not the incoming request, so the response is unusable.
+	if ctx.Err() != nil && req.Context().Err() == nil {
+		if resp != nil {
+			_ = resp.Body.Close()
+		}
+		cancel()
+		return nil, gerror.Wrapf(
+			context.DeadlineExceeded, `proxy request to "%s" timed out after %s`, req.URL.Host, timeout,
+		)
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if body, ok := resp.Body.(io.ReadWriteCloser); ok && resp.StatusCode == http.StatusSwitchingProtocols {
+		resp.Body = &proxyUpgradeBody{ReadWriteCloser: body, cancel: cancel}
+	} else {
+		resp.Body = &proxyBody{ReadCloser: resp.Body, cancel: cancel}
+	}
+	return resp, nil
+}
+
+// isProxyRetryable checks and returns whether the attempt can be retried.
+func isProxyRetryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return !gerror.Is(err, context.Canceled)
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// Header implements interface http.ResponseWriter.
+func (w *proxyResponseWriter) Header() http.Header {
+	return w.response.Header()
+}
+
+// Write implements interface http.ResponseWriter.
+func (w *proxyResponseWriter) Write(data []byte) (int, error) {
+	if w.response.Status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.response.RawWriter().Write(data)
+}
+
+// WriteHeader implements interface http.ResponseWriter.
+func (w *proxyResponseWriter) WriteHeader(status int) {
+	// Informational responses except protocol switching can be written multiple times.
+	if status >= 100 && status < 200 && status != http.StatusSwitchingProtocols {
+		w.response.Writer.ResponseWriter.WriteHeader(status)
+		return
+	}
+	if w.response.Status == 0 {
+		w.response.Status = status
+	}
+	w.response.RawWriter().WriteHeader(status)
+}
+
+// Flush implements interface http.Flusher.
+func (w *proxyResponseWriter) Flush() {
+	if w.response.Status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	w.response.Writer.Flush()
+}
+
+// Hijack implements interface http.Hijacker.
+func (w *proxyResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if w.response.Status == 0 {
+		w.response.Status = http.StatusSwitchingProtocols
+	}
+	return w.response.Writer.Hijack()
+}
+
+// Unwrap returns the underlying writer for http.ResponseController.
+func (w *proxyResponseWriter) Unwrap() http.ResponseWriter {
+	return w.response.RawWriter()
+}
+
+// Close closes the body and cancels the attempt context.
+func (b *proxyBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+// Close closes the body and cancels the attempt context.
+func (b *proxyUpgradeBody) Close() error {
+	defer b.cancel()
+	return b.ReadWriteCloser.Close()
+}
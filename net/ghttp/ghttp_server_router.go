@@ -12,6 +12,7 @@ import (
 	"reflect"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/gogf/gf/v2/container/glist"
 	"github.com/gogf/gf/v2/container/gtype"
@@ -19,8 +20,10 @@ import (
 	"github.com/gogf/gf/v2/errors/gcode"
 	"github.com/gogf/gf/v2/errors/gerror"
 	"github.com/gogf/gf/v2/internal/consts"
+	"github.com/gogf/gf/v2/internal/httputil"
 	"github.com/gogf/gf/v2/text/gregex"
 	"github.com/gogf/gf/v2/text/gstr"
+	"github.com/gogf/gf/v2/util/gconv"
 	"github.com/gogf/gf/v2/util/gmeta"
 	"github.com/gogf/gf/v2/util/gtag"
 )
@@ -42,7 +45,7 @@ func (s *Server) parsePattern(pattern string) (domain, method, path string, err
 	path = strings.TrimSpace(pattern)
 	domain = DefaultDomainName
 	method = defaultMethod
-	if array, err := gregex.MatchString(`([a-zA-Z]+):(.+)`, pattern); len(array) > 1 && err == nil {
+	if array, err := gregex.MatchString(`^([a-zA-Z]+):(.+)`, pattern); len(array) > 1 && err == nil {
 		path = strings.TrimSpace(array[2])
 		if v := strings.TrimSpace(array[1]); v != "" {
 			method = v
@@ -106,13 +109,36 @@ func (s *Server) setHandler(ctx context.Context, in setHandlerInput) {
 		if v := gmeta.Get(objectReq, gtag.Method); !v.IsEmpty() {
 			method = v.String()
 		}
+		if v := gmeta.Get(objectReq, gtag.Version); !v.IsEmpty() {
+			handler.Version = gstr.Trim(v.String(), "/")
+		}
+		if v := gmeta.Get(objectReq, gtag.Timeout); !v.IsEmpty() {
+			if handler.timeout, err = time.ParseDuration(v.String()); err != nil {
+				s.Logger().Fatalf(ctx, `invalid timeout "%s" of pattern "%s", %+v`, v.String(), pattern, err)
+				return
+			}
+		}
+		if v := gmeta.Get(objectReq, gtag.Bulkhead); !v.IsEmpty() {
+			handler.bulkhead = &BulkheadOptions{}
+			if err = parseMetaOptions(v.String(), handler.bulkhead); err != nil {
+				s.Logger().Fatalf(ctx, `invalid bulkhead "%s" of pattern "%s", %+v`, v.String(), pattern, err)
+				return
+			}
+		}
+		if v := gmeta.Get(objectReq, gtag.CircuitBreaker); !v.IsEmpty() {
+			handler.circuitBreaker = &CircuitBreakerOptions{}
+			if err = parseMetaOptions(v.String(), handler.circuitBreaker); err != nil {
+				s.Logger().Fatalf(ctx, `invalid circuitBreaker "%s" of pattern "%s", %+v`, v.String(), pattern, err)
+				return
+			}
+		}
 		// Multiple methods registering, which are joined using char `,`.
 		if gstr.Contains(method, ",") {
 			methods := gstr.SplitAndTrim(method, ",")
 			for _, v := range methods {
 				// Each method has it own handler.
 				clonedHandler := *handler
-				s.doSetHandler(ctx, &clonedHandler, prefix, uri, pattern, v, domain)
+				s.doSetVersionedHandler(ctx, &clonedHandler, prefix, uri, pattern, v, domain)
 			}
 			return
 		}
@@ -121,7 +147,29 @@ func (s *Server) setHandler(ctx context.Context, in setHandlerInput) {
 			method = defaultMethod
 		}
 	}
-	s.doSetHandler(ctx, handler, prefix, uri, pattern, method, domain)
+	s.doSetVersionedHandler(ctx, handler, prefix, uri, pattern, method, domain)
+}
+
+// doSetVersionedHandler registers the handler, which is registered twice if it has API version:
+// one with the version prefix following `prefix`, and the other without the version prefix
+// dispatching the requests by version resolved from request.
+func (s *Server) doSetVersionedHandler(
+	ctx context.Context, handler *HandlerItem,
+	prefix, uri, pattern, method, domain string,
+) {
+	switch {
+	case handler.Version == "":
+		s.doSetHandler(ctx, handler, prefix, uri, pattern, method, domain)
+		return
+	case handler.Type != HandlerTypeHandler && handler.Type != HandlerTypeObject:
+		s.doSetHandler(ctx, handler, prefix, uri, pattern, method, domain)
+		return
+	}
+	s.apiVersions.Add(handler.Version)
+	dispatchHandler := *handler
+	dispatchHandler.versionDispatch = true
+	s.doSetHandler(ctx, handler, strings.TrimRight(prefix, "/")+"/"+handler.Version, uri, pattern, method, domain)
+	s.doSetHandler(ctx, &dispatchHandler, prefix, uri, pattern, method, domain)
 }
 
 func (s *Server) doSetHandler(
@@ -156,6 +204,10 @@ func (s *Server) doSetHandler(
 			if items, ok := s.routesMap[routerKey]; ok {
 				var duplicatedHandler *HandlerItem
 				for i, item := range items {
+					// Handlers dispatched by different API versions are not duplicated.
+					if (item.versionDispatch || handler.versionDispatch) && item.Version != handler.Version {
+						continue
+					}
 					switch item.Type {
 					case HandlerTypeHandler, HandlerTypeObject:
 						duplicatedHandler = items[i]
@@ -219,7 +271,7 @@ func (s *Server) doSetHandler(
 			continue
 		}
 		// Check if it's a fuzzy node.
-		if gregex.IsMatchString(`^[:\*]|\{[\w\.\-]+\}|\*`, part) {
+		if gregex.IsMatchString(`^[:\*]|\*`, part) || len(httputil.ParseRouteParams(part)) > 0 {
 			part = "*fuzz"
 			// If it's a fuzzy node, it creates a "*list" item - which is a list - in the hash map.
 			// All the sub router items from this fuzzy node will also be added to its "*list" item.
@@ -297,6 +349,7 @@ func (s *Server) isValidMethod(method string) bool {
 // 1. The middleware has the most high priority.
 // 2. URI: The deeper, the higher (simply check the count of char '/' in the URI).
 // 3. Route type: {xxx} > :xxx > *xxx.
+// 4. Constrained field: {xxx:int} > {xxx}.
 func (s *Server) compareRouterPriority(newItem *HandlerItem, oldItem *HandlerItem) bool {
 	// If they're all types of middleware, the priority is according to their registered sequence.
 	if newItem.Type == HandlerTypeMiddleware && oldItem.Type == HandlerTypeMiddleware {
@@ -320,9 +373,12 @@ func (s *Server) compareRouterPriority(newItem *HandlerItem, oldItem *HandlerIte
 	// Example:
 	// /admin-goods-{page} > /admin-{page}
 	// /{hash}.{type}      > /{hash}
-	var uriNew, uriOld string
-	uriNew, _ = gregex.ReplaceString(`\{[^/]+?\}`, "", newItem.Router.Uri)
-	uriOld, _ = gregex.ReplaceString(`\{[^/]+?\}`, "", oldItem.Router.Uri)
+	var (
+		uriNew = httputil.StripRouteConstraints(newItem.Router.Uri)
+		uriOld = httputil.StripRouteConstraints(oldItem.Router.Uri)
+	)
+	uriNew, _ = gregex.ReplaceString(`\{[^/]+?\}`, "", uriNew)
+	uriOld, _ = gregex.ReplaceString(`\{[^/]+?\}`, "", uriOld)
 	uriNew, _ = gregex.ReplaceString(`:[^/]+?`, "", uriNew)
 	uriOld, _ = gregex.ReplaceString(`:[^/]+?`, "", uriOld)
 	uriNew, _ = gregex.ReplaceString(`\*[^/]*`, "", uriNew) // Replace "/*" and "/*any".
@@ -347,7 +403,7 @@ func (s *Server) compareRouterPriority(newItem *HandlerItem, oldItem *HandlerIte
 		fuzzyCountTotalNew int
 		fuzzyCountTotalOld int
 	)
-	for _, v := range newItem.Router.Uri {
+	for _, v := range httputil.StripRouteConstraints(newItem.Router.Uri) {
 		switch v {
 		case '{':
 			fuzzyCountFieldNew++
@@ -357,7 +413,7 @@ func (s *Server) compareRouterPriority(newItem *HandlerItem, oldItem *HandlerIte
 			fuzzyCountAnyNew++
 		}
 	}
-	for _, v := range oldItem.Router.Uri {
+	for _, v := range httputil.StripRouteConstraints(oldItem.Router.Uri) {
 		switch v {
 		case '{':
 			fuzzyCountFieldOld++
@@ -393,6 +449,19 @@ func (s *Server) compareRouterPriority(newItem *HandlerItem, oldItem *HandlerIte
 		return false
 	}
 
+	// The constrained field is more accurate than the unconstrained one.
+	// Eg: /user/{id:int} > /user/{name}
+	var (
+		constraintCountNew = routeConstraintCount(newItem.Router.Uri)
+		constraintCountOld = routeConstraintCount(oldItem.Router.Uri)
+	)
+	if constraintCountNew > constraintCountOld {
+		return true
+	}
+	if constraintCountNew < constraintCountOld {
+		return false
+	}
+
 	// It then compares the accuracy of their http method,
 	// the more accurate the more priority.
 	if newItem.Router.Method != defaultMethod {
@@ -440,23 +509,82 @@ func (s *Server) patternToRegular(rule string) (regular string, names []string)
 				regular += `/{0,1}.*`
 			}
 		default:
-			// Special chars replacement.
-			v = gstr.ReplaceByMap(v, map[string]string{
-				`.`: `\.`,
-				`+`: `\+`,
-				`*`: `.*`,
-			})
-			s, _ := gregex.ReplaceStringFunc(`\{[\w\.\-]+\}`, v, func(s string) string {
-				names = append(names, s[1:len(s)-1])
-				return `([^/]+)`
-			})
-			if strings.EqualFold(s, v) {
-				regular += "/" + v
-			} else {
-				regular += "/" + s
+			var (
+				last   = 0
+				params = httputil.ParseRouteParams(v)
+			)
+			regular += "/"
+			for _, param := range params {
+				regular += routeLiteralToRegular(v[last:param.Start])
+				regular += `(` + routeConstraintToRegular(param.Constraint) + `)`
+				names = append(names, param.Name)
+				last = param.End
 			}
+			regular += routeLiteralToRegular(v[last:])
 		}
 	}
 	regular += `$`
 	return
 }
+
+// routeLiteralToRegular converts the literal part of route rule to regular expression.
+func routeLiteralToRegular(literal string) string {
+	// Special chars replacement.
+	return gstr.ReplaceByMap(literal, map[string]string{
+		`.`: `\.`,
+		`+`: `\+`,
+		`*`: `.*`,
+	})
+}
+
+// routeConstraintToRegular converts the constraint of route field to regular expression,
+// it converts the capturing groups to non-capturing ones, so that they do not affect the
+// matched values of the names.
+func routeConstraintToRegular(constraint string) string {
+	if constraint == "" {
+		return `[^/]+`
+	}
+	var (
+		regular = httputil.ConstraintToRegex(constraint)
+		builder strings.Builder
+	)
+	for i := 0; i < len(regular); i++ {
+		builder.WriteByte(regular[i])
+		switch regular[i] {
+		case '\\':
+			if i+1 < len(regular) {
+				i++
+				builder.WriteByte(regular[i])
+			}
+		case '(':
+			if i+1 < len(regular) && regular[i+1] != '?' {
+				builder.WriteString(`?:`)
+			}
+		}
+	}
+	return builder.String()
+}
+
+// routeConstraintCount returns the count of constrained fields in route rule `uri`.
+func routeConstraintCount(uri string) int {
+	var count int
+	for _, param := range httputil.ParseRouteParams(uri) {
+		if param.Constraint != "" {
+			count++
+		}
+	}
+	return count
+}
+
+// parseMetaOptions parses the options from meta tag value like "key1=value1,key2=value2" into struct `pointer`.
+func parseMetaOptions(value string, pointer interface{}) error {
+	var m = make(map[string]string)
+	for _, item := range gstr.SplitAndTrim(value, ",") {
+		k, v, ok := strings.Cut(item, "=")
+		if !ok {
+			return gerror.NewCodef(gcode.CodeInvalidParameter, `invalid option "%s", it should be like "key=value"`, item)
+		}
+		m[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return gconv.Struct(m, pointer)
+}
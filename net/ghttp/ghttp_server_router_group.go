@@ -25,6 +25,7 @@ type (
 		server     *Server       // Server.
 		domain     *Domain       // Domain.
 		prefix     string        // Prefix for sub-route.
+		version    string        // API version for sub-route.
 		middleware []HandlerFunc // Middleware array.
 	}
 
@@ -137,6 +138,23 @@ func (g *RouterGroup) Group(prefix string, groups ...func(group *RouterGroup)) *
 	return group
 }
 
+// Version creates and returns a subgroup of the current router group, of which the routes are
+// of API `version`, like "v1".
+//
+// The routes are registered with the version prefix following the group prefix, like "/api/v1/user",
+// and can also be requested without the version prefix, like "/api/user", in which case the version is
+// resolved from request header or media type parameter. See ServerConfig.ApiVersionHeader.
+func (g *RouterGroup) Version(version string, groups ...func(group *RouterGroup)) *RouterGroup {
+	group := g.Group("")
+	group.version = gstr.Trim(version, "/")
+	if len(groups) > 0 {
+		for _, v := range groups {
+			v(group)
+		}
+	}
+	return group
+}
+
 // Clone returns a new router group which is a clone of the current group.
 func (g *RouterGroup) Clone() *RouterGroup {
 	newGroup := &RouterGroup{
@@ -144,6 +162,7 @@ func (g *RouterGroup) Clone() *RouterGroup {
 		server:     g.server,
 		domain:     g.domain,
 		prefix:     g.prefix,
+		version:    g.version,
 		middleware: make([]HandlerFunc, len(g.middleware)),
 	}
 	copy(newGroup.middleware, g.middleware)
@@ -290,6 +309,16 @@ func (g *RouterGroup) getPrefix() string {
 	return prefix
 }
 
+// getVersion returns the API version of the group, which is the nearest version of the group and its parents.
+func (g *RouterGroup) getVersion() string {
+	for group := g; group != nil; group = group.parent {
+		if group.version != "" {
+			return group.version
+		}
+	}
+	return ""
+}
+
 // doBindRoutersToServer does really register for the group.
 func (g *RouterGroup) doBindRoutersToServer(ctx context.Context, item *preBindItem) *RouterGroup {
 	var (
@@ -342,6 +371,7 @@ func (g *RouterGroup) doBindRoutersToServer(ctx context.Context, item *preBindIt
 				FuncInfo:   funcInfo,
 				Middleware: g.middleware,
 				Source:     source,
+				Version:    g.getVersion(),
 			}
 			if g.domain != nil {
 				g.domain.doBindHandler(ctx, in)
@@ -358,6 +388,7 @@ func (g *RouterGroup) doBindRoutersToServer(ctx context.Context, item *preBindIt
 						Method:     extras[0],
 						Middleware: g.middleware,
 						Source:     source,
+						Version:    g.getVersion(),
 					}
 					if g.domain != nil {
 						g.domain.doBindObject(ctx, in)
@@ -372,6 +403,7 @@ func (g *RouterGroup) doBindRoutersToServer(ctx context.Context, item *preBindIt
 						Method:     extras[0],
 						Middleware: g.middleware,
 						Source:     source,
+						Version:    g.getVersion(),
 					}
 					if g.domain != nil {
 						g.domain.doBindObjectMethod(ctx, in)
@@ -387,6 +419,7 @@ func (g *RouterGroup) doBindRoutersToServer(ctx context.Context, item *preBindIt
 					Method:     "",
 					Middleware: g.middleware,
 					Source:     source,
+					Version:    g.getVersion(),
 				}
 				// Finally, it treats the `object` as the Object registering type.
 				if g.domain != nil {
@@ -405,6 +438,7 @@ func (g *RouterGroup) doBindRoutersToServer(ctx context.Context, item *preBindIt
 			Method:     "",
 			Middleware: g.middleware,
 			Source:     source,
+			Version:    g.getVersion(),
 		}
 		if g.domain != nil {
 			g.domain.doBindObjectRest(ctx, in)
@@ -71,9 +71,18 @@ func (s *Server) getHandlersWithCache(r *Request) (parsedItems []*HandlerItemPar
 	if xUrlPath := r.Header.Get(HeaderXUrlPath); xUrlPath != "" {
 		path = xUrlPath
 	}
+	// API version resolving, only if there are versioned routes.
+	var version string
+	if s.apiVersions.Len() > 0 {
+		version = s.resolveApiVersion(r)
+		r.apiVersion = version
+	}
 	var handlerCacheKey = s.serveHandlerKey(method, path, host)
+	if version != "" {
+		handlerCacheKey += "#" + version
+	}
 	value, err := s.serveCache.GetOrSetFunc(ctx, handlerCacheKey, func(ctx context.Context) (interface{}, error) {
-		parsedItems, serveItem, hasHook, hasServe = s.searchHandlers(method, path, host)
+		parsedItems, serveItem, hasHook, hasServe = s.searchVersionedHandlers(method, path, host, version)
 		if parsedItems != nil {
 			return &handlerCacheItem{parsedItems, serveItem, hasHook, hasServe}, nil
 		}
@@ -91,7 +100,8 @@ func (s *Server) getHandlersWithCache(r *Request) (parsedItems []*HandlerItemPar
 
 // searchHandlers retrieve and returns the routers with given parameters.
 // Note that the returned routers contain serving handler, middleware handlers and hook handlers.
-func (s *Server) searchHandlers(method, path, domain string) (parsedItems []*HandlerItemParsed, serveItem *HandlerItemParsed, hasHook, hasServe bool) {
+// The serving handlers dispatched by API version are filtered out if they are not of `version`.
+func (s *Server) searchHandlers(method, path, domain, version string) (parsedItems []*HandlerItemParsed, serveItem *HandlerItemParsed, hasHook, hasServe bool) {
 	if len(path) == 0 {
 		return nil, nil, false, false
 	}
@@ -169,6 +179,9 @@ func (s *Server) searchHandlers(method, path, domain string) (parsedItems []*Han
 		for i := len(lists) - 1; i >= 0; i-- {
 			for e := lists[i].Front(); e != nil; e = e.Next() {
 				item := e.Value.(*HandlerItem)
+				if item.versionDispatch && item.Version != version {
+					continue
+				}
 				// Filter repeated handler items, especially the middleware and hook handlers.
 				// It is necessary, do not remove this checks logic unless you really know how it is necessary.
 				//
@@ -0,0 +1,147 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package ghttp
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gogf/gf/v2/text/gstr"
+)
+
+const (
+	// apiVersionMediaTypeParam is the media type parameter name for API version,
+	// like: application/json; version=v2
+	apiVersionMediaTypeParam = "version"
+)
+
+// compareApiVersion compares API versions `a` and `b` like comparing semantic versions,
+// and compares them as strings if they are of the same semantic version, like "v1" and "1".
+func compareApiVersion(a, b string) int {
+	if r := gstr.CompareVersion(a, b); r != 0 {
+		return r
+	}
+	return strings.Compare(a, b)
+}
+
+// resolveApiVersion resolves and returns the API version of the request from, in order:
+// request header, media type parameter of header "Accept" or "Content-Type",
+// configured default version and the latest registered version.
+func (s *Server) resolveApiVersion(r *Request) string {
+	var version string
+	if s.config.ApiVersionHeader != "" {
+		version = r.Header.Get(s.config.ApiVersionHeader)
+	}
+	if version == "" {
+		version = mediaTypeApiVersion(r.Header.Get("Accept"))
+	}
+	if version == "" {
+		version = mediaTypeApiVersion(r.Header.Get("Content-Type"))
+	}
+	if version == "" {
+		version = s.config.ApiVersionDefault
+	}
+	if version == "" {
+		if n := s.apiVersions.Len(); n > 0 {
+			version, _ = s.apiVersions.Get(n - 1)
+		}
+	}
+	return s.normalizeApiVersion(version)
+}
+
+// normalizeApiVersion normalizes `version` to the registered one,
+// for example, it converts "2" to "v2" if "v2" is registered.
+func (s *Server) normalizeApiVersion(version string) string {
+	version = gstr.Trim(version, " /")
+	if version == "" || s.apiVersions.Contains(version) {
+		return version
+	}
+	if version[0] != 'v' && version[0] != 'V' {
+		if s.apiVersions.Contains("v" + version) {
+			return "v" + version
+		}
+	} else if s.apiVersions.Contains(version[1:]) {
+		return version[1:]
+	}
+	return version
+}
+
+// mediaTypeApiVersion retrieves and returns the API version from the media type parameter of `header`,
+// which can contain multiple media types joined with char ','.
+func mediaTypeApiVersion(header string) string {
+	if header == "" || !strings.Contains(header, apiVersionMediaTypeParam) {
+		return ""
+	}
+	for _, item := range strings.Split(header, ",") {
+		_, params, err := mime.ParseMediaType(item)
+		if err != nil {
+			continue
+		}
+		if version := params[apiVersionMediaTypeParam]; version != "" {
+			return version
+		}
+	}
+	return ""
+}
+
+// searchVersionedHandlers searches the handlers for the request of API `version`.
+// If there's no serving handler and fallback is enabled, it searches the nearest lower versions in order.
+func (s *Server) searchVersionedHandlers(
+	method, path, domain, version string,
+) (parsedItems []*HandlerItemParsed, serveItem *HandlerItemParsed, hasHook, hasServe bool) {
+	parsedItems, serveItem, hasHook, hasServe = s.searchHandlers(method, path, domain, version)
+	if hasServe || version == "" || !s.config.ApiVersionFallback {
+		return
+	}
+	versions := s.apiVersions.Slice()
+	for i := len(versions) - 1; i >= 0; i-- {
+		if gstr.CompareVersion(versions[i], version) >= 0 {
+			continue
+		}
+		items, item, hook, serve := s.searchHandlers(method, path, domain, versions[i])
+		if serve {
+			return items, item, hook, serve
+		}
+	}
+	return
+}
+
+// setApiVersionDeprecationHeaders sets the deprecation headers to response
+// if the serving handler is of deprecated API version.
+func (s *Server) setApiVersionDeprecationHeaders(r *Request) {
+	if len(s.config.ApiVersionDeprecations) == 0 || r.serveHandler == nil {
+		return
+	}
+	deprecation, ok := s.config.ApiVersionDeprecations[r.serveHandler.Handler.Version]
+	if !ok {
+		return
+	}
+	header := r.Response.Header()
+	if deprecation.Date.IsZero() {
+		header.Set("Deprecation", "true")
+	} else {
+		header.Set("Deprecation", "@"+strconv.FormatInt(deprecation.Date.Unix(), 10))
+	}
+	if !deprecation.Sunset.IsZero() {
+		header.Set("Sunset", deprecation.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if deprecation.Link != "" {
+		header.Add("Link", fmt.Sprintf(`<%s>; rel="deprecation"`, deprecation.Link))
+	}
+}
+
+// GetApiVersion returns the API version of current request, which is the version of the serving handler,
+// or else the version resolved from request if there are versioned routes registered.
+func (r *Request) GetApiVersion() string {
+	if r.serveHandler != nil && r.serveHandler.Handler.Version != "" {
+		return r.serveHandler.Handler.Version
+	}
+	return r.apiVersion
+}
@@ -44,6 +44,7 @@ type doBindHandlerInput struct {
 	FuncInfo   handlerFuncInfo
 	Middleware []HandlerFunc
 	Source     string
+	Version    string
 }
 
 // doBindHandler registers a handler function to server with given pattern.
@@ -59,6 +60,7 @@ func (s *Server) doBindHandler(ctx context.Context, in doBindHandlerInput) {
 			Info:       in.FuncInfo,
 			Middleware: in.Middleware,
 			Source:     in.Source,
+			Version:    in.Version,
 		},
 	})
 }
@@ -70,6 +70,7 @@ type doBindObjectInput struct {
 	Method     string
 	Middleware []HandlerFunc
 	Source     string
+	Version    string
 }
 
 func (s *Server) doBindObject(ctx context.Context, in doBindObjectInput) {
@@ -143,6 +144,7 @@ func (s *Server) doBindObject(ctx context.Context, in doBindObjectInput) {
 			ShutFunc:   shutFunc,
 			Middleware: in.Middleware,
 			Source:     in.Source,
+			Version:    in.Version,
 		}
 		// If there's "Index" method, then an additional route is automatically added
 		// to match the main URI, for example:
@@ -170,6 +172,7 @@ func (s *Server) doBindObject(ctx context.Context, in doBindObjectInput) {
 				ShutFunc:   shutFunc,
 				Middleware: in.Middleware,
 				Source:     in.Source,
+				Version:    in.Version,
 			}
 		}
 	}
@@ -183,6 +186,7 @@ type doBindObjectMethodInput struct {
 	Method     string
 	Middleware []HandlerFunc
 	Source     string
+	Version    string
 }
 
 func (s *Server) doBindObjectMethod(ctx context.Context, in doBindObjectMethodInput) {
@@ -239,6 +243,7 @@ func (s *Server) doBindObjectMethod(ctx context.Context, in doBindObjectMethodIn
 		ShutFunc:   shutFunc,
 		Middleware: in.Middleware,
 		Source:     in.Source,
+		Version:    in.Version,
 	}
 
 	s.bindHandlerByMap(ctx, in.Prefix, handlerMap)
@@ -298,6 +303,7 @@ func (s *Server) doBindObjectRest(ctx context.Context, in doBindObjectInput) {
 			ShutFunc:   shutFunc,
 			Middleware: in.Middleware,
 			Source:     in.Source,
+			Version:    in.Version,
 		}
 	}
 	s.bindHandlerByMap(ctx, in.Prefix, handlerMap)
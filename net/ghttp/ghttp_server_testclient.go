@@ -0,0 +1,269 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package ghttp
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/net/gclient"
+)
+
+// TestRecord is the recorded response of the request dispatched by TestClient,
+// which can be retrieved by GetTestRecord for assertions.
+type TestRecord struct {
+	Request *Request    // Server side request object, which can be used to retrieve the handler error and response.
+	Status  int         // Response status.
+	Header  http.Header // Response header.
+	Body    []byte      // Response body.
+}
+
+// testTransport is the http.RoundTripper dispatching requests to the server in process.
+type testTransport struct {
+	server    *Server
+	upgrading *http.Transport // Transport for upgrade requests, which uses in-memory connections.
+}
+
+// testResponseRecorder is the http.ResponseWriter recording the response.
+type testResponseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+// testRecordBody is the response body carrying the TestRecord.
+type testRecordBody struct {
+	io.ReadCloser
+	record *TestRecord
+}
+
+// testListener is the in-memory net.Listener accepting connections from TestDialContext.
+type testListener struct {
+	conns     chan net.Conn
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// testAddr is the net.Addr of in-memory connections.
+type testAddr struct{}
+
+// testRecordCtxKey is the context key for TestRecord of the request.
+type testRecordCtxKey struct{}
+
+const (
+	// TestClientHost is the host of requests sent by TestClient.
+	TestClientHost = "localhost"
+	// testClientRemoteAddr is the client address of requests sent by TestClient.
+	testClientRemoteAddr = "127.0.0.1:0"
+)
+
+// TestClient creates and returns a client dispatching requests directly into the router and middleware
+// stack of the server in process, without binding a port. It is used for testing purpose.
+//
+// The server does not need to be started, the routes are registered when the test client is created,
+// and the group routes registered later are bound before the next request. It returns an error if the server
+// fails preparing, like there's no route registered. The client has prefix "http://localhost" and browser mode enabled, so the cookies and sessions work
+// across requests. Note that the response is buffered until the handler is done, and the upgrade requests
+// like WebSocket are served through in-memory connections.
+func (s *Server) TestClient() (*gclient.Client, error) {
+	if err := s.prepare(context.Background()); err != nil {
+		return nil, err
+	}
+	client := gclient.New()
+	client.Transport = &testTransport{
+		server: s,
+		upgrading: &http.Transport{
+			DialContext:       s.TestDialContext,
+			DisableKeepAlives: true,
+		},
+	}
+	client.SetBrowserMode(true)
+	client.SetPrefix("http://" + TestClientHost)
+	return client, nil
+}
+
+// TestDialContext dials and returns an in-memory connection to the server, which is served with standard
+// HTTP protocol. It can be used for the clients that dial connections themselves, like WebSocket dialer:
+// `websocket.Dialer{NetDialContext: s.TestDialContext}`.
+func (s *Server) TestDialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	if err := s.prepare(ctx); err != nil {
+		return nil, err
+	}
+	s.testMu.Lock()
+	if s.testListener == nil {
+		s.testListener = &testListener{
+			conns:  make(chan net.Conn),
+			closed: make(chan struct{}),
+		}
+		s.testServer = &http.Server{Handler: http.HandlerFunc(s.config.Handler)}
+		go func(server *http.Server, listener *testListener) {
+			_ = server.Serve(listener)
+		}(s.testServer, s.testListener)
+	}
+	listener := s.testListener
+	s.testMu.Unlock()
+
+	serverConn, clientConn := net.Pipe()
+	select {
+	case listener.conns <- serverConn:
+		return clientConn, nil
+	case <-listener.closed:
+		_ = serverConn.Close()
+		_ = clientConn.Close()
+		return nil, gerror.NewCode(gcode.CodeInvalidOperation, "test listener is closed")
+	case <-ctx.Done():
+		_ = serverConn.Close()
+		_ = clientConn.Close()
+		return nil, ctx.Err()
+	}
+}
+
+// shutdownTestServer closes the in-memory listener and gracefully shuts down the in-memory server
+// of TestDialContext. The new test connections are refused after it is called.
+func (s *Server) shutdownTestServer(ctx context.Context) {
+	s.testMu.Lock()
+	defer s.testMu.Unlock()
+	if s.testListener == nil {
+		return
+	}
+	_ = s.testListener.Close()
+	if err := s.testServer.Shutdown(ctx); err != nil {
+		s.Logger().Errorf(ctx, `%+v`, err)
+	}
+}
+
+// closeTestServer closes the in-memory listener and server of TestDialContext immediately.
+func (s *Server) closeTestServer() {
+	s.testMu.Lock()
+	defer s.testMu.Unlock()
+	if s.testListener == nil {
+		return
+	}
+	_ = s.testListener.Close()
+	_ = s.testServer.Close()
+}
+
+// GetTestRecord retrieves and returns the recorded response of `resp` that is returned by TestClient.
+// It returns nil if `resp` is not returned by TestClient.
+func GetTestRecord(resp *gclient.Response) *TestRecord {
+	if resp == nil || resp.Response == nil {
+		return nil
+	}
+	if body, ok := resp.Response.Body.(*testRecordBody); ok {
+		return body.record
+	}
+	return nil
+}
+
+// RoundTrip implements interface http.RoundTripper.
+func (t *testTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if strings.EqualFold(req.Header.Get("Connection"), "upgrade") || req.Header.Get("Upgrade") != "" {
+		return t.upgrading.RoundTrip(req)
+	}
+	if err := t.server.prepare(req.Context()); err != nil {
+		return nil, err
+	}
+	var (
+		record    = &TestRecord{}
+		recorder  = &testResponseRecorder{header: make(http.Header)}
+		serverReq = req.Clone(context.WithValue(req.Context(), testRecordCtxKey{}, record))
+	)
+	serverReq.RemoteAddr = testClientRemoteAddr
+	serverReq.RequestURI = req.URL.RequestURI()
+	if serverReq.Body == nil {
+		serverReq.Body = http.NoBody
+	}
+	if serverReq.Host == "" {
+		serverReq.Host = req.URL.Host
+	}
+	t.server.config.Handler(recorder, serverReq)
+
+	if recorder.status == 0 {
+		recorder.status = http.StatusOK
+	}
+	record.Status = recorder.status
+	record.Header = recorder.header.Clone()
+	record.Body = recorder.body.Bytes()
+	resp := &http.Response{
+		Status:        http.StatusText(recorder.status),
+		StatusCode:    recorder.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recorder.header.Clone(),
+		ContentLength: int64(recorder.body.Len()),
+		Request:       req,
+		Body: &testRecordBody{
+			ReadCloser: io.NopCloser(bytes.NewReader(record.Body)),
+			record:     record,
+		},
+	}
+	return resp, nil
+}
+
+// Header implements interface http.ResponseWriter.
+func (w *testResponseRecorder) Header() http.Header {
+	return w.header
+}
+
+// Write implements interface http.ResponseWriter.
+func (w *testResponseRecorder) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.body.Write(b)
+}
+
+// WriteHeader implements interface http.ResponseWriter.
+func (w *testResponseRecorder) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+// Flush implements interface http.Flusher, which does nothing as the response is buffered.
+func (w *testResponseRecorder) Flush() {}
+
+// Accept implements interface net.Listener.
+func (l *testListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close implements interface net.Listener.
+func (l *testListener) Close() error {
+	l.closeOnce.Do(func() {
+		close(l.closed)
+	})
+	return nil
+}
+
+// Addr implements interface net.Listener.
+func (l *testListener) Addr() net.Addr {
+	return testAddr{}
+}
+
+// Network implements interface net.Addr.
+func (testAddr) Network() string {
+	return "memory"
+}
+
+// String implements interface net.Addr.
+func (testAddr) String() string {
+	return testClientRemoteAddr
+}
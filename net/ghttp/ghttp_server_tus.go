@@ -0,0 +1,460 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package ghttp
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gogf/gf/v2/container/gtype"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/os/gfile"
+	"github.com/gogf/gf/v2/os/gmlock"
+	"github.com/gogf/gf/v2/util/guid"
+)
+
+// TusOptions is the options for resumable uploads handler.
+type TusOptions struct {
+	// Storage specifies the storage backend for uploads.
+	// It uses local file storage in the temporary directory in default.
+	Storage TusStorage
+	// MaxSize specifies the maximum size in bytes of an upload, it's unlimited in default.
+	MaxSize int64
+	// Expiration specifies the expiration of incomplete uploads, it's 24 hours in default.
+	// Negative value means the uploads never expire.
+	Expiration time.Duration
+	// OnComplete is called when an upload is completed, which hands the finished file to the application.
+	// If it returns error, the request is responded with HTTP status 500.
+	OnComplete func(r *Request, info *TusUploadInfo) error
+}
+
+// TusHandler is the handler for resumable uploads implementing the tus protocol v1.0.0,
+// with extensions: creation, creation-with-upload, expiration, checksum and termination.
+// See https://tus.io/protocols/resumable-upload.
+type TusHandler struct {
+	options       TusOptions
+	lastCleanTime *gtype.Int64 // Last timestamp in seconds cleaning the expired uploads.
+}
+
+const (
+	tusVersion                = "1.0.0"
+	tusExtensions             = "creation,creation-with-upload,expiration,checksum,termination"
+	tusChecksumAlgorithms     = "md5,sha1,sha256,sha512"
+	tusContentType            = "application/offset+octet-stream"
+	tusStatusChecksumMismatch = 460
+	tusDefaultExpiration      = 24 * time.Hour
+	tusCleanInterval          = 60
+	tusLockKeyPrefix          = "ghttp:tus:"
+)
+
+// NewTusHandler creates and returns a resumable uploads handler.
+func NewTusHandler(options ...TusOptions) *TusHandler {
+	var option TusOptions
+	if len(options) > 0 {
+		option = options[0]
+	}
+	if option.Storage == nil {
+		option.Storage = NewTusStorageFile(gfile.Temp("gf-tus"))
+	}
+	if option.Expiration == 0 {
+		option.Expiration = tusDefaultExpiration
+	}
+	return &TusHandler{
+		options:       option,
+		lastCleanTime: gtype.NewInt64(),
+	}
+}
+
+// BindTus binds resumable uploads handler to `pattern`, which creates uploads by POST `pattern`,
+// and resumes the uploads by `pattern/{id}`. It returns the created handler.
+//
+// Note that the request body size is limited by server configuration ClientMaxBodySize,
+// the client should upload the file in chunks that do not exceed the limit, or else
+// it resumes the uploading from the offset that the server has received.
+// The handler should not be used with MiddlewareHandlerResponse, which changes the response content.
+func (s *Server) BindTus(pattern string, options ...TusOptions) *TusHandler {
+	var (
+		handler                = NewTusHandler(options...)
+		domain, method, uri, _ = s.parsePattern(pattern)
+	)
+	uri = strings.TrimRight(uri, "/")
+	pattern = method + ":" + uri + "@" + domain
+	s.BindHandler(pattern, handler.Handle)
+	s.BindHandler(method+":"+uri+"/{tus_upload_id}@"+domain, handler.Handle)
+	return handler
+}
+
+// Storage returns the storage backend of the handler.
+func (h *TusHandler) Storage() TusStorage {
+	return h.options.Storage
+}
+
+// Handle is the HandlerFunc handling the tus protocol requests. It can be bound to routes
+// manually, like `/files/*`, in which the upload id is the last part of the request path.
+func (h *TusHandler) Handle(r *Request) {
+	header := r.Response.Header()
+	header.Set("Tus-Resumable", tusVersion)
+	header.Set("Cache-Control", "no-store")
+	if r.Method == http.MethodOptions {
+		header.Set("Tus-Version", tusVersion)
+		header.Set("Tus-Extension", tusExtensions)
+		header.Set("Tus-Checksum-Algorithm", tusChecksumAlgorithms)
+		if h.options.MaxSize > 0 {
+			header.Set("Tus-Max-Size", strconv.FormatInt(h.options.MaxSize, 10))
+		}
+		r.Response.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if r.Header.Get("Tus-Resumable") != tusVersion {
+		header.Set("Tus-Version", tusVersion)
+		r.Response.WriteStatus(http.StatusPreconditionFailed, "unsupported tus version")
+		return
+	}
+	method := r.Method
+	if v := r.Header.Get("X-HTTP-Method-Override"); v != "" {
+		method = strings.ToUpper(v)
+	}
+	switch method {
+	case http.MethodPost:
+		h.doCreate(r)
+	case http.MethodHead:
+		h.doHead(r)
+	case http.MethodPatch:
+		h.doPatch(r)
+	case http.MethodDelete:
+		h.doTerminate(r)
+	default:
+		r.Response.WriteStatus(http.StatusMethodNotAllowed)
+	}
+}
+
+// doCreate handles the creation request, which also writes the content if it is creation-with-upload.
+func (h *TusHandler) doCreate(r *Request) {
+	var (
+		ctx        = r.Context()
+		sizeHeader = r.Header.Get("Upload-Length")
+	)
+	if sizeHeader == "" {
+		r.Response.WriteStatus(http.StatusBadRequest, "missing header Upload-Length")
+		return
+	}
+	size, err := strconv.ParseInt(sizeHeader, 10, 64)
+	if err != nil || size < 0 {
+		r.Response.WriteStatus(http.StatusBadRequest, "invalid header Upload-Length")
+		return
+	}
+	if h.options.MaxSize > 0 && size > h.options.MaxSize {
+		r.Response.WriteStatus(http.StatusRequestEntityTooLarge)
+		return
+	}
+	metadata, ok := parseTusMetadata(r.Header.Get("Upload-Metadata"))
+	if !ok {
+		r.Response.WriteStatus(http.StatusBadRequest, "invalid header Upload-Metadata")
+		return
+	}
+	h.cleanExpired(r)
+	info := &TusUploadInfo{
+		Id:        guid.S(),
+		Size:      size,
+		Metadata:  metadata,
+		CreatedAt: time.Now(),
+	}
+	if h.options.Expiration > 0 {
+		info.ExpiresAt = info.CreatedAt.Add(h.options.Expiration)
+	}
+	if err = h.options.Storage.Create(ctx, info); err != nil {
+		h.writeError(r, err)
+		return
+	}
+	info.storage = h.options.Storage
+	r.Response.Header().Set("Location", strings.TrimRight(r.URL.Path, "/")+"/"+info.Id)
+	// Creation with upload.
+	if r.Header.Get("Content-Type") == tusContentType && r.ContentLength != 0 {
+		if !h.doWrite(r, info) {
+			return
+		}
+	}
+	h.writeUploadHeader(r, info)
+	r.Response.WriteHeader(http.StatusCreated)
+}
+
+// doHead handles the request retrieving the upload offset.
+func (h *TusHandler) doHead(r *Request) {
+	info := h.getUpload(r)
+	if info == nil {
+		return
+	}
+	header := r.Response.Header()
+	header.Set("Upload-Length", strconv.FormatInt(info.Size, 10))
+	if len(info.Metadata) > 0 {
+		header.Set("Upload-Metadata", formatTusMetadata(info.Metadata))
+	}
+	h.writeUploadHeader(r, info)
+	r.Response.WriteHeader(http.StatusOK)
+}
+
+// doPatch handles the request appending content to the upload.
+func (h *TusHandler) doPatch(r *Request) {
+	if r.Header.Get("Content-Type") != tusContentType {
+		r.Response.WriteStatus(http.StatusUnsupportedMediaType)
+		return
+	}
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		r.Response.WriteStatus(http.StatusBadRequest, "invalid header Upload-Offset")
+		return
+	}
+	var (
+		id      = h.getUploadId(r)
+		lockKey = tusLockKeyPrefix + id
+	)
+	// Concurrent writing to the same upload is not allowed.
+	if !gmlock.TryLock(lockKey) {
+		r.Response.WriteStatus(http.StatusLocked)
+		return
+	}
+	defer gmlock.Unlock(lockKey)
+
+	info := h.getUpload(r)
+	if info == nil {
+		return
+	}
+	if info.Offset != offset {
+		r.Response.WriteStatus(http.StatusConflict, "mismatched Upload-Offset")
+		return
+	}
+	if !h.doWrite(r, info) {
+		return
+	}
+	h.writeUploadHeader(r, info)
+	r.Response.WriteHeader(http.StatusNoContent)
+}
+
+// doTerminate handles the request terminating the upload.
+func (h *TusHandler) doTerminate(r *Request) {
+	lockKey := tusLockKeyPrefix + h.getUploadId(r)
+	// The upload being written cannot be terminated.
+	if !gmlock.TryLock(lockKey) {
+		r.Response.WriteStatus(http.StatusLocked)
+		return
+	}
+	defer gmlock.Unlock(lockKey)
+
+	info := h.getUpload(r)
+	if info == nil {
+		return
+	}
+	if err := h.options.Storage.Delete(r.Context(), info.Id); err != nil {
+		h.writeError(r, err)
+		return
+	}
+	r.Response.WriteHeader(http.StatusNoContent)
+}
+
+// doWrite writes the request body to the upload with checksum verification, and calls the
+// completion hook if the upload is completed. It returns false if the request is already responded.
+func (h *TusHandler) doWrite(r *Request, info *TusUploadInfo) bool {
+	var (
+		ctx    = r.Context()
+		reader = io.Reader(r.Body)
+	)
+	if r.ContentLength > 0 && info.Offset+r.ContentLength > info.Size {
+		r.Response.WriteStatus(http.StatusRequestEntityTooLarge)
+		return false
+	}
+	// It does not write more than the upload size.
+	reader = io.LimitReader(reader, info.Size-info.Offset)
+	if checksum := r.Header.Get("Upload-Checksum"); checksum != "" {
+		verifiedReader, status := h.verifyChecksum(r, reader, checksum)
+		if status != http.StatusOK {
+			r.Response.WriteStatus(status)
+			return false
+		}
+		defer verifiedReader.Close()
+		reader = verifiedReader
+	}
+	n, err := h.options.Storage.Write(ctx, info.Id, info.Offset, reader)
+	if err != nil {
+		// The written content is kept, and the client can resume the uploading
+		// with the offset retrieved by HEAD request.
+		h.writeError(r, gerror.Wrapf(err, `write upload "%s" failed`, info.Id))
+		return false
+	}
+	info.Offset += n
+	if info.IsCompleted() && h.options.OnComplete != nil {
+		if err = h.options.OnComplete(r, info); err != nil {
+			h.writeError(r, err)
+			return false
+		}
+	}
+	return true
+}
+
+// verifyChecksum reads the chunk content from `reader` to a temporary file and verifies its checksum
+// with header `Upload-Checksum`. It returns the reader of the temporary file that is deleted when
+// it is closed, and the HTTP status of the verification.
+func (h *TusHandler) verifyChecksum(r *Request, reader io.Reader, checksum string) (io.ReadCloser, int) {
+	var (
+		algorithm, encoded, _ = strings.Cut(checksum, " ")
+		hasher                hash.Hash
+	)
+	switch algorithm {
+	case "md5":
+		hasher = md5.New()
+	case "sha1":
+		hasher = sha1.New()
+	case "sha256":
+		hasher = sha256.New()
+	case "sha512":
+		hasher = sha512.New()
+	default:
+		return nil, http.StatusBadRequest
+	}
+	expected, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, http.StatusBadRequest
+	}
+	file, err := os.CreateTemp("", "gf-tus-chunk-*")
+	if err != nil {
+		r.Server.Logger().Errorf(r.Context(), `%+v`, err)
+		return nil, http.StatusInternalServerError
+	}
+	tmpFile := &tusTempFile{file}
+	if _, err = io.Copy(io.MultiWriter(file, hasher), reader); err != nil {
+		_ = tmpFile.Close()
+		return nil, http.StatusBadRequest
+	}
+	if !bytes.Equal(hasher.Sum(nil), expected) {
+		_ = tmpFile.Close()
+		return nil, tusStatusChecksumMismatch
+	}
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		_ = tmpFile.Close()
+		r.Server.Logger().Errorf(r.Context(), `%+v`, err)
+		return nil, http.StatusInternalServerError
+	}
+	return tmpFile, http.StatusOK
+}
+
+// getUploadId returns the upload id from the last part of the request path.
+func (h *TusHandler) getUploadId(r *Request) string {
+	return gfile.Basename(strings.TrimRight(r.URL.Path, "/"))
+}
+
+// getUpload retrieves and returns the upload of current request.
+// It returns nil if the request is already responded, like the upload does not exist.
+func (h *TusHandler) getUpload(r *Request) *TusUploadInfo {
+	var (
+		ctx = r.Context()
+		id  = h.getUploadId(r)
+	)
+	info, err := h.options.Storage.Get(ctx, id)
+	if err != nil {
+		h.writeError(r, err)
+		return nil
+	}
+	if info == nil {
+		r.Response.WriteStatus(http.StatusNotFound)
+		return nil
+	}
+	if info.IsExpired() {
+		if err = h.options.Storage.Delete(ctx, id); err != nil {
+			r.Server.Logger().Errorf(ctx, `%+v`, err)
+		}
+		r.Response.WriteStatus(http.StatusGone)
+		return nil
+	}
+	info.storage = h.options.Storage
+	return info
+}
+
+// writeUploadHeader writes the offset and expiration header of upload to response.
+func (h *TusHandler) writeUploadHeader(r *Request, info *TusUploadInfo) {
+	header := r.Response.Header()
+	header.Set("Upload-Offset", strconv.FormatInt(info.Offset, 10))
+	if !info.IsCompleted() && !info.ExpiresAt.IsZero() {
+		header.Set("Upload-Expires", info.ExpiresAt.UTC().Format(http.TimeFormat))
+	}
+}
+
+// writeError writes the internal error to response.
+func (h *TusHandler) writeError(r *Request, err error) {
+	r.Response.WriteHeader(http.StatusInternalServerError)
+	r.SetError(err)
+}
+
+// cleanExpired deletes the expired uploads if the storage supports, at most once per minute.
+func (h *TusHandler) cleanExpired(r *Request) {
+	cleaner, ok := h.options.Storage.(TusStorageCleaner)
+	if !ok {
+		return
+	}
+	var (
+		now  = time.Now().Unix()
+		last = h.lastCleanTime.Val()
+	)
+	if now-last < tusCleanInterval || !h.lastCleanTime.Cas(last, now) {
+		return
+	}
+	if err := cleaner.CleanExpired(r.Context()); err != nil {
+		r.Server.Logger().Errorf(r.Context(), `%+v`, err)
+	}
+}
+
+// tusTempFile is the temporary file that is deleted when it is closed.
+type tusTempFile struct {
+	*os.File
+}
+
+// Close closes and deletes the temporary file.
+func (f *tusTempFile) Close() error {
+	err := f.File.Close()
+	_ = os.Remove(f.File.Name())
+	return err
+}
+
+// parseTusMetadata parses header `Upload-Metadata` like `filename d29ybGQ=,is_confidential`.
+func parseTusMetadata(header string) (map[string]string, bool) {
+	metadata := make(map[string]string)
+	for _, pair := range strings.Split(header, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(pair, " ")
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, false
+		}
+		metadata[key] = string(decoded)
+	}
+	return metadata, true
+}
+
+// formatTusMetadata formats `metadata` as header `Upload-Metadata`.
+func formatTusMetadata(metadata map[string]string) string {
+	pairs := make([]string, 0, len(metadata))
+	for key, value := range metadata {
+		if value == "" {
+			pairs = append(pairs, key)
+		} else {
+			pairs = append(pairs, key+" "+base64.StdEncoding.EncodeToString([]byte(value)))
+		}
+	}
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,253 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package ghttp
+
+import (
+	"context"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/internal/json"
+	"github.com/gogf/gf/v2/os/gfile"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gogf/gf/v2/util/grand"
+)
+
+// TusStorage is the storage backend interface for resumable uploads.
+type TusStorage interface {
+	// Create creates a new upload with given `info`, the field `Id` is already generated.
+	Create(ctx context.Context, info *TusUploadInfo) error
+
+	// Get retrieves and returns the upload information of `id`, in which the field `Offset`
+	// should be the current size of the stored content. It returns nil if the upload does not exist.
+	Get(ctx context.Context, id string) (*TusUploadInfo, error)
+
+	// Write appends content from `reader` to the upload of `id` at `offset`, and returns the written
+	// size. Note that the written content should be kept even if the reading of `reader` fails,
+	// as the client can resume the uploading from the new offset.
+	Write(ctx context.Context, id string, offset int64, reader io.Reader) (int64, error)
+
+	// Open opens and returns the stored content of upload `id` for reading.
+	Open(ctx context.Context, id string) (io.ReadCloser, error)
+
+	// Delete deletes the upload of `id` and its stored content.
+	Delete(ctx context.Context, id string) error
+}
+
+// TusStorageCleaner is the optional interface for TusStorage, which deletes all the expired uploads.
+// It is called by TusHandler when new upload is created, at most once per minute.
+type TusStorageCleaner interface {
+	CleanExpired(ctx context.Context) error
+}
+
+// TusUploadInfo is the information of a resumable upload.
+type TusUploadInfo struct {
+	Id        string            `json:"id"`        // Unique id of the upload.
+	Size      int64             `json:"size"`      // Total size of the upload.
+	Offset    int64             `json:"offset"`    // Current uploaded size.
+	Metadata  map[string]string `json:"metadata"`  // Metadata from header `Upload-Metadata`, like filename and filetype.
+	CreatedAt time.Time         `json:"createdAt"` // Created time.
+	ExpiresAt time.Time         `json:"expiresAt"` // Expiration time, which is zero if it never expires.
+	storage   TusStorage        // Storage of the upload, which is used by Open and Save.
+}
+
+// TusStorageFile is the TusStorage implementer using local files.
+// Each upload is stored as two files in the directory: `{id}.bin` for content and `{id}.info` for information.
+type TusStorageFile struct {
+	path string // Directory path storing the uploads.
+}
+
+const (
+	tusStorageFileContentExt = ".bin"
+	tusStorageFileInfoExt    = ".info"
+)
+
+// NewTusStorageFile creates and returns a local file storage for resumable uploads in directory `path`.
+func NewTusStorageFile(path string) *TusStorageFile {
+	return &TusStorageFile{path: path}
+}
+
+// Path returns the content file path of upload `id`.
+func (s *TusStorageFile) Path(id string) string {
+	return gfile.Join(s.path, id+tusStorageFileContentExt)
+}
+
+// Create implements interface TusStorage.
+func (s *TusStorageFile) Create(ctx context.Context, info *TusUploadInfo) error {
+	if !gfile.Exists(s.path) {
+		if err := gfile.Mkdir(s.path); err != nil {
+			return err
+		}
+	}
+	content, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	if err = gfile.PutBytes(s.infoPath(info.Id), content); err != nil {
+		return err
+	}
+	return gfile.PutBytes(s.Path(info.Id), nil)
+}
+
+// Get implements interface TusStorage.
+func (s *TusStorageFile) Get(ctx context.Context, id string) (*TusUploadInfo, error) {
+	if !isValidTusUploadId(id) || !gfile.Exists(s.infoPath(id)) {
+		return nil, nil
+	}
+	var info *TusUploadInfo
+	if err := json.Unmarshal(gfile.GetBytes(s.infoPath(id)), &info); err != nil {
+		return nil, err
+	}
+	info.Offset = gfile.Size(s.Path(id))
+	return info, nil
+}
+
+// Write implements interface TusStorage.
+func (s *TusStorageFile) Write(ctx context.Context, id string, offset int64, reader io.Reader) (int64, error) {
+	file, err := gfile.OpenFile(s.Path(id), os.O_WRONLY, gfile.DefaultPermOpen)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	if _, err = file.Seek(offset, io.SeekStart); err != nil {
+		return 0, gerror.Wrapf(err, `seek file "%s" to offset %d failed`, s.Path(id), offset)
+	}
+	return io.Copy(file, reader)
+}
+
+// Open implements interface TusStorage.
+func (s *TusStorageFile) Open(ctx context.Context, id string) (io.ReadCloser, error) {
+	if !isValidTusUploadId(id) {
+		return nil, gerror.NewCodef(gcode.CodeInvalidParameter, `invalid upload id "%s"`, id)
+	}
+	return gfile.Open(s.Path(id))
+}
+
+// Delete implements interface TusStorage.
+func (s *TusStorageFile) Delete(ctx context.Context, id string) error {
+	if !isValidTusUploadId(id) {
+		return nil
+	}
+	if err := gfile.RemoveFile(s.Path(id)); err != nil && gfile.Exists(s.Path(id)) {
+		return err
+	}
+	if err := gfile.RemoveFile(s.infoPath(id)); err != nil && gfile.Exists(s.infoPath(id)) {
+		return err
+	}
+	return nil
+}
+
+// CleanExpired implements interface TusStorageCleaner.
+func (s *TusStorageFile) CleanExpired(ctx context.Context) error {
+	if !gfile.Exists(s.path) {
+		return nil
+	}
+	files, err := gfile.ScanDirFile(s.path, "*"+tusStorageFileInfoExt)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		id := strings.TrimSuffix(gfile.Basename(file), tusStorageFileInfoExt)
+		info, err := s.Get(ctx, id)
+		if err != nil || info == nil {
+			continue
+		}
+		if info.IsExpired() {
+			if err = s.Delete(ctx, id); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// infoPath returns the information file path of upload `id`.
+func (s *TusStorageFile) infoPath(id string) string {
+	return gfile.Join(s.path, id+tusStorageFileInfoExt)
+}
+
+// IsCompleted checks and returns whether the upload is completed.
+func (info *TusUploadInfo) IsCompleted() bool {
+	return info.Offset >= info.Size
+}
+
+// IsExpired checks and returns whether the upload is expired.
+// Note that the completed upload never expires.
+func (info *TusUploadInfo) IsExpired() bool {
+	return !info.IsCompleted() && !info.ExpiresAt.IsZero() && time.Now().After(info.ExpiresAt)
+}
+
+// Filename returns the file name from metadata "filename" or "name".
+func (info *TusUploadInfo) Filename() string {
+	if name := info.Metadata["filename"]; name != "" {
+		return name
+	}
+	return info.Metadata["name"]
+}
+
+// Open opens and returns the stored content of the upload for reading.
+func (info *TusUploadInfo) Open(ctx context.Context) (io.ReadCloser, error) {
+	if info.storage == nil {
+		return nil, gerror.NewCode(gcode.CodeInvalidOperation, `upload storage is not bound`)
+	}
+	return info.storage.Open(ctx, info.Id)
+}
+
+// Save saves the stored content of the upload to directory `dirPath`, with the file name from metadata,
+// or the upload id if the file name is not given. It returns the saved file name.
+//
+// The parameter `randomlyRename` specifies whether randomly renames the file name.
+func (info *TusUploadInfo) Save(ctx context.Context, dirPath string, randomlyRename ...bool) (filename string, err error) {
+	if !gfile.Exists(dirPath) {
+		if err = gfile.Mkdir(dirPath); err != nil {
+			return
+		}
+	} else if !gfile.IsDir(dirPath) {
+		return "", gerror.NewCode(gcode.CodeInvalidParameter, `parameter "dirPath" should be a directory path`)
+	}
+	reader, err := info.Open(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	name := gfile.Basename(info.Filename())
+	if name == "" || name == "." || name == "/" {
+		name = info.Id
+	}
+	if len(randomlyRename) > 0 && randomlyRename[0] {
+		name = strings.ToLower(strconv.FormatInt(gtime.TimestampNano(), 36)+grand.S(6)) + gfile.Ext(name)
+	}
+	filePath := gfile.Join(dirPath, name)
+	newFile, err := gfile.Create(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer newFile.Close()
+	if _, err = io.Copy(newFile, reader); err != nil {
+		return "", gerror.Wrapf(err, `io.Copy failed from upload "%s" to "%s"`, info.Id, filePath)
+	}
+	return gfile.Basename(filePath), nil
+}
+
+// isValidTusUploadId checks whether `id` is valid upload id, which avoids path traversal.
+func isValidTusUploadId(id string) bool {
+	if id == "" {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
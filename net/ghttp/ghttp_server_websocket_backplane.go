@@ -0,0 +1,100 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package ghttp
+
+import (
+	"context"
+	"time"
+
+	"github.com/gogf/gf/v2/database/gredis"
+	"github.com/gogf/gf/v2/internal/intlog"
+	"github.com/gogf/gf/v2/internal/json"
+)
+
+// WebSocketBackplane is the message bus among the WebSocketHub of multiple instances,
+// which fans out the messages broadcast by any instance to all instances.
+type WebSocketBackplane interface {
+	// Publish publishes `payload` to all subscribers, including the publisher itself.
+	Publish(ctx context.Context, payload []byte) error
+
+	// Subscribe subscribes the messages and calls `handler` for each message in background,
+	// until `ctx` is done.
+	Subscribe(ctx context.Context, handler func(ctx context.Context, payload []byte)) error
+}
+
+// WebSocketBackplaneRedis is the WebSocketBackplane using redis pub/sub.
+type WebSocketBackplaneRedis struct {
+	redis   *gredis.Redis
+	channel string
+}
+
+// webSocketBackplaneMessage is the message transferred by backplane.
+type webSocketBackplaneMessage struct {
+	Origin  string          `json:"origin"`            // Hub id publishing the message.
+	Kind    string          `json:"kind"`              // Target kind: all, room, user, conn.
+	Target  string          `json:"target,omitempty"`  // Room, user id or connection id.
+	Exclude []string        `json:"exclude,omitempty"` // Excluded connection ids.
+	Payload json.RawMessage `json:"payload"`           // Encoded WebSocketMessage.
+}
+
+const (
+	webSocketTargetAll  = "all"
+	webSocketTargetRoom = "room"
+	webSocketTargetUser = "user"
+	webSocketTargetConn = "conn"
+
+	// DefaultWebSocketBackplaneChannel is the default redis channel of WebSocketBackplaneRedis.
+	DefaultWebSocketBackplaneChannel = "gf:websocket:hub"
+)
+
+// NewWebSocketBackplaneRedis creates and returns a backplane using redis pub/sub on `channel`,
+// which is DefaultWebSocketBackplaneChannel in default.
+func NewWebSocketBackplaneRedis(redis *gredis.Redis, channel ...string) *WebSocketBackplaneRedis {
+	b := &WebSocketBackplaneRedis{
+		redis:   redis,
+		channel: DefaultWebSocketBackplaneChannel,
+	}
+	if len(channel) > 0 && channel[0] != "" {
+		b.channel = channel[0]
+	}
+	return b
+}
+
+// Publish implements interface WebSocketBackplane.
+func (b *WebSocketBackplaneRedis) Publish(ctx context.Context, payload []byte) error {
+	_, err := b.redis.Publish(ctx, b.channel, string(payload))
+	return err
+}
+
+// Subscribe implements interface WebSocketBackplane.
+func (b *WebSocketBackplaneRedis) Subscribe(
+	ctx context.Context, handler func(ctx context.Context, payload []byte),
+) error {
+	conn, _, err := b.redis.Subscribe(ctx, b.channel)
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close(context.Background())
+	}()
+	go func() {
+		for {
+			msg, err := conn.ReceiveMessage(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				intlog.Errorf(ctx, `%+v`, err)
+				time.Sleep(time.Second)
+				continue
+			}
+			handler(ctx, []byte(msg.Payload))
+		}
+	}()
+	return nil
+}
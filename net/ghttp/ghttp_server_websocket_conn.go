@@ -0,0 +1,207 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package ghttp
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/gogf/gf/v2/container/gset"
+	"github.com/gogf/gf/v2/container/gtype"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+)
+
+// WebSocketConn is the connection managed by WebSocketHub.
+type WebSocketConn struct {
+	hub       *WebSocketHub
+	conn      *websocket.Conn
+	id        string
+	userId    *gtype.String
+	request   *Request
+	ctx       context.Context
+	cancel    context.CancelFunc
+	rooms     *gset.StrSet
+	send      chan []byte   // Outgoing messages, which are written by the writing goroutine.
+	done      chan struct{} // Closed when the connection is closing.
+	closeOnce sync.Once
+}
+
+// ctxKeyForWebSocketConn is the context key for WebSocketConn.
+type ctxKeyForWebSocketConn struct{}
+
+// WebSocketConnFromCtx retrieves and returns the WebSocketConn from context,
+// which is available in the message handlers of WebSocketHub.
+func WebSocketConnFromCtx(ctx context.Context) *WebSocketConn {
+	if v := ctx.Value(ctxKeyForWebSocketConn{}); v != nil {
+		return v.(*WebSocketConn)
+	}
+	return nil
+}
+
+// Id returns the unique id of the connection.
+func (c *WebSocketConn) Id() string {
+	return c.id
+}
+
+// UserId returns the user id of the connection, which is set by SetUserId.
+func (c *WebSocketConn) UserId() string {
+	return c.userId.Val()
+}
+
+// SetUserId sets the user id of the connection, so that messages can be sent to all connections of the user.
+func (c *WebSocketConn) SetUserId(userId string) {
+	c.hub.setUserId(c, userId)
+}
+
+// Request returns the request upgraded to the connection.
+func (c *WebSocketConn) Request() *Request {
+	return c.request
+}
+
+// Context returns the context of the connection, which is done when the connection is closed.
+func (c *WebSocketConn) Context() context.Context {
+	return c.ctx
+}
+
+// Join makes the connection join `rooms`.
+func (c *WebSocketConn) Join(rooms ...string) {
+	c.hub.join(c, rooms...)
+}
+
+// Leave makes the connection leave `rooms`.
+func (c *WebSocketConn) Leave(rooms ...string) {
+	c.hub.leave(c, rooms...)
+}
+
+// Rooms returns the rooms that the connection joins.
+func (c *WebSocketConn) Rooms() []string {
+	return c.rooms.Slice()
+}
+
+// Send sends message of `msgType` with `data` to the connection.
+func (c *WebSocketConn) Send(msgType string, data interface{}) error {
+	content, err := encodeWebSocketMessage(msgType, "", data, nil)
+	if err != nil {
+		return err
+	}
+	return c.SendRaw(content)
+}
+
+// SendRaw sends raw text message `content` to the connection.
+//
+// The message is queued and written asynchronously. If the queue is full as the client
+// consumes slowly, the message is dropped with error if option DropOnFull is true,
+// or else the connection is closed.
+func (c *WebSocketConn) SendRaw(content []byte) error {
+	select {
+	case <-c.done:
+		return gerror.NewCodef(gcode.CodeInvalidOperation, `websocket connection "%s" is closed`, c.id)
+	default:
+	}
+	select {
+	case c.send <- content:
+		return nil
+	case <-c.done:
+		return gerror.NewCodef(gcode.CodeInvalidOperation, `websocket connection "%s" is closed`, c.id)
+	default:
+	}
+	if c.hub.options.DropOnFull {
+		return gerror.NewCodef(gcode.CodeServerBusy, `websocket connection "%s" is busy, message dropped`, c.id)
+	}
+	c.Close()
+	return gerror.NewCodef(gcode.CodeServerBusy, `websocket connection "%s" is too slow, closed`, c.id)
+}
+
+// Close closes the connection.
+func (c *WebSocketConn) Close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.cancel()
+		c.hub.unregister(c)
+	})
+}
+
+// IsClosed checks and returns whether the connection is closed.
+func (c *WebSocketConn) IsClosed() bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// readLoop reads and dispatches messages until the connection is closed.
+func (c *WebSocketConn) readLoop() {
+	var options = c.hub.options
+	if options.MaxMessageSize > 0 {
+		c.conn.SetReadLimit(options.MaxMessageSize)
+	}
+	_ = c.conn.SetReadDeadline(time.Now().Add(options.PongTimeout))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(options.PongTimeout))
+	})
+	for {
+		msgType, content, err := c.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) &&
+				!c.IsClosed() && options.OnError != nil {
+				options.OnError(c, err)
+			}
+			return
+		}
+		_ = c.conn.SetReadDeadline(time.Now().Add(options.PongTimeout))
+		if msgType != websocket.TextMessage && msgType != websocket.BinaryMessage {
+			continue
+		}
+		c.hub.dispatch(c, content)
+	}
+}
+
+// writeLoop writes queued messages and heartbeats until the connection is closed.
+func (c *WebSocketConn) writeLoop() {
+	var (
+		options = c.hub.options
+		ticker  *time.Ticker
+		tickC   <-chan time.Time
+	)
+	defer c.conn.Close()
+	if options.PingInterval > 0 {
+		ticker = time.NewTicker(options.PingInterval)
+		tickC = ticker.C
+		defer ticker.Stop()
+	}
+	for {
+		select {
+		case content := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(options.WriteTimeout))
+			if err := c.conn.WriteMessage(websocket.TextMessage, content); err != nil {
+				c.Close()
+				return
+			}
+
+		case <-tickC:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(options.WriteTimeout))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				c.Close()
+				return
+			}
+
+		case <-c.done:
+			_ = c.conn.WriteControl(
+				websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+				time.Now().Add(options.WriteTimeout),
+			)
+			return
+		}
+	}
+}
@@ -0,0 +1,576 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package ghttp
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/gogf/gf/v2/container/gset"
+	"github.com/gogf/gf/v2/container/gtype"
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/internal/intlog"
+	"github.com/gogf/gf/v2/internal/json"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/gogf/gf/v2/util/guid"
+	"github.com/gogf/gf/v2/util/gutil"
+	"github.com/gogf/gf/v2/util/gvalid"
+)
+
+// WebSocketHubOptions is the options for WebSocketHub.
+type WebSocketHubOptions struct {
+	PingInterval   time.Duration // Interval sending ping to clients, 30 seconds in default. A negative value disables ping.
+	PongTimeout    time.Duration // Connection is closed if nothing including pong is received in time, 60 seconds in default.
+	WriteTimeout   time.Duration // Timeout writing a message, 10 seconds in default.
+	SendBufferSize int           // Size of the queue of outgoing messages for each connection, 256 in default.
+	MaxMessageSize int64         // Max size of incoming messages, no limit in default.
+
+	// DropOnFull drops the outgoing messages if the queue of the connection is full,
+	// or else the slow connection is closed.
+	DropOnFull bool
+
+	// OnConnect is called after the connection is upgraded and registered to the hub,
+	// which can set the user id or join rooms. The connection is closed if it returns error.
+	OnConnect func(conn *WebSocketConn) error
+
+	// OnDisconnect is called after the connection is closed and unregistered from the hub.
+	OnDisconnect func(conn *WebSocketConn)
+
+	// OnMessage handles the message whose type has no handler bound by On.
+	// An error message is replied to the client if it is not set.
+	OnMessage func(conn *WebSocketConn, msg *WebSocketMessage)
+
+	// OnError is called if the connection is broken unexpectedly.
+	OnError func(conn *WebSocketConn, err error)
+}
+
+// WebSocketHub manages the websocket connections, rooms, and routes the JSON messages
+// to handlers by message type.
+type WebSocketHub struct {
+	id        string                               // Unique id of the hub, which is the origin of the backplane messages.
+	options   WebSocketHubOptions                  // Hub options.
+	mu        sync.RWMutex                         // Mutex for connection indexes.
+	conns     map[string]*WebSocketConn            // Connection id to connection.
+	users     map[string]map[string]*WebSocketConn // User id to connections.
+	rooms     map[string]map[string]*WebSocketConn // Room to connections.
+	handlers  map[string]*webSocketHandler         // Message type to handler.
+	backplane WebSocketBackplane                   // Backplane for multi-instance fan-out.
+	cancel    context.CancelFunc                   // Cancels backplane subscription.
+	closed    *gtype.Bool
+}
+
+// WebSocketMessage is the JSON message exchanged by WebSocketHub, like:
+// {"type":"chat.send","id":"1","data":{"text":"hello"}}.
+//
+// The reply of the message has the same type and id, carrying the result data or the error.
+type WebSocketMessage struct {
+	Type  string                 `json:"type"`            // Message type for routing.
+	Id    string                 `json:"id,omitempty"`    // Optional message id, which is replied with the result.
+	Data  json.RawMessage        `json:"data,omitempty"`  // Message data.
+	Error *WebSocketMessageError `json:"error,omitempty"` // Error of the reply.
+}
+
+// WebSocketMessageError is the error of the replied message.
+type WebSocketMessageError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// webSocketHandler is the handler for messages of certain type.
+type webSocketHandler struct {
+	value   reflect.Value
+	reqType reflect.Type
+	hasRes  bool
+}
+
+const (
+	// WebSocketMessageTypeError is the type of message replied when the incoming message is malformed.
+	WebSocketMessageTypeError = "error"
+
+	defaultWebSocketPingInterval   = 30 * time.Second
+	defaultWebSocketPongTimeout    = 60 * time.Second
+	defaultWebSocketWriteTimeout   = 10 * time.Second
+	defaultWebSocketSendBufferSize = 256
+)
+
+var (
+	reflectTypeContext = reflect.TypeOf((*context.Context)(nil)).Elem()
+	reflectTypeError   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// NewWebSocketHub creates and returns a websocket hub.
+// Bind Handle of the hub to route to serve websocket connections.
+func NewWebSocketHub(options ...WebSocketHubOptions) *WebSocketHub {
+	h := &WebSocketHub{
+		id:       guid.S(),
+		conns:    make(map[string]*WebSocketConn),
+		users:    make(map[string]map[string]*WebSocketConn),
+		rooms:    make(map[string]map[string]*WebSocketConn),
+		handlers: make(map[string]*webSocketHandler),
+		closed:   gtype.NewBool(),
+	}
+	if len(options) > 0 {
+		h.options = options[0]
+	}
+	if h.options.PingInterval == 0 {
+		h.options.PingInterval = defaultWebSocketPingInterval
+	}
+	if h.options.PongTimeout <= 0 {
+		h.options.PongTimeout = defaultWebSocketPongTimeout
+	}
+	if h.options.WriteTimeout <= 0 {
+		h.options.WriteTimeout = defaultWebSocketWriteTimeout
+	}
+	if h.options.SendBufferSize <= 0 {
+		h.options.SendBufferSize = defaultWebSocketSendBufferSize
+	}
+	return h
+}
+
+// On binds `handler` for messages of `msgType`. The `handler` is like strict route handler:
+//
+//	func(ctx context.Context, req *XxxReq) (res *XxxRes, err error)
+//	func(ctx context.Context, req *XxxReq) (err error)
+//
+// The data of the message is converted and validated to the request struct, the connection
+// can be retrieved by WebSocketConnFromCtx. The result or error is replied to the client with
+// the same message type and id. If the result is nil and the message has no id, nothing is replied.
+//
+// It panics if the `handler` is invalid.
+func (h *WebSocketHub) On(msgType string, handler interface{}) {
+	var (
+		value       = reflect.ValueOf(handler)
+		reflectType = value.Type()
+	)
+	if reflectType.Kind() != reflect.Func ||
+		reflectType.NumIn() != 2 ||
+		reflectType.In(0) != reflectTypeContext ||
+		reflectType.In(1).Kind() != reflect.Ptr ||
+		reflectType.In(1).Elem().Kind() != reflect.Struct ||
+		reflectType.NumOut() < 1 || reflectType.NumOut() > 2 ||
+		reflectType.Out(reflectType.NumOut()-1) != reflectTypeError {
+		panic(gerror.NewCodef(
+			gcode.CodeInvalidParameter,
+			`invalid websocket handler "%s" for message type "%s", handler should be defined as `+
+				`"func(context.Context, *XxxReq) (*XxxRes, error)" or "func(context.Context, *XxxReq) error"`,
+			reflectType.String(), msgType,
+		))
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.handlers[msgType] = &webSocketHandler{
+		value:   value,
+		reqType: reflectType.In(1).Elem(),
+		hasRes:  reflectType.NumOut() == 2,
+	}
+}
+
+// Handle is the HandlerFunc upgrading the request to websocket connection and serving it
+// until the connection is closed.
+func (h *WebSocketHub) Handle(r *Request) {
+	if h.closed.Val() {
+		r.Response.WriteStatus(http.StatusServiceUnavailable)
+		return
+	}
+	ws, err := wsUpGrader.Upgrade(r.Response.Writer, r.Request, nil)
+	if err != nil {
+		// The upgrader has written the error response.
+		r.SetError(err)
+		return
+	}
+	ctx, cancel := context.WithCancel(r.Context())
+	conn := &WebSocketConn{
+		hub:     h,
+		conn:    ws,
+		id:      guid.S(),
+		userId:  gtype.NewString(),
+		request: r,
+		ctx:     ctx,
+		cancel:  cancel,
+		rooms:   gset.NewStrSet(true),
+		send:    make(chan []byte, h.options.SendBufferSize),
+		done:    make(chan struct{}),
+	}
+	go conn.writeLoop()
+	defer conn.Close()
+
+	h.register(conn)
+	if h.options.OnConnect != nil {
+		if err = h.options.OnConnect(conn); err != nil {
+			r.SetError(err)
+			return
+		}
+	}
+	conn.readLoop()
+}
+
+// SetBackplane sets the backplane and subscribes it, so that the messages broadcast by the hubs
+// of multiple instances are delivered to the connections of all instances.
+func (h *WebSocketHub) SetBackplane(ctx context.Context, backplane WebSocketBackplane) error {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	if err := backplane.Subscribe(ctx, h.handleBackplane); err != nil {
+		cancel()
+		return err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.cancel != nil {
+		h.cancel()
+	}
+	h.backplane = backplane
+	h.cancel = cancel
+	return nil
+}
+
+// Close closes all connections and stops the backplane subscription.
+func (h *WebSocketHub) Close() {
+	if !h.closed.Cas(false, true) {
+		return
+	}
+	h.mu.Lock()
+	if h.cancel != nil {
+		h.cancel()
+	}
+	conns := make([]*WebSocketConn, 0, len(h.conns))
+	for _, conn := range h.conns {
+		conns = append(conns, conn)
+	}
+	h.mu.Unlock()
+	for _, conn := range conns {
+		conn.Close()
+	}
+}
+
+// Count returns the count of local connections.
+func (h *WebSocketHub) Count() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.conns)
+}
+
+// Conn retrieves and returns the local connection by `connId`, or nil if it does not exist.
+func (h *WebSocketHub) Conn(connId string) *WebSocketConn {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.conns[connId]
+}
+
+// UserConns returns the local connections of user `userId`.
+func (h *WebSocketHub) UserConns(userId string) []*WebSocketConn {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return webSocketConnSlice(h.users[userId])
+}
+
+// RoomConns returns the local connections in `room`.
+func (h *WebSocketHub) RoomConns(room string) []*WebSocketConn {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return webSocketConnSlice(h.rooms[room])
+}
+
+// Broadcast sends message of `msgType` with `data` to all connections.
+func (h *WebSocketHub) Broadcast(ctx context.Context, msgType string, data interface{}) error {
+	return h.deliver(ctx, &webSocketBackplaneMessage{Kind: webSocketTargetAll}, msgType, data)
+}
+
+// BroadcastRoom sends message of `msgType` with `data` to connections in `room`,
+// except the connections of `excludeConnIds`.
+func (h *WebSocketHub) BroadcastRoom(
+	ctx context.Context, room, msgType string, data interface{}, excludeConnIds ...string,
+) error {
+	return h.deliver(ctx, &webSocketBackplaneMessage{
+		Kind:    webSocketTargetRoom,
+		Target:  room,
+		Exclude: excludeConnIds,
+	}, msgType, data)
+}
+
+// SendToUser sends message of `msgType` with `data` to all connections of user `userId`.
+func (h *WebSocketHub) SendToUser(ctx context.Context, userId, msgType string, data interface{}) error {
+	return h.deliver(ctx, &webSocketBackplaneMessage{
+		Kind:   webSocketTargetUser,
+		Target: userId,
+	}, msgType, data)
+}
+
+// SendToConn sends message of `msgType` with `data` to connection `connId`,
+// which might be connected to hubs of other instances if backplane is set.
+func (h *WebSocketHub) SendToConn(ctx context.Context, connId, msgType string, data interface{}) error {
+	return h.deliver(ctx, &webSocketBackplaneMessage{
+		Kind:   webSocketTargetConn,
+		Target: connId,
+	}, msgType, data)
+}
+
+// deliver delivers the message to the local targets and publishes it to the backplane.
+func (h *WebSocketHub) deliver(
+	ctx context.Context, message *webSocketBackplaneMessage, msgType string, data interface{},
+) error {
+	content, err := encodeWebSocketMessage(msgType, "", data, nil)
+	if err != nil {
+		return err
+	}
+	message.Origin = h.id
+	message.Payload = content
+	delivered := h.deliverLocal(message)
+	h.mu.RLock()
+	backplane := h.backplane
+	h.mu.RUnlock()
+	if backplane == nil || (message.Kind == webSocketTargetConn && delivered > 0) {
+		return nil
+	}
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return gerror.WrapCode(gcode.CodeInternalError, err)
+	}
+	return backplane.Publish(ctx, payload)
+}
+
+// deliverLocal delivers the message to the local targets, returns the count of delivered connections.
+func (h *WebSocketHub) deliverLocal(message *webSocketBackplaneMessage) int {
+	var conns []*WebSocketConn
+	h.mu.RLock()
+	switch message.Kind {
+	case webSocketTargetAll:
+		conns = webSocketConnSlice(h.conns)
+	case webSocketTargetRoom:
+		conns = webSocketConnSlice(h.rooms[message.Target])
+	case webSocketTargetUser:
+		conns = webSocketConnSlice(h.users[message.Target])
+	case webSocketTargetConn:
+		if conn, ok := h.conns[message.Target]; ok {
+			conns = []*WebSocketConn{conn}
+		}
+	}
+	h.mu.RUnlock()
+	var (
+		delivered = 0
+		excludes  = gset.NewStrSetFrom(message.Exclude)
+	)
+	for _, conn := range conns {
+		if excludes.Contains(conn.id) {
+			continue
+		}
+		if err := conn.SendRaw(message.Payload); err != nil {
+			intlog.Errorf(conn.ctx, `%+v`, err)
+			continue
+		}
+		delivered++
+	}
+	return delivered
+}
+
+// handleBackplane delivers the message from the backplane to the local targets.
+func (h *WebSocketHub) handleBackplane(ctx context.Context, payload []byte) {
+	var message *webSocketBackplaneMessage
+	if err := json.Unmarshal(payload, &message); err != nil {
+		intlog.Errorf(ctx, `invalid websocket backplane message: %+v`, err)
+		return
+	}
+	if message == nil || message.Origin == h.id {
+		return
+	}
+	h.deliverLocal(message)
+}
+
+// dispatch decodes and routes the message to its handler.
+func (h *WebSocketHub) dispatch(conn *WebSocketConn, content []byte) {
+	var msg *WebSocketMessage
+	if err := json.Unmarshal(content, &msg); err != nil || msg == nil || msg.Type == "" {
+		h.reply(conn, &WebSocketMessage{Type: WebSocketMessageTypeError}, nil, gerror.NewCode(
+			gcode.CodeInvalidRequest, `invalid message, message should be like {"type":"xxx","data":{}}`,
+		))
+		return
+	}
+	h.mu.RLock()
+	handler := h.handlers[msg.Type]
+	h.mu.RUnlock()
+	if handler == nil {
+		if h.options.OnMessage != nil {
+			h.options.OnMessage(conn, msg)
+			return
+		}
+		h.reply(conn, msg, nil, gerror.NewCodef(gcode.CodeNotFound, `no handler for message type "%s"`, msg.Type))
+		return
+	}
+	var (
+		ctx = context.WithValue(conn.ctx, ctxKeyForWebSocketConn{}, conn)
+		res interface{}
+		err error
+	)
+	gutil.TryCatch(ctx, func(ctx context.Context) {
+		res, err = handler.call(ctx, msg)
+	}, func(ctx context.Context, exception error) {
+		err = gerror.WrapCode(gcode.CodeInternalPanic, exception)
+	})
+	if res == nil && err == nil && msg.Id == "" {
+		return
+	}
+	h.reply(conn, msg, res, err)
+}
+
+// reply replies the result or error of `msg` to the connection.
+func (h *WebSocketHub) reply(conn *WebSocketConn, msg *WebSocketMessage, res interface{}, err error) {
+	var msgError *WebSocketMessageError
+	if err != nil {
+		code := gerror.Code(err)
+		if code == gcode.CodeNil {
+			code = gcode.CodeInternalError
+		}
+		msgError = &WebSocketMessageError{Code: code.Code(), Message: err.Error()}
+	}
+	content, err := encodeWebSocketMessage(msg.Type, msg.Id, res, msgError)
+	if err == nil {
+		err = conn.SendRaw(content)
+	}
+	if err != nil {
+		intlog.Errorf(conn.ctx, `%+v`, err)
+	}
+}
+
+// register adds the connection to the hub.
+func (h *WebSocketHub) register(conn *WebSocketConn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.conns[conn.id] = conn
+}
+
+// unregister removes the connection from the hub, which is called once when connection closes.
+func (h *WebSocketHub) unregister(conn *WebSocketConn) {
+	h.mu.Lock()
+	delete(h.conns, conn.id)
+	deleteWebSocketConn(h.users, conn.userId.Val(), conn)
+	for _, room := range conn.rooms.Slice() {
+		deleteWebSocketConn(h.rooms, room, conn)
+	}
+	h.mu.Unlock()
+	if h.options.OnDisconnect != nil {
+		h.options.OnDisconnect(conn)
+	}
+}
+
+// setUserId updates the user id of the connection.
+func (h *WebSocketHub) setUserId(conn *WebSocketConn, userId string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if conn.IsClosed() {
+		conn.userId.Set(userId)
+		return
+	}
+	deleteWebSocketConn(h.users, conn.userId.Val(), conn)
+	conn.userId.Set(userId)
+	if userId != "" {
+		addWebSocketConn(h.users, userId, conn)
+	}
+}
+
+// join makes the connection join rooms.
+func (h *WebSocketHub) join(conn *WebSocketConn, rooms ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if conn.IsClosed() {
+		return
+	}
+	for _, room := range rooms {
+		conn.rooms.Add(room)
+		addWebSocketConn(h.rooms, room, conn)
+	}
+}
+
+// leave makes the connection leave rooms.
+func (h *WebSocketHub) leave(conn *WebSocketConn, rooms ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, room := range rooms {
+		conn.rooms.Remove(room)
+		deleteWebSocketConn(h.rooms, room, conn)
+	}
+}
+
+// call converts the message data to request and calls the handler.
+func (h *webSocketHandler) call(ctx context.Context, msg *WebSocketMessage) (res interface{}, err error) {
+	var (
+		req  = reflect.New(h.reqType)
+		data map[string]interface{}
+	)
+	if len(msg.Data) > 0 {
+		j, err := gjson.LoadContent(msg.Data)
+		if err != nil {
+			return nil, gerror.WrapCode(gcode.CodeInvalidRequest, err, `invalid message data`)
+		}
+		data = j.Map()
+	}
+	if err = gconv.Struct(data, req.Interface()); err != nil {
+		return nil, gerror.WrapCode(gcode.CodeInvalidRequest, err, `invalid message data`)
+	}
+	if err = gvalid.New().Bail().Data(req.Interface()).Assoc(data).Run(ctx); err != nil {
+		return nil, err
+	}
+	results := h.value.Call([]reflect.Value{reflect.ValueOf(ctx), req})
+	if v := results[len(results)-1].Interface(); v != nil {
+		err = v.(error)
+	}
+	if h.hasRes && !results[0].IsNil() {
+		res = results[0].Interface()
+	}
+	return
+}
+
+// encodeWebSocketMessage encodes the message to JSON.
+func encodeWebSocketMessage(
+	msgType, msgId string, data interface{}, msgError *WebSocketMessageError,
+) ([]byte, error) {
+	msg := &WebSocketMessage{
+		Type:  msgType,
+		Id:    msgId,
+		Error: msgError,
+	}
+	if data != nil {
+		content, err := json.Marshal(data)
+		if err != nil {
+			return nil, gerror.WrapCode(gcode.CodeInternalError, err)
+		}
+		msg.Data = content
+	}
+	content, err := json.Marshal(msg)
+	if err != nil {
+		return nil, gerror.WrapCode(gcode.CodeInternalError, err)
+	}
+	return content, nil
+}
+
+func addWebSocketConn(index map[string]map[string]*WebSocketConn, key string, conn *WebSocketConn) {
+	conns, ok := index[key]
+	if !ok {
+		conns = make(map[string]*WebSocketConn)
+		index[key] = conns
+	}
+	conns[conn.id] = conn
+}
+
+func deleteWebSocketConn(index map[string]map[string]*WebSocketConn, key string, conn *WebSocketConn) {
+	if conns, ok := index[key]; ok {
+		delete(conns, conn.id)
+		if len(conns) == 0 {
+			delete(index, key)
+		}
+	}
+}
+
+func webSocketConnSlice(conns map[string]*WebSocketConn) []*WebSocketConn {
+	array := make([]*WebSocketConn, 0, len(conns))
+	for _, conn := range conns {
+		array = append(array, conn)
+	}
+	return array
+}
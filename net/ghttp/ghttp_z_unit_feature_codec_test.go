@@ -0,0 +1,189 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package ghttp_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/gogf/gf/v2/text/gstr"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/gogf/gf/v2/util/guid"
+)
+
+// testCodecKV is a simple Codec encoding map as `k=v` lines for testing.
+type testCodecKV struct{}
+
+func (testCodecKV) Encode(v interface{}) ([]byte, error) {
+	var (
+		r     = v.(ghttp.DefaultHandlerResponse)
+		lines = []string{
+			fmt.Sprintf("code=%d", r.Code),
+			fmt.Sprintf("message=%s", r.Message),
+			fmt.Sprintf("data=%s", gjson.MustEncodeString(r.Data)),
+		}
+	)
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+func (testCodecKV) Decode(data []byte, v interface{}) error {
+	m := make(map[string]interface{})
+	for _, line := range gstr.SplitAndTrim(string(data), "\n") {
+		array := strings.SplitN(line, "=", 2)
+		if len(array) == 2 {
+			m[array[0]] = array[1]
+		}
+	}
+	return gconv.Scan(m, v)
+}
+
+// testCodecFail is a Codec always failing encoding for testing.
+type testCodecFail struct{}
+
+func (testCodecFail) Encode(v interface{}) ([]byte, error) {
+	return nil, errors.New("encode failed")
+}
+
+func (testCodecFail) Decode(data []byte, v interface{}) error {
+	return errors.New("decode failed")
+}
+
+func Test_Codec_Negotiation(t *testing.T) {
+	type CodecReq struct {
+		g.Meta `path:"/user" method:"post"`
+		Name   string `json:"name"`
+		Age    int    `json:"age"`
+	}
+	type CodecRes struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+	ghttp.RegisterCodec("application/x-test-kv", testCodecKV{})
+	ghttp.RegisterCodec("application/x-test-fail", testCodecFail{})
+
+	s := g.Server(guid.S())
+	s.Use(ghttp.MiddlewareHandlerResponse)
+	s.BindHandler("/user", func(ctx context.Context, req *CodecReq) (res *CodecRes, err error) {
+		return &CodecRes{Name: req.Name, Age: req.Age}, nil
+	})
+	s.SetOpenApiPath("/api.json")
+	s.SetOpenApiCodecMediaTypes(true)
+	s.SetDumpRouterMap(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	gtest.C(t, func(t *gtest.T) {
+		client := g.Client()
+		client.SetPrefix(fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort()))
+		var expectJson = `{"code":0,"message":"OK","data":{"name":"john","age":18}}`
+
+		// Default JSON.
+		t.Assert(client.PostContent(ctx, "/user", `{"name":"john","age":18}`), expectJson)
+		t.Assert(
+			client.Header(g.MapStrStr{"Accept": "*/*"}).PostContent(ctx, "/user", `{"name":"john","age":18}`),
+			expectJson,
+		)
+		// Browsers prefer html, and accept xml with lower quality.
+		t.Assert(
+			client.Header(g.MapStrStr{
+				"Accept": "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+			}).PostContent(ctx, "/user", `{"name":"john","age":18}`),
+			expectJson,
+		)
+
+		// XML.
+		resp, err := client.Header(g.MapStrStr{"Accept": "application/xml"}).Post(ctx, "/user", `{"name":"john","age":18}`)
+		t.AssertNil(err)
+		t.Assert(resp.Header.Get("Content-Type"), "application/xml")
+		content := resp.ReadAllString()
+		t.Assert(gstr.Contains(content, `<name>john</name>`), true)
+		t.Assert(gstr.Contains(content, `<message>OK</message>`), true)
+		resp.Close()
+
+		// YAML with quality values.
+		resp, err = client.Header(g.MapStrStr{
+			"Accept": "application/json;q=0.5, application/yaml",
+		}).Post(ctx, "/user", `{"name":"john","age":18}`)
+		t.AssertNil(err)
+		t.Assert(resp.Header.Get("Content-Type"), "application/yaml")
+		content = resp.ReadAllString()
+		t.Assert(gstr.Contains(content, `name: john`), true)
+		t.Assert(gstr.Contains(content, `message: OK`), true)
+		resp.Close()
+
+		// Custom codec for both request and response.
+		resp, err = client.
+			Header(g.MapStrStr{"Accept": "application/x-test-kv"}).
+			ContentType("application/x-test-kv").
+			Post(ctx, "/user", "name=john\nage=18")
+		t.AssertNil(err)
+		t.Assert(resp.Header.Get("Content-Type"), "application/x-test-kv")
+		t.Assert(resp.ReadAllString(), "code=0\nmessage=OK\n"+`data={"name":"john","age":18}`)
+		resp.Close()
+
+		// It falls back to JSON if encoding fails.
+		t.Assert(
+			client.Header(g.MapStrStr{"Accept": "application/x-test-fail"}).PostContent(ctx, "/user", `{"name":"john","age":18}`),
+			expectJson,
+		)
+
+		// YAML request body.
+		t.Assert(
+			client.ContentType("application/yaml").PostContent(ctx, "/user", "name: john\nage: 18"),
+			expectJson,
+		)
+	})
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			openapi    = s.GetOpenApi()
+			operation  = openapi.Paths["/user"].Post
+			mediaTypes = ghttp.SupportedMediaTypes()
+		)
+		t.Assert(mediaTypes[0], "application/json")
+		t.AssertIN("application/x-test-kv", mediaTypes)
+		t.AssertIN("application/yaml", mediaTypes)
+		t.AssertNE(operation.RequestBody.Value.Content["application/xml"], nil)
+		t.AssertNE(operation.Responses["200"].Value.Content["application/x-test-kv"], nil)
+	})
+}
+
+func Test_Codec_OpenApi_Default(t *testing.T) {
+	type DefaultReq struct {
+		g.Meta `path:"/default" method:"post"`
+		Name   string `json:"name"`
+	}
+	type DefaultRes struct {
+		Name string `json:"name"`
+	}
+	s := g.Server(guid.S())
+	s.Use(ghttp.MiddlewareHandlerResponse)
+	s.BindHandler("/default", func(ctx context.Context, req *DefaultReq) (res *DefaultRes, err error) {
+		return &DefaultRes{Name: req.Name}, nil
+	})
+	s.SetOpenApiPath("/api.json")
+	s.SetDumpRouterMap(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	// The OpenApi specification only lists JSON in default.
+	gtest.C(t, func(t *gtest.T) {
+		operation := s.GetOpenApi().Paths["/default"].Post
+		t.Assert(len(operation.RequestBody.Value.Content), 1)
+		t.AssertNE(operation.RequestBody.Value.Content["application/json"], nil)
+		t.Assert(len(operation.Responses["200"].Value.Content), 1)
+	})
+}
@@ -0,0 +1,174 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package ghttp_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/gogf/gf/v2/util/guid"
+)
+
+func Test_Health_Checkers(t *testing.T) {
+	var healthy = true
+	s := g.Server(guid.S())
+	s.EnableHealth("/probe")
+	s.AddHealthChecker("db", ghttp.HealthCheckFunc(func(ctx context.Context) error {
+		if healthy {
+			return nil
+		}
+		return errors.New("connection refused")
+	}))
+	s.AddHealthChecker("app", ghttp.HealthCheckFunc(func(ctx context.Context) error {
+		return nil
+	}), ghttp.HealthCheckOption{Liveness: true})
+	s.AddHealthChecker("slow", ghttp.HealthCheckFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}), ghttp.HealthCheckOption{Timeout: 100 * time.Millisecond})
+	s.SetDumpRouterMap(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	gtest.C(t, func(t *gtest.T) {
+		client := g.Client()
+		client.SetPrefix(fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort()))
+
+		// The slow checker times out.
+		resp, err := client.Get(ctx, "/probe/readyz")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 503)
+		j, err := gjson.LoadContent(resp.ReadAll())
+		t.AssertNil(err)
+		t.Assert(j.Get("status"), "down")
+		t.Assert(j.Get("checks.db.status"), "up")
+		t.Assert(j.Get("checks.slow.status"), "down")
+		resp.Close()
+
+		s.RemoveHealthChecker("slow")
+		resp, err = client.Get(ctx, "/probe/healthz")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 200)
+		j, err = gjson.LoadContent(resp.ReadAll())
+		t.AssertNil(err)
+		t.Assert(j.Get("status"), "up")
+		t.Assert(j.Get("checks.slow"), nil)
+		resp.Close()
+
+		// Failing dependency does not affect liveness.
+		healthy = false
+		resp, err = client.Get(ctx, "/probe/readyz")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 503)
+		j, err = gjson.LoadContent(resp.ReadAll())
+		t.AssertNil(err)
+		t.Assert(j.Get("checks.db.error"), "connection refused")
+		resp.Close()
+
+		resp, err = client.Get(ctx, "/probe/livez")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 200)
+		j, err = gjson.LoadContent(resp.ReadAll())
+		t.AssertNil(err)
+		t.Assert(j.Get("checks.app.status"), "up")
+		t.Assert(j.Get("checks.db"), nil)
+		resp.Close()
+	})
+}
+
+func Test_Health_Shutdown(t *testing.T) {
+	s := g.Server(guid.S())
+	s.EnableHealth()
+	s.SetConfigWithMap(g.Map{
+		"healthShutdownDelay": "500ms",
+	})
+	s.SetDumpRouterMap(false)
+	s.Start()
+	time.Sleep(100 * time.Millisecond)
+
+	gtest.C(t, func(t *gtest.T) {
+		client := g.Client()
+		client.SetPrefix(fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort()))
+		t.Assert(client.GetContent(ctx, "/readyz"), `{"status":"up"}`)
+		t.Assert(s.IsShuttingDown(), false)
+
+		go s.Shutdown()
+		time.Sleep(100 * time.Millisecond)
+		t.Assert(s.IsShuttingDown(), true)
+
+		// Readiness fails while the server is still serving.
+		resp, err := client.Get(ctx, "/readyz")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 503)
+		j, err := gjson.LoadContent(resp.ReadAll())
+		t.AssertNil(err)
+		t.Assert(j.Get("checks.shutdown.status"), "down")
+		resp.Close()
+
+		resp, err = client.Get(ctx, "/healthz")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 200)
+		resp.Close()
+	})
+}
+
+// healthTestDriver is a sql driver whose connection blocks pinging until the context is done.
+type healthTestDriver struct{}
+
+type healthTestConn struct {
+	driver.Conn
+}
+
+type healthTestDB struct {
+	db *sql.DB
+}
+
+func init() {
+	sql.Register("ghttp-health-test", healthTestDriver{})
+}
+
+func (healthTestDriver) Open(name string) (driver.Conn, error) {
+	return healthTestConn{}, nil
+}
+
+func (healthTestConn) Ping(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (healthTestConn) Close() error {
+	return nil
+}
+
+func (d healthTestDB) Master(schema ...string) (*sql.DB, error) {
+	return d.db, nil
+}
+
+func Test_Health_CheckerDB_Context(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		db, err := sql.Open("ghttp-health-test", "")
+		t.AssertNil(err)
+		defer db.Close()
+
+		checkCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		err = ghttp.HealthCheckerDB(healthTestDB{db: db}).CheckHealth(checkCtx)
+		t.Assert(errors.Is(err, context.DeadlineExceeded), true)
+		t.AssertLT(time.Since(start), time.Second)
+	})
+}
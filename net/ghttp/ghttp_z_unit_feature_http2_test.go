@@ -0,0 +1,113 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package ghttp_test
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"golang.org/x/net/http2"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/gogf/gf/v2/util/guid"
+)
+
+func Test_Server_H2C(t *testing.T) {
+	s := g.Server(guid.S())
+	s.BindHandler("/proto", func(r *ghttp.Request) {
+		r.Response.Write(r.Proto)
+	})
+	s.SetH2C(true)
+	s.SetDumpRouterMap(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	prefix := fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort())
+	// HTTP/2 with prior knowledge.
+	gtest.C(t, func(t *gtest.T) {
+		client := g.Client().Prefix(prefix).H2C()
+		t.Assert(client.GetContent(ctx, "/proto"), "HTTP/2.0")
+		t.Assert(client.GetContent(ctx, "/proto"), "HTTP/2.0")
+
+		client.SetH2C(false)
+		t.Assert(client.GetContent(ctx, "/proto"), "HTTP/1.1")
+	})
+	// HTTP/1.1 upgrade.
+	gtest.C(t, func(t *gtest.T) {
+		conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", s.GetListenedPort()))
+		t.AssertNil(err)
+		defer conn.Close()
+		_, err = conn.Write([]byte("GET /proto HTTP/1.1\r\n" +
+			"Host: 127.0.0.1\r\n" +
+			"Connection: Upgrade, HTTP2-Settings\r\n" +
+			"Upgrade: h2c\r\n" +
+			"HTTP2-Settings: \r\n\r\n",
+		))
+		t.AssertNil(err)
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		t.AssertNil(err)
+		t.Assert(line, "HTTP/1.1 101 Switching Protocols\r\n")
+	})
+}
+
+func Test_Server_HTTP2_Settings(t *testing.T) {
+	s := g.Server(guid.S())
+	s.BindHandler("/", func(r *ghttp.Request) {
+		r.Response.Write(r.Proto)
+	})
+	gtest.AssertNil(s.SetConfigWithMap(g.Map{
+		"h2c":                       true,
+		"http2MaxConcurrentStreams": 10,
+		"http2MaxReadFrameSize":     "32kb",
+	}))
+	s.SetDumpRouterMap(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	gtest.C(t, func(t *gtest.T) {
+		conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", s.GetListenedPort()))
+		t.AssertNil(err)
+		defer conn.Close()
+		_, err = conn.Write([]byte(http2.ClientPreface))
+		t.AssertNil(err)
+		framer := http2.NewFramer(conn, conn)
+		t.AssertNil(framer.WriteSettings())
+		frame, err := framer.ReadFrame()
+		t.AssertNil(err)
+		settings, ok := frame.(*http2.SettingsFrame)
+		t.Assert(ok, true)
+		maxStreams, _ := settings.Value(http2.SettingMaxConcurrentStreams)
+		t.Assert(maxStreams, 10)
+		maxFrameSize, _ := settings.Value(http2.SettingMaxFrameSize)
+		t.Assert(maxFrameSize, 32*1024)
+	})
+}
+
+func Test_Server_H2C_Disabled(t *testing.T) {
+	s := g.Server(guid.S())
+	s.BindHandler("/proto", func(r *ghttp.Request) {
+		r.Response.Write(r.Proto)
+	})
+	s.SetDumpRouterMap(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	gtest.C(t, func(t *gtest.T) {
+		client := g.Client().Prefix(fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort()))
+		t.Assert(client.GetContent(ctx, "/proto"), "HTTP/1.1")
+		_, err := client.H2C().Get(ctx, "/proto")
+		t.AssertNE(err, nil)
+	})
+}
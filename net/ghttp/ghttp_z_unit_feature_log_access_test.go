@@ -0,0 +1,191 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package ghttp_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/container/garray"
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/os/glog"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/gogf/gf/v2/text/gstr"
+	"github.com/gogf/gf/v2/util/guid"
+)
+
+func Test_AccessLog_Json(t *testing.T) {
+	var (
+		entries  = garray.New(true)
+		contents = garray.NewStrArray(true)
+	)
+	s := g.Server(guid.S())
+	s.BindHandler("/login", func(r *ghttp.Request) {
+		r.Response.Header().Set("X-Result", "ok")
+		r.Response.WriteJson(g.Map{"user": r.Get("user"), "token": "secret-token"})
+	})
+	config := ghttp.NewConfig()
+	config.AccessLogEnabled = true
+	config.AccessLogFormat = ghttp.AccessLogFormatJson
+	config.AccessLogRequestHeaders = []string{"X-Request-Id"}
+	config.AccessLogResponseHeaders = []string{"X-Result"}
+	config.AccessLogRequestBody = true
+	config.AccessLogResponseBody = true
+	config.AccessLogRedactFields = []string{"password", "token", "profile.secret"}
+	config.AccessLogHandler = func(ctx context.Context, in *glog.HandlerInput) {
+		entries.Append(in.Values[0])
+		contents.Append(in.ValuesContent())
+	}
+	err := s.SetConfig(config)
+	gtest.AssertNil(err)
+	s.SetDumpRouterMap(false)
+	s.SetLogStdout(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	gtest.C(t, func(t *gtest.T) {
+		client := g.Client()
+		client.SetPrefix(fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort()))
+		client.SetHeader("X-Request-Id", "req-1")
+		client.ContentJson().PostContent(
+			ctx, "/login",
+			`{"user":"john","password":"123456","profile":{"secret":"s","name":"n"}}`,
+		)
+		time.Sleep(100 * time.Millisecond)
+		t.Assert(entries.Len(), 1)
+
+		entry := entries.At(0).(*ghttp.AccessLogEntry)
+		t.Assert(entry.Status, 200)
+		t.Assert(entry.Method, "POST")
+		t.Assert(entry.RequestHeaders["X-Request-Id"], "req-1")
+		t.Assert(entry.ResponseHeaders["X-Result"], "ok")
+		t.Assert(gstr.Contains(entry.RequestBody, "123456"), false)
+		t.Assert(gstr.Contains(entry.RequestBody, `"secret":"***"`), true)
+		t.Assert(gstr.Contains(entry.RequestBody, `"name":"n"`), true)
+		t.Assert(gstr.Contains(entry.ResponseBody, "secret-token"), false)
+
+		j, err := gjson.DecodeToJson(contents.At(0))
+		t.AssertNil(err)
+		t.Assert(j.Get("status"), 200)
+		t.Assert(j.Get("uri"), "/login")
+		t.Assert(j.Get("requestHeaders.X-Request-Id"), "req-1")
+		t.Assert(j.Get("responseBody"), `{"token":"***","user":"john"}`)
+	})
+}
+
+func Test_AccessLog_Format(t *testing.T) {
+	var contents = garray.NewStrArray(true)
+	s := g.Server(guid.S())
+	s.BindHandler("/hello", func(r *ghttp.Request) {
+		r.Response.Write("hello")
+	})
+	s.SetAccessLogEnabled(true)
+	s.SetAccessLogHandler(func(ctx context.Context, in *glog.HandlerInput) {
+		contents.Append(in.ValuesContent())
+	})
+	s.SetDumpRouterMap(false)
+	s.SetLogStdout(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	prefix := fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort())
+	gtest.C(t, func(t *gtest.T) {
+		s.SetAccessLogFormat("{method} {uri} {status} {bytes}")
+		g.Client().Prefix(prefix).GetContent(ctx, "/hello?name=john")
+		time.Sleep(100 * time.Millisecond)
+		t.Assert(contents.Len(), 1)
+		t.Assert(contents.At(0), "GET /hello?name=john 200 5")
+	})
+}
+
+func Test_AccessLog_Combined_Sampling(t *testing.T) {
+	var contents = garray.NewStrArray(true)
+	s := g.Server(guid.S())
+	s.BindHandler("/hello", func(r *ghttp.Request) {
+		r.Response.Write("hello")
+	})
+	err := s.SetConfigWithMap(g.Map{
+		"accessLogEnabled":    true,
+		"accessLogFormat":     "combined",
+		"accessLogSampleRate": 0.000001,
+		"logStdout":           false,
+	})
+	gtest.AssertNil(err)
+	s.SetAccessLogHandler(func(ctx context.Context, in *glog.HandlerInput) {
+		contents.Append(in.ValuesContent())
+	})
+	s.SetDumpRouterMap(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	gtest.C(t, func(t *gtest.T) {
+		client := g.Client().Prefix(fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort()))
+		for i := 0; i < 10; i++ {
+			t.Assert(client.GetContent(ctx, "/hello"), "hello")
+		}
+		time.Sleep(100 * time.Millisecond)
+		t.Assert(contents.Len(), 0)
+	})
+}
+
+func Test_AccessLog_Redact_Truncated(t *testing.T) {
+	entries := garray.New(true)
+	s := g.Server(guid.S())
+	s.BindHandler("/login", func(r *ghttp.Request) {
+		r.GetBody()
+	})
+	config := ghttp.NewConfig()
+	config.AccessLogEnabled = true
+	config.AccessLogRequestBody = true
+	config.AccessLogBodyLimit = 80
+	config.AccessLogRedactFields = []string{"password", "token", "secret"}
+	config.AccessLogHandler = func(ctx context.Context, in *glog.HandlerInput) {
+		entries.Append(in.Values[0])
+	}
+	err := s.SetConfig(config)
+	gtest.AssertNil(err)
+	s.SetDumpRouterMap(false)
+	s.SetLogStdout(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	gtest.C(t, func(t *gtest.T) {
+		client := g.Client().ContentJson()
+		client.SetPrefix(fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort()))
+		// The values of any type are redacted in the truncated body.
+		client.PostContent(
+			ctx, "/login",
+			`{"password":123456,"token":{"value":"t1"},"secret":["s1","s2"],"name":"n","data":"`+
+				gstr.Repeat("x", 100)+`"}`,
+		)
+		time.Sleep(100 * time.Millisecond)
+		t.Assert(entries.Len(), 1)
+
+		body := entries.At(0).(*ghttp.AccessLogEntry).RequestBody
+		t.Assert(gstr.HasSuffix(body, "..."), true)
+		t.Assert(gstr.Contains(body, "123456"), false)
+		t.Assert(gstr.Contains(body, "t1"), false)
+		t.Assert(gstr.Contains(body, "s2"), false)
+		t.Assert(gstr.HasPrefix(body, `{"password":"***","token":"***","secret":"***","name":"n"`), true)
+
+		// The value truncated in the middle is redacted.
+		client.PostContent(ctx, "/login", `{"name":"`+gstr.Repeat("x", 60)+`","token":{"value":"secret-value"}}`)
+		time.Sleep(100 * time.Millisecond)
+		t.Assert(entries.Len(), 2)
+		body = entries.At(1).(*ghttp.AccessLogEntry).RequestBody
+		t.Assert(gstr.Contains(body, "secret-value"), false)
+		t.Assert(gstr.Contains(body, `"token":"***"`), true)
+	})
+}
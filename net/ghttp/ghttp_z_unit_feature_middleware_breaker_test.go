@@ -0,0 +1,195 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package ghttp_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/gogf/gf/v2/util/guid"
+)
+
+type bulkheadTestReq struct {
+	g.Meta `path:"/meta" method:"get" bulkhead:"maxConcurrent=1"`
+}
+
+type bulkheadTestRes struct{}
+
+type breakerTestReq struct {
+	g.Meta `path:"/meta" method:"get" circuitBreaker:"minRequests=1,slowThreshold=50ms,slowRate=0.3,openTimeout=1m"`
+	Sleep  time.Duration
+	Name   string `v:"required"`
+}
+
+type breakerTestRes struct{}
+
+func Test_Middleware_Bulkhead(t *testing.T) {
+	s := g.Server(guid.S())
+	gtest.AssertNil(s.SetConfigWithMap(g.Map{
+		"bulkhead": g.Map{"maxConcurrent": 2},
+	}))
+	s.Use(ghttp.MiddlewareHandlerResponse)
+	s.Group("/limit", func(group *ghttp.RouterGroup) {
+		group.Middleware(ghttp.MiddlewareBulkhead(ghttp.BulkheadOptions{
+			MaxConcurrent: 1,
+			MaxQueue:      1,
+			QueueTimeout:  time.Second,
+		}))
+		group.ALL("/slow", func(r *ghttp.Request) {
+			time.Sleep(200 * time.Millisecond)
+			r.Response.Write("slow")
+		})
+		group.ALL("/fast", func(r *ghttp.Request) {
+			r.Response.Write("fast")
+		})
+	})
+	s.Group("/config", func(group *ghttp.RouterGroup) {
+		group.Middleware(ghttp.MiddlewareBulkhead())
+		group.ALL("/slow", func(r *ghttp.Request) {
+			time.Sleep(200 * time.Millisecond)
+			r.Response.Write("slow")
+		})
+		group.Bind(func(ctx context.Context, req *bulkheadTestReq) (res *bulkheadTestRes, err error) {
+			time.Sleep(200 * time.Millisecond)
+			return
+		})
+	})
+	s.SetDumpRouterMap(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	client := g.Client().Prefix(fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort()))
+	// concurrentGet requests `paths` concurrently and returns the status codes in order.
+	concurrentGet := func(t *gtest.T, paths ...string) []int {
+		var (
+			wg       sync.WaitGroup
+			statuses = make([]int, len(paths))
+		)
+		for i, path := range paths {
+			wg.Add(1)
+			go func(i int, path string) {
+				defer wg.Done()
+				resp, err := client.Get(ctx, path)
+				t.AssertNil(err)
+				defer resp.Close()
+				statuses[i] = resp.StatusCode
+			}(i, path)
+			time.Sleep(50 * time.Millisecond)
+		}
+		wg.Wait()
+		return statuses
+	}
+	// The routes of group share the same bulkhead with queue.
+	gtest.C(t, func(t *gtest.T) {
+		statuses := concurrentGet(t, "/limit/slow", "/limit/fast", "/limit/slow")
+		t.Assert(statuses, []int{http.StatusOK, http.StatusOK, http.StatusServiceUnavailable})
+
+		t.Assert(client.GetContent(ctx, "/limit/fast"), "fast")
+	})
+	gtest.C(t, func(t *gtest.T) {
+		go client.GetContent(ctx, "/limit/slow")
+		go client.GetContent(ctx, "/limit/slow")
+		time.Sleep(50 * time.Millisecond)
+		resp, err := client.Get(ctx, "/limit/fast")
+		t.AssertNil(err)
+		defer resp.Close()
+		t.Assert(resp.StatusCode, http.StatusServiceUnavailable)
+		t.Assert(resp.ReadAllString(), fmt.Sprintf(
+			`{"code":%d,"message":"too many concurrent requests","data":null}`, gcode.CodeServerBusy.Code(),
+		))
+		time.Sleep(500 * time.Millisecond)
+	})
+	// Options from configuration and meta tag.
+	gtest.C(t, func(t *gtest.T) {
+		statuses := concurrentGet(t, "/config/slow", "/config/slow", "/config/slow")
+		t.Assert(statuses, []int{http.StatusOK, http.StatusOK, http.StatusServiceUnavailable})
+
+		statuses = concurrentGet(t, "/config/meta", "/config/slow", "/config/meta")
+		t.Assert(statuses, []int{http.StatusOK, http.StatusOK, http.StatusServiceUnavailable})
+	})
+}
+
+func Test_Middleware_CircuitBreaker(t *testing.T) {
+	s := g.Server(guid.S())
+	s.Use(ghttp.MiddlewareHandlerResponse)
+	s.Group("/api", func(group *ghttp.RouterGroup) {
+		group.Middleware(ghttp.MiddlewareCircuitBreaker(ghttp.CircuitBreakerOptions{
+			MinRequests: 2,
+			OpenTimeout: 200 * time.Millisecond,
+		}))
+		group.ALL("/call", func(r *ghttp.Request) {
+			if r.Get("fail").Bool() {
+				r.SetError(gerror.New("dependency failed"))
+				return
+			}
+			r.Response.Write("ok")
+		})
+		group.ALL("/other", func(r *ghttp.Request) {
+			r.Response.Write("other")
+		})
+		group.Bind(func(ctx context.Context, req *breakerTestReq) (res *breakerTestRes, err error) {
+			time.Sleep(req.Sleep)
+			return
+		})
+	})
+	s.SetDumpRouterMap(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	client := g.Client().Prefix(fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort()))
+	getStatus := func(t *gtest.T, path string) int {
+		resp, err := client.Get(ctx, path)
+		t.AssertNil(err)
+		defer resp.Close()
+		return resp.StatusCode
+	}
+	// Trips on error rate.
+	gtest.C(t, func(t *gtest.T) {
+		t.Assert(client.GetContent(ctx, "/api/call"), "ok")
+		t.Assert(client.GetContent(ctx, "/api/call"), "ok")
+		t.Assert(client.GetContent(ctx, "/api/call?fail=1"), `{"code":50,"message":"dependency failed","data":null}`)
+		t.Assert(client.GetContent(ctx, "/api/call?fail=1"), `{"code":50,"message":"dependency failed","data":null}`)
+
+		resp, err := client.Get(ctx, "/api/call")
+		t.AssertNil(err)
+		defer resp.Close()
+		t.Assert(resp.StatusCode, http.StatusServiceUnavailable)
+		t.Assert(resp.ReadAllString(), fmt.Sprintf(
+			`{"code":%d,"message":"circuit breaker is open","data":null}`, gcode.CodeServerBusy.Code(),
+		))
+		// Each route has its own breaker.
+		t.Assert(client.GetContent(ctx, "/api/other"), "other")
+	})
+	// Half-open state.
+	gtest.C(t, func(t *gtest.T) {
+		time.Sleep(250 * time.Millisecond)
+		t.Assert(getStatus(t, "/api/call?fail=1"), http.StatusOK)
+		t.Assert(getStatus(t, "/api/call"), http.StatusServiceUnavailable)
+
+		time.Sleep(250 * time.Millisecond)
+		t.Assert(client.GetContent(ctx, "/api/call"), "ok")
+		t.Assert(client.GetContent(ctx, "/api/call"), "ok")
+	})
+	// Trips on latency from meta tag options, and client errors are not failures.
+	gtest.C(t, func(t *gtest.T) {
+		t.Assert(getStatus(t, "/api/meta?sleep=1ms"), http.StatusOK)
+		t.Assert(getStatus(t, "/api/meta?sleep=1ms&name=john"), http.StatusOK)
+		t.Assert(getStatus(t, "/api/meta?sleep=100ms&name=john"), http.StatusOK)
+		t.Assert(getStatus(t, "/api/meta?sleep=1ms&name=john"), http.StatusServiceUnavailable)
+	})
+}
@@ -0,0 +1,194 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package ghttp_test
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/encoding/gcompress"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/os/gfile"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/gogf/gf/v2/util/guid"
+)
+
+// compressTestCodec is the codec that wraps content with encoding name, for testing.
+type compressTestCodec struct{}
+
+type compressTestWriter struct {
+	io.Writer
+}
+
+func (compressTestCodec) Encoding() string {
+	return "x-test"
+}
+
+func (compressTestCodec) NewWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	_, err := w.Write([]byte(fmt.Sprintf("x-test:%d:", level)))
+	return compressTestWriter{w}, err
+}
+
+func (compressTestCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return io.NopCloser(r), nil
+}
+
+func (w compressTestWriter) Close() error {
+	return nil
+}
+
+func Test_Middleware_Compress(t *testing.T) {
+	gcompress.RegisterCodec(compressTestCodec{})
+	var content = strings.Repeat("Hello World! ", 1000)
+
+	s := g.Server(guid.S())
+	s.Group("/", func(group *ghttp.RouterGroup) {
+		group.Middleware(ghttp.MiddlewareCompress())
+		group.ALL("/text", func(r *ghttp.Request) {
+			r.Response.Write(content)
+		})
+		group.ALL("/small", func(r *ghttp.Request) {
+			r.Response.Write("small")
+		})
+		group.ALL("/image", func(r *ghttp.Request) {
+			r.Response.Header().Set("Content-Type", "image/png")
+			r.Response.Write(content)
+		})
+		group.ALL("/encoded", func(r *ghttp.Request) {
+			r.Response.Header().Set("Content-Encoding", "identity")
+			r.Response.Write(content)
+		})
+	})
+	s.Group("/custom", func(group *ghttp.RouterGroup) {
+		group.Middleware(ghttp.MiddlewareCompress(ghttp.CompressOptions{
+			Encodings:    []string{"x-test", gcompress.EncodingGzip},
+			Levels:       map[string]int{"x-test": 5},
+			MinSize:      1,
+			ContentTypes: []string{"application/json"},
+		}))
+		group.ALL("/json", func(r *ghttp.Request) {
+			r.Response.WriteJson(g.Map{"k": "v"})
+		})
+		group.ALL("/text", func(r *ghttp.Request) {
+			r.Response.Write(content)
+		})
+	})
+	s.SetDumpRouterMap(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	client := g.Client().Prefix(fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort()))
+	get := func(t *gtest.T, path, acceptEncoding string) (encoding, vary, body string) {
+		resp, err := client.Header(g.MapStrStr{"Accept-Encoding": acceptEncoding}).Get(ctx, path)
+		t.AssertNil(err)
+		defer resp.Close()
+		return resp.Header.Get("Content-Encoding"), resp.Header.Get("Vary"), resp.ReadAllString()
+	}
+	gtest.C(t, func(t *gtest.T) {
+		// Quality values negotiation, "br" and "zstd" are not registered.
+		encoding, vary, body := get(t, "/text", "br, zstd;q=0.9, deflate;q=0.8, gzip;q=0.5")
+		t.Assert(encoding, gcompress.EncodingGzip)
+		t.Assert(vary, "Accept-Encoding")
+		data, err := gcompress.Decompress(encoding, []byte(body))
+		t.AssertNil(err)
+		t.Assert(data, content)
+
+		encoding, _, body = get(t, "/text", "*")
+		t.Assert(encoding, gcompress.EncodingGzip)
+		data, err = gcompress.UnGzip([]byte(body))
+		t.AssertNil(err)
+		t.Assert(data, content)
+
+		encoding, _, body = get(t, "/text", "gzip;q=0, identity")
+		t.Assert(encoding, "")
+		t.Assert(body, content)
+	})
+	gtest.C(t, func(t *gtest.T) {
+		encoding, vary, body := get(t, "/small", "gzip")
+		t.Assert(encoding, "")
+		t.Assert(vary, "Accept-Encoding")
+		t.Assert(body, "small")
+
+		encoding, vary, _ = get(t, "/image", "gzip")
+		t.Assert(encoding, "")
+		t.Assert(vary, "")
+
+		encoding, _, body = get(t, "/encoded", "gzip")
+		t.Assert(encoding, "identity")
+		t.Assert(body, content)
+	})
+	gtest.C(t, func(t *gtest.T) {
+		encoding, _, body := get(t, "/custom/json", "gzip, x-test")
+		t.Assert(encoding, "x-test")
+		t.Assert(body, `x-test:5:{"k":"v"}`)
+
+		encoding, _, _ = get(t, "/custom/text", "gzip, x-test")
+		t.Assert(encoding, "")
+	})
+}
+
+func Test_Static_Precompressed(t *testing.T) {
+	var (
+		content = strings.Repeat("console.log('hello');\n", 100)
+		path    = gfile.Temp(guid.S())
+	)
+	defer gfile.Remove(path)
+	gtest.AssertNil(gfile.PutContents(gfile.Join(path, "app.js"), content))
+	gtest.AssertNil(gfile.PutContents(gfile.Join(path, "app.js.br"), "br-content"))
+	gtest.AssertNil(gfile.PutContents(gfile.Join(path, "style.css"), "body{}"))
+	gzipContent, err := gcompress.Gzip([]byte(content))
+	gtest.AssertNil(err)
+	gtest.AssertNil(gfile.PutBytes(gfile.Join(path, "app.js.gz"), gzipContent))
+
+	s := g.Server(guid.S())
+	s.SetServerRoot(path)
+	s.SetStaticPrecompressed(true)
+	s.SetDumpRouterMap(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	client := g.Client().Prefix(fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort()))
+	gtest.C(t, func(t *gtest.T) {
+		resp, err := client.Header(g.MapStrStr{"Accept-Encoding": "gzip, br"}).Get(ctx, "/app.js")
+		t.AssertNil(err)
+		defer resp.Close()
+		t.Assert(resp.Header.Get("Content-Encoding"), gcompress.EncodingBrotli)
+		t.Assert(resp.Header.Get("Vary"), "Accept-Encoding")
+		t.Assert(strings.Contains(resp.Header.Get("Content-Type"), "javascript"), true)
+		t.Assert(resp.ReadAllString(), "br-content")
+	})
+	gtest.C(t, func(t *gtest.T) {
+		resp, err := client.Header(g.MapStrStr{"Accept-Encoding": "gzip, br;q=0.5"}).Get(ctx, "/app.js")
+		t.AssertNil(err)
+		defer resp.Close()
+		t.Assert(resp.Header.Get("Content-Encoding"), gcompress.EncodingGzip)
+		data, err := gcompress.UnGzip(resp.ReadAll())
+		t.AssertNil(err)
+		t.Assert(data, content)
+	})
+	gtest.C(t, func(t *gtest.T) {
+		resp, err := client.Header(g.MapStrStr{"Accept-Encoding": "zstd"}).Get(ctx, "/app.js")
+		t.AssertNil(err)
+		defer resp.Close()
+		t.Assert(resp.Header.Get("Content-Encoding"), "")
+		t.Assert(resp.Header.Get("Vary"), "Accept-Encoding")
+		t.Assert(resp.ReadAllString(), content)
+
+		resp2, err := client.Header(g.MapStrStr{"Accept-Encoding": "gzip"}).Get(ctx, "/style.css")
+		t.AssertNil(err)
+		defer resp2.Close()
+		t.Assert(resp2.Header.Get("Content-Encoding"), "")
+		t.Assert(resp2.Header.Get("Vary"), "")
+		t.Assert(resp2.ReadAllString(), "body{}")
+	})
+}
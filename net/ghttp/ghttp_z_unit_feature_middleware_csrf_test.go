@@ -0,0 +1,178 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package ghttp_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/container/gtype"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/os/gsession"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/gogf/gf/v2/util/guid"
+)
+
+func Test_Middleware_CSRF_Session(t *testing.T) {
+	s := g.Server(guid.S())
+	s.Group("/", func(group *ghttp.RouterGroup) {
+		group.Middleware(ghttp.MiddlewareCSRF)
+		group.GET("/token", func(r *ghttp.Request) {
+			r.Response.Write(r.GetCSRFToken())
+		})
+		group.GET("/tpl", func(r *ghttp.Request) {
+			r.Response.WriteTplContent(`{{.csrf_token}}`)
+		})
+		group.POST("/submit", func(r *ghttp.Request) {
+			r.Response.Write("ok")
+		})
+	})
+	s.SetDumpRouterMap(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	gtest.C(t, func(t *gtest.T) {
+		client := g.Client()
+		client.SetBrowserMode(true)
+		client.SetPrefix(fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort()))
+
+		token := client.GetContent(ctx, "/token")
+		t.Assert(len(token), 32)
+		t.Assert(client.GetContent(ctx, "/token"), token)
+		t.Assert(client.GetContent(ctx, "/tpl"), token)
+
+		// Missing token.
+		resp, err := client.Post(ctx, "/submit")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 403)
+		resp.Close()
+
+		// Invalid token.
+		resp, err = client.Header(g.MapStrStr{"X-CSRF-Token": "invalid"}).Post(ctx, "/submit")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 403)
+		resp.Close()
+
+		// Token from header.
+		t.Assert(client.Header(g.MapStrStr{"X-CSRF-Token": token}).PostContent(ctx, "/submit"), "ok")
+
+		// Token from form field.
+		t.Assert(client.PostContent(ctx, "/submit", "_csrf="+token), "ok")
+	})
+	// Token is bound to session.
+	gtest.C(t, func(t *gtest.T) {
+		client1 := g.Client()
+		client1.SetBrowserMode(true)
+		client1.SetPrefix(fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort()))
+		client2 := g.Client()
+		client2.SetBrowserMode(true)
+		client2.SetPrefix(fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort()))
+
+		token1 := client1.GetContent(ctx, "/token")
+		token2 := client2.GetContent(ctx, "/token")
+		t.AssertNE(token1, token2)
+
+		resp, err := client2.Header(g.MapStrStr{"X-CSRF-Token": token1}).Post(ctx, "/submit")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 403)
+		resp.Close()
+	})
+}
+
+func Test_Middleware_CSRF_Cookie(t *testing.T) {
+	s := g.Server(guid.S())
+	s.Use(ghttp.MiddlewareCSRFWithOptions(ghttp.CSRFOptions{
+		Mode:        ghttp.CSRFModeCookie,
+		HeaderName:  "X-XSRF-Token",
+		ExemptPaths: []string{"/webhook/*"},
+	}))
+	s.BindHandler("GET:/token", func(r *ghttp.Request) {
+		r.Response.Write(r.GetCSRFToken())
+	})
+	s.BindHandler("POST:/submit", func(r *ghttp.Request) {
+		r.Response.Write("ok")
+	})
+	s.Group("/webhook", func(group *ghttp.RouterGroup) {
+		group.POST("/pay", func(r *ghttp.Request) {
+			r.Response.Write("webhook")
+		})
+	})
+	s.SetDumpRouterMap(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	gtest.C(t, func(t *gtest.T) {
+		client := g.Client()
+		client.SetBrowserMode(true)
+		client.SetPrefix(fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort()))
+
+		resp, err := client.Get(ctx, "/token")
+		t.AssertNil(err)
+		token := resp.ReadAllString()
+		t.Assert(resp.GetCookie("_csrf"), token)
+		resp.Close()
+
+		resp, err = client.Post(ctx, "/submit")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 403)
+		resp.Close()
+
+		t.Assert(client.Header(g.MapStrStr{"X-XSRF-Token": token}).PostContent(ctx, "/submit"), "ok")
+
+		// Exempted route group.
+		t.Assert(g.Client().PostContent(
+			ctx, fmt.Sprintf("http://127.0.0.1:%d/webhook/pay", s.GetListenedPort()),
+		), "webhook")
+	})
+}
+
+// csrfFailStorage is the session storage that fails to store values.
+type csrfFailStorage struct {
+	*gsession.StorageMemory
+}
+
+func (s *csrfFailStorage) Set(ctx context.Context, sessionId string, key string, value interface{}, ttl time.Duration) error {
+	return errors.New("storage unavailable")
+}
+
+func Test_Middleware_CSRF_SessionError(t *testing.T) {
+	var errCode = gtype.NewInt()
+	s := g.Server(guid.S())
+	s.SetSessionStorage(&csrfFailStorage{StorageMemory: gsession.NewStorageMemory()})
+	s.Group("/", func(group *ghttp.RouterGroup) {
+		group.Middleware(func(r *ghttp.Request) {
+			r.Middleware.Next()
+			errCode.Set(gerror.Code(r.GetError()).Code())
+		}, ghttp.MiddlewareCSRF)
+		group.GET("/token", func(r *ghttp.Request) {
+			r.Response.Write(r.GetCSRFToken())
+		})
+	})
+	s.SetDumpRouterMap(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	// The session error is responded as internal error instead of panic.
+	gtest.C(t, func(t *gtest.T) {
+		client := g.Client()
+		client.SetPrefix(fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort()))
+		resp, err := client.Get(ctx, "/token")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 500)
+		t.Assert(errCode.Val(), gcode.CodeInternalError.Code())
+		resp.Close()
+	})
+}
@@ -0,0 +1,137 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package ghttp_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/gclient"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/gogf/gf/v2/util/guid"
+)
+
+func Test_Middleware_HMACVerify(t *testing.T) {
+	var (
+		s       = g.Server(guid.S())
+		errText string
+	)
+	s.Group("/", func(group *ghttp.RouterGroup) {
+		group.Middleware(ghttp.MiddlewareHMACVerify(ghttp.HMACVerifyOptions{
+			Secrets:       map[string]string{"app": "secret"},
+			SignedHeaders: []string{"X-Tenant"},
+		}))
+		group.ALL("/echo", func(r *ghttp.Request) {
+			r.Response.Write(r.GetHMACKeyId(), ":", r.Get("id"), ":", r.GetBodyString())
+		})
+	})
+	s.Group("/custom", func(group *ghttp.RouterGroup) {
+		group.Middleware(ghttp.MiddlewareHMACVerify(ghttp.HMACVerifyOptions{
+			SecretFunc: func(r *ghttp.Request, keyId string) (string, error) {
+				return "secret-" + keyId, nil
+			},
+			AllowUnsignedPayload: true,
+			ErrorHandler: func(r *ghttp.Request, err error) {
+				errText = err.Error()
+				r.Response.WriteStatus(403)
+			},
+		}))
+		group.ALL("/echo", func(r *ghttp.Request) {
+			r.Response.Write(r.GetHMACKeyId(), ":", r.GetBodyString())
+		})
+	})
+	s.SetDumpRouterMap(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	var (
+		prefix  = fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort())
+		options = gclient.HMACSignOptions{
+			KeyId:         "app",
+			Secret:        "secret",
+			SignedHeaders: []string{"Content-Type", "X-Tenant"},
+		}
+	)
+	gtest.C(t, func(t *gtest.T) {
+		client := g.Client().Prefix(prefix).Use(gclient.MiddlewareHMACSign(options))
+		client.SetHeader("X-Tenant", "a")
+		t.Assert(client.GetContent(ctx, "/echo?id=1&name=john%20doe&tag=a+b"), "app:1:")
+		t.Assert(client.PostContent(ctx, "/echo", g.Map{"id": 2}), "app:2:id=2")
+
+		// Unsigned request.
+		resp, err := g.Client().Prefix(prefix).Post(ctx, "/echo", "data")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 401)
+		t.AssertNE(resp.Header.Get("Date"), "")
+		resp.Close()
+
+		// Invalid secret and missing signed header.
+		for _, o := range []gclient.HMACSignOptions{
+			{KeyId: "app", Secret: "invalid", SignedHeaders: options.SignedHeaders},
+			{KeyId: "app", Secret: "secret"},
+		} {
+			resp, err = g.Client().Prefix(prefix).Use(gclient.MiddlewareHMACSign(o)).Post(ctx, "/echo", "data")
+			t.AssertNil(err)
+			t.Assert(resp.StatusCode, 401)
+			resp.Close()
+		}
+
+		// Tampered body.
+		resp, err = g.Client().Prefix(prefix).Use(
+			gclient.MiddlewareHMACSign(options),
+			func(c *gclient.Client, r *http.Request) (*gclient.Response, error) {
+				r.Body = io.NopCloser(strings.NewReader("tampered"))
+				r.ContentLength = 8
+				return c.Next(r)
+			},
+		).Header(g.MapStrStr{"X-Tenant": "a"}).Post(ctx, "/echo", "data")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 401)
+		resp.Close()
+
+		// Expired timestamp.
+		resp, err = g.Client().Prefix(prefix).Use(
+			gclient.MiddlewareHMACSign(options),
+			func(c *gclient.Client, r *http.Request) (*gclient.Response, error) {
+				r.Header.Set("X-Signature-Timestamp", strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10))
+				return c.Next(r)
+			},
+		).Header(g.MapStrStr{"X-Tenant": "a"}).Get(ctx, "/echo")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 401)
+		resp.Close()
+	})
+	// Custom secret function, unsigned payload and error handler.
+	gtest.C(t, func(t *gtest.T) {
+		client := g.Client().Prefix(prefix).Use(gclient.MiddlewareHMACSign(gclient.HMACSignOptions{
+			KeyId:           "b",
+			Secret:          "secret-b",
+			Algorithm:       "HMAC-SHA512",
+			UnsignedPayload: true,
+		}))
+		t.Assert(client.PostContent(ctx, "/custom/echo", "data"), "b:data")
+
+		client = g.Client().Prefix(prefix).Use(gclient.MiddlewareHMACSign(gclient.HMACSignOptions{
+			KeyId:  "b",
+			Secret: "secret-c",
+		}))
+		resp, err := client.Post(ctx, "/custom/echo", "data")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 403)
+		resp.Close()
+		t.Assert(errText, gerror.Wrap(ghttp.ErrHMACSignatureInvalid, "signature mismatch").Error())
+	})
+}
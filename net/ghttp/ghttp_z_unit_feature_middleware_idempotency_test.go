@@ -0,0 +1,184 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package ghttp_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/container/gtype"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/gogf/gf/v2/util/guid"
+)
+
+func Test_Middleware_Idempotency(t *testing.T) {
+	var counter = gtype.NewInt()
+	s := g.Server(guid.S())
+	s.Group("/", func(group *ghttp.RouterGroup) {
+		group.Middleware(ghttp.MiddlewareIdempotency())
+		group.POST("/order", func(r *ghttp.Request) {
+			r.Response.Header().Set("X-Order", "created")
+			r.Response.WriteStatus(201, fmt.Sprintf("order-%d", counter.Add(1)))
+		})
+		group.POST("/slow", func(r *ghttp.Request) {
+			time.Sleep(500 * time.Millisecond)
+			r.Response.Write("slow")
+		})
+		group.POST("/fail", func(r *ghttp.Request) {
+			r.Response.WriteStatus(500, fmt.Sprintf("fail-%d", counter.Add(1)))
+		})
+	})
+	s.SetDumpRouterMap(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	prefix := fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort())
+	// Replay the response.
+	gtest.C(t, func(t *gtest.T) {
+		client := g.Client().Prefix(prefix).Header(g.MapStrStr{"Idempotency-Key": guid.S()})
+		resp, err := client.Post(ctx, "/order", "id=1")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 201)
+		t.Assert(resp.ReadAllString(), "order-1")
+		t.Assert(resp.Header.Get(ghttp.HeaderIdempotentReplayed), "")
+		resp.Close()
+
+		resp, err = client.Post(ctx, "/order", "id=1")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 201)
+		t.Assert(resp.ReadAllString(), "order-1")
+		t.Assert(resp.Header.Get("X-Order"), "created")
+		t.Assert(resp.Header.Get(ghttp.HeaderIdempotentReplayed), "true")
+		resp.Close()
+
+		// Different payload with the same key.
+		resp, err = client.Post(ctx, "/order", "id=2")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 422)
+		resp.Close()
+
+		// Without key.
+		t.Assert(g.Client().Prefix(prefix).PostContent(ctx, "/order", "id=1"), "order-2")
+	})
+	// Concurrent duplicates.
+	gtest.C(t, func(t *gtest.T) {
+		client := g.Client().Prefix(prefix).Header(g.MapStrStr{"Idempotency-Key": guid.S()})
+		go client.PostContent(ctx, "/slow")
+		time.Sleep(100 * time.Millisecond)
+		resp, err := client.Post(ctx, "/slow")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 409)
+		resp.Close()
+
+		time.Sleep(600 * time.Millisecond)
+		resp, err = client.Post(ctx, "/slow")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 200)
+		t.Assert(resp.ReadAllString(), "slow")
+		t.Assert(resp.Header.Get(ghttp.HeaderIdempotentReplayed), "true")
+		resp.Close()
+	})
+	// Server errors are not stored.
+	gtest.C(t, func(t *gtest.T) {
+		counter.Set(0)
+		client := g.Client().Prefix(prefix).Header(g.MapStrStr{"Idempotency-Key": guid.S()})
+		t.Assert(client.PostContent(ctx, "/fail"), "fail-1")
+		t.Assert(client.PostContent(ctx, "/fail"), "fail-2")
+	})
+}
+
+func Test_Middleware_Idempotency_Required(t *testing.T) {
+	s := g.Server(guid.S())
+	s.Group("/", func(group *ghttp.RouterGroup) {
+		group.Middleware(ghttp.MiddlewareIdempotency(ghttp.IdempotencyOptions{
+			HeaderName: "X-Request-Id",
+			Required:   true,
+		}))
+		group.ALL("/pay", func(r *ghttp.Request) {
+			r.Response.Write("paid")
+		})
+	})
+	s.SetDumpRouterMap(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	gtest.C(t, func(t *gtest.T) {
+		client := g.Client().Prefix(fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort()))
+		resp, err := client.Post(ctx, "/pay")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 400)
+		resp.Close()
+
+		t.Assert(client.GetContent(ctx, "/pay"), "paid")
+		t.Assert(client.Header(g.MapStrStr{"X-Request-Id": "1"}).PostContent(ctx, "/pay"), "paid")
+	})
+}
+
+type idempotencyOrderReq struct {
+	g.Meta `path:"/order" method:"post"`
+	Id     int
+}
+
+type idempotencyOrderRes struct {
+	Order string `json:"order"`
+}
+
+func Test_Middleware_Idempotency_HandlerResponse(t *testing.T) {
+	var counter = gtype.NewInt()
+	s := g.Server(guid.S())
+	s.Group("/", func(group *ghttp.RouterGroup) {
+		// The idempotency middleware is executed in the handler response middleware.
+		group.Middleware(ghttp.MiddlewareHandlerResponse, ghttp.MiddlewareIdempotency())
+		group.Bind(func(ctx context.Context, req *idempotencyOrderReq) (res *idempotencyOrderRes, err error) {
+			n := counter.Add(1)
+			if req.Id < 0 {
+				return nil, gerror.NewCodef(gcode.CodeInvalidParameter, "invalid-%d", n)
+			}
+			return &idempotencyOrderRes{Order: fmt.Sprintf("order-%d", n)}, nil
+		})
+	})
+	s.SetDumpRouterMap(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	prefix := fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort())
+	// The serialized handler response is replayed.
+	gtest.C(t, func(t *gtest.T) {
+		client := g.Client().Prefix(prefix).Header(g.MapStrStr{"Idempotency-Key": guid.S()})
+		expect := `{"code":0,"message":"OK","data":{"order":"order-1"}}`
+		t.Assert(client.PostContent(ctx, "/order", "id=1"), expect)
+
+		resp, err := client.Post(ctx, "/order", "id=1")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 200)
+		t.Assert(resp.ReadAllString(), expect)
+		t.Assert(resp.Header.Get(ghttp.HeaderIdempotentReplayed), "true")
+		resp.Close()
+		t.Assert(counter.Val(), 1)
+	})
+	// The handler error is not stored.
+	gtest.C(t, func(t *gtest.T) {
+		counter.Set(0)
+		client := g.Client().Prefix(prefix).Header(g.MapStrStr{"Idempotency-Key": guid.S()})
+		t.Assert(client.PostContent(ctx, "/order", "id=-1"), `{"code":53,"message":"invalid-1","data":null}`)
+
+		resp, err := client.Post(ctx, "/order", "id=-1")
+		t.AssertNil(err)
+		t.Assert(resp.ReadAllString(), `{"code":53,"message":"invalid-2","data":null}`)
+		t.Assert(resp.Header.Get(ghttp.HeaderIdempotentReplayed), "")
+		resp.Close()
+	})
+}
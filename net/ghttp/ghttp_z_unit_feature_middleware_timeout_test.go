@@ -0,0 +1,119 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package ghttp_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/gogf/gf/v2/util/guid"
+)
+
+type timeoutTestReq struct {
+	g.Meta `path:"/meta" method:"get" timeout:"100ms"`
+	Sleep  time.Duration
+}
+
+type timeoutTestRes struct {
+	Deadline bool
+}
+
+func Test_Middleware_Timeout(t *testing.T) {
+	s := g.Server(guid.S())
+	s.SetRequestTimeout(time.Second)
+	s.Use(ghttp.MiddlewareHandlerResponse)
+	s.BindHandler("/slow", func(r *ghttp.Request) {
+		time.Sleep(time.Second)
+		r.Response.Write("slow")
+	})
+	s.Group("/api", func(group *ghttp.RouterGroup) {
+		group.Middleware(ghttp.MiddlewareTimeout(200 * time.Millisecond))
+		group.ALL("/fast", func(r *ghttp.Request) {
+			_, ok := r.Context().Deadline()
+			r.Response.Write(ok)
+		})
+		group.ALL("/proxy", func(r *ghttp.Request) {
+			// The deadline is propagated to the client request.
+			_, err := g.Client().Get(r.Context(), fmt.Sprintf("http://127.0.0.1:%d/slow", s.GetListenedPort()))
+			r.Response.Write("partial")
+			r.SetError(err)
+		})
+		group.Bind(func(ctx context.Context, req *timeoutTestReq) (res *timeoutTestRes, err error) {
+			select {
+			case <-time.After(req.Sleep):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			_, ok := ctx.Deadline()
+			return &timeoutTestRes{Deadline: ok}, nil
+		})
+	})
+	s.Group("/config", func(group *ghttp.RouterGroup) {
+		group.Middleware(ghttp.MiddlewareTimeout())
+		group.ALL("/deadline", func(r *ghttp.Request) {
+			deadline, _ := r.Context().Deadline()
+			r.Response.Write(time.Until(deadline) > 500*time.Millisecond)
+		})
+	})
+	s.Group("/nested", func(group *ghttp.RouterGroup) {
+		group.Middleware(ghttp.MiddlewareTimeout(time.Millisecond), func(r *ghttp.Request) {
+			time.Sleep(50 * time.Millisecond)
+			r.Middleware.Next()
+		}, ghttp.MiddlewareTimeout(time.Second))
+		group.ALL("/exhausted", func(r *ghttp.Request) {
+			r.Response.Write("handled")
+		})
+	})
+	s.SetDumpRouterMap(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	client := g.Client().Prefix(fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort()))
+	gtest.C(t, func(t *gtest.T) {
+		t.Assert(client.GetContent(ctx, "/api/fast"), "true")
+		t.Assert(client.GetContent(ctx, "/config/deadline"), "true")
+	})
+	gtest.C(t, func(t *gtest.T) {
+		start := time.Now()
+		resp, err := client.Get(ctx, "/api/proxy")
+		t.AssertNil(err)
+		defer resp.Close()
+		t.Assert(time.Since(start) < 500*time.Millisecond, true)
+		t.Assert(resp.StatusCode, http.StatusGatewayTimeout)
+		t.Assert(
+			resp.ReadAllString(),
+			fmt.Sprintf(`{"code":%d,"message":"request timeout","data":null}`, gcode.CodeServerBusy.Code()),
+		)
+	})
+	// Timeout from meta tag.
+	gtest.C(t, func(t *gtest.T) {
+		t.Assert(client.GetContent(ctx, "/api/meta?sleep=10ms"), `{"code":0,"message":"OK","data":{"Deadline":true}}`)
+		resp, err := client.Get(ctx, "/api/meta?sleep=150ms")
+		t.AssertNil(err)
+		defer resp.Close()
+		t.Assert(resp.StatusCode, http.StatusGatewayTimeout)
+	})
+	// Deadline exhausted before handling.
+	gtest.C(t, func(t *gtest.T) {
+		resp, err := client.Get(ctx, "/nested/exhausted")
+		t.AssertNil(err)
+		defer resp.Close()
+		t.Assert(resp.StatusCode, http.StatusServiceUnavailable)
+		t.Assert(
+			resp.ReadAllString(),
+			fmt.Sprintf(`{"code":%d,"message":"request timeout","data":null}`, gcode.CodeServerBusy.Code()),
+		)
+	})
+}
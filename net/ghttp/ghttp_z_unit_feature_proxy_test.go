@@ -0,0 +1,337 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package ghttp_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel"
+
+	"github.com/gogf/gf/v2/container/gtype"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/net/gsel"
+	"github.com/gogf/gf/v2/net/gsvc"
+	"github.com/gogf/gf/v2/net/gtrace"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/gogf/gf/v2/util/guid"
+)
+
+// proxyTestTracerProvider is the default tracer provider, as some cases replace the global one.
+var proxyTestTracerProvider = otel.GetTracerProvider()
+
+// proxyTestDiscovery is the discovery resolving services to static endpoints.
+type proxyTestDiscovery struct {
+	endpoints gsvc.Endpoints
+}
+
+// proxyTestWatcher is the watcher that never changes.
+type proxyTestWatcher struct{}
+
+func (d *proxyTestDiscovery) Search(ctx context.Context, in gsvc.SearchInput) ([]gsvc.Service, error) {
+	return []gsvc.Service{&gsvc.LocalService{Name: in.Name, Endpoints: d.endpoints}}, nil
+}
+
+func (d *proxyTestDiscovery) Watch(ctx context.Context, key string) (gsvc.Watcher, error) {
+	return &proxyTestWatcher{}, nil
+}
+
+func (w *proxyTestWatcher) Proceed() ([]gsvc.Service, error) {
+	select {}
+}
+
+func (w *proxyTestWatcher) Close() error {
+	return nil
+}
+
+func startProxyTestUpstream(name string) *ghttp.Server {
+	s := g.Server(guid.S())
+	s.BindHandler("/*", func(r *ghttp.Request) {
+		r.Response.Header().Set("X-Upstream", name)
+		r.Response.Header().Set("X-Internal", "1")
+		r.Response.Writef(
+			"%s:%s:%s:%s:%s",
+			name, r.URL.RequestURI(), r.Header.Get("X-Gateway"),
+			r.Header.Get("X-Secret"), r.GetBodyString(),
+		)
+	})
+	s.BindHandler("/slow", func(r *ghttp.Request) {
+		time.Sleep(time.Second)
+		r.Response.Write("slow")
+	})
+	s.BindHandler("/trace", func(r *ghttp.Request) {
+		r.Response.Write(gtrace.GetTraceID(r.Context()))
+	})
+	s.BindHandler("/stream", func(r *ghttp.Request) {
+		r.Response.Header().Set("Content-Type", "text/event-stream")
+		for i := 0; i < 3; i++ {
+			r.Response.Writefln("data: %d", i)
+			r.Response.Flush()
+			time.Sleep(50 * time.Millisecond)
+		}
+	})
+	s.BindHandler("/ws", func(r *ghttp.Request) {
+		ws, err := r.WebSocket()
+		if err != nil {
+			r.Exit()
+		}
+		for {
+			msgType, msg, err := ws.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err = ws.WriteMessage(msgType, append([]byte(name+":"), msg...)); err != nil {
+				return
+			}
+		}
+	})
+	s.SetDumpRouterMap(false)
+	s.SetLogStdout(false)
+	s.Start()
+	return s
+}
+
+func Test_Proxy_Static(t *testing.T) {
+	var (
+		upstream1 = startProxyTestUpstream("u1")
+		upstream2 = startProxyTestUpstream("u2")
+	)
+	defer upstream1.Shutdown()
+	defer upstream2.Shutdown()
+
+	s := g.Server(guid.S())
+	s.BindProxy("/api/*", fmt.Sprintf("http://127.0.0.1:%d/v1", upstream1.GetListenedPort()), ghttp.ProxyOptions{
+		Upstreams:             []string{fmt.Sprintf("http://127.0.0.1:%d", upstream2.GetListenedPort())},
+		Builder:               gsel.NewBuilderRoundRobin(),
+		StripPrefix:           "/api",
+		RequestHeaders:        map[string]string{"X-Gateway": "gf"},
+		RemoveRequestHeaders:  []string{"X-Secret"},
+		ResponseHeaders:       map[string]string{"X-Proxied": "true"},
+		RemoveResponseHeaders: []string{"X-Internal"},
+	})
+	s.BindProxy("/raw/*", fmt.Sprintf("http://127.0.0.1:%d", upstream1.GetListenedPort()), ghttp.ProxyOptions{
+		StripPrefix: "/raw",
+	})
+	s.Group("/group", func(group *ghttp.RouterGroup) {
+		group.Proxy("/*", fmt.Sprintf("http://127.0.0.1:%d", upstream1.GetListenedPort()), ghttp.ProxyOptions{
+			RewritePath: func(path string) string {
+				return "/rewritten" + path
+			},
+		})
+	})
+	s.SetDumpRouterMap(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	client := g.Client().Prefix(fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort()))
+	gtest.C(t, func(t *gtest.T) {
+		var upstreams = make(map[string]int)
+		for i := 0; i < 4; i++ {
+			resp, err := client.Header(g.MapStrStr{"X-Secret": "s"}).Post(ctx, "/api/user?id=1", "body")
+			t.AssertNil(err)
+			t.Assert(resp.StatusCode, 200)
+			t.Assert(resp.Header.Get("X-Proxied"), "true")
+			t.Assert(resp.Header.Get("X-Internal"), "")
+			upstream := resp.Header.Get("X-Upstream")
+			upstreams[upstream]++
+			t.Assert(resp.ReadAllString(), upstream+":/v1/user?id=1:gf::body")
+			resp.Close()
+		}
+		t.Assert(upstreams["u1"], 2)
+		t.Assert(upstreams["u2"], 2)
+	})
+	gtest.C(t, func(t *gtest.T) {
+		t.Assert(client.GetContent(ctx, "/group/user"), "u1:/rewritten/group/user:::")
+		tracerProvider := otel.GetTracerProvider()
+		otel.SetTracerProvider(proxyTestTracerProvider)
+		defer otel.SetTracerProvider(tracerProvider)
+		traceCtx, err := gtrace.WithTraceID(ctx, "0102030405060708090a0b0c0d0e0f10")
+		t.AssertNil(err)
+		t.Assert(client.GetContent(traceCtx, "/raw/trace"), "0102030405060708090a0b0c0d0e0f10")
+	})
+	// Streaming.
+	gtest.C(t, func(t *gtest.T) {
+		resp, err := client.Get(ctx, "/raw/stream")
+		t.AssertNil(err)
+		defer resp.Close()
+		var (
+			reader = bufio.NewReader(resp.Body)
+			start  = time.Now()
+		)
+		line, err := reader.ReadString('\n')
+		t.AssertNil(err)
+		t.Assert(line, "data: 0\n")
+		t.Assert(time.Since(start) < 100*time.Millisecond, true)
+		t.Assert(resp.ReadAllString(), "data: 1\ndata: 2\n")
+	})
+	// WebSocket.
+	gtest.C(t, func(t *gtest.T) {
+		conn, _, err := websocket.DefaultDialer.Dial(
+			fmt.Sprintf("ws://127.0.0.1:%d/raw/ws", s.GetListenedPort()), nil,
+		)
+		t.AssertNil(err)
+		defer conn.Close()
+		t.AssertNil(conn.WriteMessage(websocket.TextMessage, []byte("hello")))
+		_, msg, err := conn.ReadMessage()
+		t.AssertNil(err)
+		t.Assert(string(msg), "u1:hello")
+	})
+}
+
+func Test_Proxy_Retry_Timeout(t *testing.T) {
+	upstream := startProxyTestUpstream("u1")
+	defer upstream.Shutdown()
+
+	var (
+		errs     = make(chan error, 1)
+		target   = fmt.Sprintf("http://127.0.0.1:%d", upstream.GetListenedPort())
+		deadAddr = "127.0.0.1:1"
+	)
+	s := g.Server(guid.S())
+	s.BindProxy("/retry/*", "http://"+deadAddr, ghttp.ProxyOptions{
+		Upstreams:   []string{target},
+		Builder:     gsel.NewBuilderRoundRobin(),
+		StripPrefix: "/retry",
+		Retry:       1,
+	})
+	s.BindProxy("/dead/*", "http://"+deadAddr)
+	s.BindProxy("/timeout/*", target, ghttp.ProxyOptions{
+		StripPrefix: "/timeout",
+		Timeout:     200 * time.Millisecond,
+		ErrorHandler: func(r *ghttp.Request, err error) {
+			errs <- err
+			r.Response.WriteStatus(http.StatusGatewayTimeout, "timeout")
+		},
+	})
+	s.SetDumpRouterMap(false)
+	s.SetLogStdout(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	client := g.Client().Prefix(fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort()))
+	gtest.C(t, func(t *gtest.T) {
+		for i := 0; i < 2; i++ {
+			t.Assert(client.GetContent(ctx, "/retry/user"), "u1:/user:::")
+		}
+		resp, err := client.Get(ctx, "/dead/user")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, http.StatusBadGateway)
+		resp.Close()
+	})
+	gtest.C(t, func(t *gtest.T) {
+		resp, err := client.Get(ctx, "/timeout/slow")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, http.StatusGatewayTimeout)
+		t.Assert(resp.ReadAllString(), "timeout")
+		resp.Close()
+		t.Assert(gerror.Is(<-errs, context.DeadlineExceeded), true)
+		t.Assert(client.GetContent(ctx, "/timeout/user"), "u1:/user:::")
+	})
+}
+
+func Test_Proxy_Retry_Idempotent(t *testing.T) {
+	var (
+		counter  = gtype.NewInt()
+		upstream = g.Server(guid.S())
+	)
+	upstream.BindHandler("/*", func(r *ghttp.Request) {
+		counter.Add(1)
+		r.Response.WriteStatus(http.StatusServiceUnavailable)
+	})
+	upstream.SetDumpRouterMap(false)
+	upstream.SetLogStdout(false)
+	upstream.Start()
+	defer upstream.Shutdown()
+
+	var (
+		s      = g.Server(guid.S())
+		target = fmt.Sprintf("http://127.0.0.1:%d", upstream.GetListenedPort())
+	)
+	// The request body is read by the server, which is repeatable for retries.
+	s.BindMiddlewareDefault(func(r *ghttp.Request) {
+		r.GetBody()
+		r.Middleware.Next()
+	})
+	s.BindProxy("/idempotent/*", target, ghttp.ProxyOptions{Retry: 2})
+	s.BindProxy("/all/*", target, ghttp.ProxyOptions{Retry: 2, RetryNonIdempotent: true})
+	s.SetDumpRouterMap(false)
+	s.SetLogStdout(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	client := g.Client().Prefix(fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort()))
+	gtest.C(t, func(t *gtest.T) {
+		for _, item := range []struct {
+			method  string
+			path    string
+			header  g.MapStrStr
+			retried bool
+		}{
+			{http.MethodGet, "/idempotent/user", nil, true},
+			{http.MethodPost, "/idempotent/user", nil, false},
+			{http.MethodPost, "/idempotent/user", g.MapStrStr{"Idempotency-Key": "key"}, true},
+			{http.MethodPost, "/all/user", nil, true},
+		} {
+			counter.Set(0)
+			resp, err := client.Header(item.header).DoRequest(ctx, item.method, item.path, "data")
+			t.AssertNil(err)
+			t.Assert(resp.StatusCode, http.StatusServiceUnavailable)
+			resp.Close()
+			if item.retried {
+				t.Assert(counter.Val(), 3)
+			} else {
+				t.Assert(counter.Val(), 1)
+			}
+		}
+	})
+	// It panics binding invalid target.
+	gtest.C(t, func(t *gtest.T) {
+		defer func() {
+			t.AssertNE(recover(), nil)
+		}()
+		g.Server(guid.S()).BindProxy("/*", "://invalid")
+	})
+}
+
+func Test_Proxy_Discovery(t *testing.T) {
+	upstream := startProxyTestUpstream("u1")
+	defer upstream.Shutdown()
+
+	s := g.Server(guid.S())
+	s.BindProxy("/*", "http://proxy-test-service", ghttp.ProxyOptions{
+		Discovery: &proxyTestDiscovery{
+			endpoints: gsvc.NewEndpoints(fmt.Sprintf("127.0.0.1:%d", upstream.GetListenedPort())),
+		},
+	})
+	s.BindProxy("/missing/*", "http://proxy-test-service", ghttp.ProxyOptions{
+		Discovery: &proxyTestDiscovery{},
+	})
+	s.SetDumpRouterMap(false)
+	s.SetLogStdout(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	client := g.Client().Prefix(fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort()))
+	gtest.C(t, func(t *gtest.T) {
+		t.Assert(client.GetContent(ctx, "/user"), "u1:/user:::")
+		resp, err := client.Get(ctx, "/missing/user")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, http.StatusServiceUnavailable)
+		resp.Close()
+	})
+}
@@ -0,0 +1,117 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package ghttp_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/gogf/gf/v2/util/guid"
+)
+
+func Test_Router_Constraint(t *testing.T) {
+	ghttp.RegisterRouteConstraint("code", `[A-Z]{3}`)
+
+	s := g.Server(guid.S())
+	s.BindHandler("/user/{id:int}", func(r *ghttp.Request) {
+		r.Response.Write("id:", r.Get("id"))
+	})
+	s.BindHandler("/user/{name}", func(r *ghttp.Request) {
+		r.Response.Write("name:", r.Get("name"))
+	})
+	s.BindHandler("/order/{uuid:uuid}", func(r *ghttp.Request) {
+		r.Response.Write("uuid:", r.Get("uuid"))
+	})
+	s.BindHandler("/post/{slug:[a-z-]+}/{page:uint}", func(r *ghttp.Request) {
+		r.Response.Write(r.Get("slug"), ":", r.Get("page"))
+	})
+	s.BindHandler("/currency/{code:code}.{format:(json|xml)}", func(r *ghttp.Request) {
+		r.Response.Write(r.Get("code"), ":", r.Get("format"))
+	})
+	s.SetDumpRouterMap(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	gtest.C(t, func(t *gtest.T) {
+		client := g.Client()
+		client.SetPrefix(fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort()))
+
+		// Falls through to the unconstrained route.
+		t.Assert(client.GetContent(ctx, "/user/100"), "id:100")
+		t.Assert(client.GetContent(ctx, "/user/-1"), "id:-1")
+		t.Assert(client.GetContent(ctx, "/user/john"), "name:john")
+
+		t.Assert(client.GetContent(ctx, "/order/9b2f6d4e-8a1c-4f3e-9d2a-1c3b5e7f9a0b"), "uuid:9b2f6d4e-8a1c-4f3e-9d2a-1c3b5e7f9a0b")
+		t.Assert(client.GetContent(ctx, "/order/123"), "Not Found")
+
+		t.Assert(client.GetContent(ctx, "/post/hello-world/2"), "hello-world:2")
+		t.Assert(client.GetContent(ctx, "/post/Hello/2"), "Not Found")
+		t.Assert(client.GetContent(ctx, "/post/hello/-2"), "Not Found")
+
+		t.Assert(client.GetContent(ctx, "/currency/USD.json"), "USD:json")
+		t.Assert(client.GetContent(ctx, "/currency/USD.yaml"), "Not Found")
+		t.Assert(client.GetContent(ctx, "/currency/usd.xml"), "Not Found")
+	})
+}
+
+func Test_Router_Constraint_OpenApi(t *testing.T) {
+	type GetUserReq struct {
+		g.Meta `path:"/user/{id:int}" method:"get"`
+		Id     int `json:"id"`
+	}
+	type GetUserRes struct {
+		Id int `json:"id"`
+	}
+	type GetOrderReq struct {
+		g.Meta `path:"/order/{uuid:uuid}" method:"get"`
+		Uuid   string `json:"uuid"`
+	}
+	type GetOrderRes struct {
+		Uuid string `json:"uuid"`
+	}
+	s := g.Server(guid.S())
+	s.Use(ghttp.MiddlewareHandlerResponse)
+	s.Group("/", func(group *ghttp.RouterGroup) {
+		group.Bind(
+			func(ctx context.Context, req *GetUserReq) (res *GetUserRes, err error) {
+				return &GetUserRes{Id: req.Id}, nil
+			},
+			func(ctx context.Context, req *GetOrderReq) (res *GetOrderRes, err error) {
+				return &GetOrderRes{Uuid: req.Uuid}, nil
+			},
+		)
+	})
+	s.SetOpenApiPath("/api.json")
+	s.SetDumpRouterMap(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	gtest.C(t, func(t *gtest.T) {
+		client := g.Client()
+		client.SetPrefix(fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort()))
+		t.Assert(client.GetContent(ctx, "/user/1"), `{"code":0,"message":"OK","data":{"id":1}}`)
+		t.Assert(client.GetContent(ctx, "/user/a"), `{"code":65,"message":"Not Found","data":null}`)
+	})
+	gtest.C(t, func(t *gtest.T) {
+		openapi := s.GetOpenApi()
+		_, ok := openapi.Paths["/user/{id:int}"]
+		t.Assert(ok, false)
+		parameter := openapi.Paths["/user/{id}"].Get.Parameters[0].Value
+		t.Assert(parameter.In, "path")
+		t.Assert(parameter.Schema.Value.Pattern, `^-?[0-9]+$`)
+
+		parameter = openapi.Paths["/order/{uuid}"].Get.Parameters[0].Value
+		t.Assert(parameter.Schema.Value.Format, "uuid")
+	})
+}
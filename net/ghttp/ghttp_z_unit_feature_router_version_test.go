@@ -0,0 +1,193 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package ghttp_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/gogf/gf/v2/util/guid"
+)
+
+type versionTestUserV1Req struct {
+	g.Meta `path:"/user" method:"get" summary:"user v1"`
+}
+
+type versionTestUserV2Req struct {
+	g.Meta `path:"/user" method:"get" version:"v2" summary:"user v2"`
+}
+
+type versionTestOrderReq struct {
+	g.Meta `path:"/order" method:"get" summary:"order v1"`
+}
+
+type versionTestRes struct {
+	Version string `json:"version"`
+}
+
+type versionTestController struct{}
+
+func (versionTestController) UserV1(ctx context.Context, req *versionTestUserV1Req) (res *versionTestRes, err error) {
+	return &versionTestRes{Version: "user:" + g.RequestFromCtx(ctx).GetApiVersion()}, nil
+}
+
+func (versionTestController) UserV2(ctx context.Context, req *versionTestUserV2Req) (res *versionTestRes, err error) {
+	return &versionTestRes{Version: "user:" + g.RequestFromCtx(ctx).GetApiVersion()}, nil
+}
+
+func (versionTestController) Order(ctx context.Context, req *versionTestOrderReq) (res *versionTestRes, err error) {
+	return &versionTestRes{Version: "order:" + g.RequestFromCtx(ctx).GetApiVersion()}, nil
+}
+
+func Test_Router_Version(t *testing.T) {
+	var (
+		c         = versionTestController{}
+		sunset    = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+		deprecate = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	)
+	s := g.Server(guid.S())
+	s.SetOpenApiPath("/api.json")
+	s.SetApiVersionFallback(true)
+	s.SetApiVersionDeprecation("v1", ghttp.ApiVersionDeprecation{
+		Date:   deprecate,
+		Sunset: sunset,
+		Link:   "https://goframe.org/v1",
+	})
+	s.Group("/api", func(group *ghttp.RouterGroup) {
+		group.Middleware(ghttp.MiddlewareHandlerResponse)
+		group.Version("v1", func(group *ghttp.RouterGroup) {
+			group.Bind(c.UserV1, c.Order)
+		})
+		// Version from meta tag.
+		group.Bind(c.UserV2)
+		group.ALL("/ping", func(r *ghttp.Request) {
+			r.Response.Write("pong:" + r.GetApiVersion())
+		})
+	})
+	s.SetDumpRouterMap(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	prefix := fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort())
+	gtest.C(t, func(t *gtest.T) {
+		t.Assert(s.GetApiVersions(), g.Slice{"v1", "v2"})
+
+		client := g.Client().Prefix(prefix)
+		// Path prefix.
+		t.Assert(client.GetContent(ctx, "/api/v1/user"), `{"code":0,"message":"OK","data":{"version":"user:v1"}}`)
+		t.Assert(client.GetContent(ctx, "/api/v2/user"), `{"code":0,"message":"OK","data":{"version":"user:v2"}}`)
+		// Latest version in default.
+		t.Assert(client.GetContent(ctx, "/api/user"), `{"code":0,"message":"OK","data":{"version":"user:v2"}}`)
+		// Header.
+		t.Assert(
+			client.Header(g.MapStrStr{"Accept-Version": "1"}).GetContent(ctx, "/api/user"),
+			`{"code":0,"message":"OK","data":{"version":"user:v1"}}`,
+		)
+		// Media type parameter.
+		t.Assert(
+			client.Header(g.MapStrStr{"Accept": "application/json; version=v1"}).GetContent(ctx, "/api/user"),
+			`{"code":0,"message":"OK","data":{"version":"user:v1"}}`,
+		)
+		// Fallback to lower version.
+		t.Assert(client.GetContent(ctx, "/api/order"), `{"code":0,"message":"OK","data":{"version":"order:v1"}}`)
+		t.Assert(
+			client.Header(g.MapStrStr{"Accept-Version": "v3"}).GetContent(ctx, "/api/user"),
+			`{"code":0,"message":"OK","data":{"version":"user:v2"}}`,
+		)
+		// Routes without version.
+		t.Assert(client.GetContent(ctx, "/api/ping"), "pong:v2")
+	})
+	// Deprecation headers.
+	gtest.C(t, func(t *gtest.T) {
+		client := g.Client().Prefix(prefix)
+		resp, err := client.Get(ctx, "/api/v1/user")
+		t.AssertNil(err)
+		defer resp.Close()
+		t.Assert(resp.Header.Get("Deprecation"), fmt.Sprintf("@%d", deprecate.Unix()))
+		t.Assert(resp.Header.Get("Sunset"), "Tue, 01 Jan 2030 00:00:00 GMT")
+		t.Assert(resp.Header.Get("Link"), `<https://goframe.org/v1>; rel="deprecation"`)
+
+		resp2, err := client.Get(ctx, "/api/v2/user")
+		t.AssertNil(err)
+		defer resp2.Close()
+		t.Assert(resp2.Header.Get("Deprecation"), "")
+	})
+	// OpenApi specifications.
+	gtest.C(t, func(t *gtest.T) {
+		client := g.Client().Prefix(prefix)
+		j, err := gjson.LoadContent(client.GetBytes(ctx, "/api.json"))
+		t.AssertNil(err)
+		t.Assert(j.Get(`paths./api/v1/user.get.deprecated`), true)
+		t.Assert(j.Get(`paths./api/v2/user.get.deprecated`), nil)
+		t.Assert(j.Get(`paths./api/user`), nil)
+
+		j, err = gjson.LoadContent(client.GetBytes(ctx, "/api.json?version=v1"))
+		t.AssertNil(err)
+		t.Assert(j.Get(`info.version`), "v1")
+		t.Assert(j.Get(`paths./api/v1/user.get.summary`), "user v1")
+		t.Assert(j.Get(`paths./api/v1/order.get.summary`), "order v1")
+		t.Assert(j.Get(`paths./api/v2/user`), nil)
+
+		j, err = gjson.LoadContent(client.GetBytes(ctx, "/api.json?version=2"))
+		t.AssertNil(err)
+		t.Assert(j.Get(`info.version`), "v2")
+		t.Assert(j.Get(`paths./api/v2/user.get.summary`), "user v2")
+		t.Assert(j.Get(`paths./api/v1/user`), nil)
+
+		resp, err := client.Get(ctx, "/api.json?version=v9")
+		t.AssertNil(err)
+		defer resp.Close()
+		t.Assert(resp.StatusCode, http.StatusNotFound)
+		t.AssertNE(s.GetOpenApiByVersion("v1"), nil)
+	})
+}
+
+func Test_Router_Version_NoFallback(t *testing.T) {
+	s := g.Server(guid.S())
+	s.SetApiVersionDefault("v1")
+	s.Group("/api", func(group *ghttp.RouterGroup) {
+		group.Version("v1", func(group *ghttp.RouterGroup) {
+			group.ALL("/user", func(r *ghttp.Request) {
+				r.Response.Write("user:" + r.GetApiVersion())
+			})
+		})
+		group.Version("v2", func(group *ghttp.RouterGroup) {
+			group.ALL("/user", func(r *ghttp.Request) {
+				r.Response.Write("user:" + r.GetApiVersion())
+			})
+			group.ALL("/order", func(r *ghttp.Request) {
+				r.Response.Write("order:" + r.GetApiVersion())
+			})
+		})
+	})
+	s.SetDumpRouterMap(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	gtest.C(t, func(t *gtest.T) {
+		client := g.Client().Prefix(fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort()))
+		// Configured default version.
+		t.Assert(client.GetContent(ctx, "/api/user"), "user:v1")
+		t.Assert(client.Header(g.MapStrStr{"Accept-Version": "v2"}).GetContent(ctx, "/api/user"), "user:v2")
+		t.Assert(client.Header(g.MapStrStr{"Accept-Version": "v2"}).GetContent(ctx, "/api/order"), "order:v2")
+		t.Assert(client.GetContent(ctx, "/api/v2/order"), "order:v2")
+
+		resp, err := client.Get(ctx, "/api/order")
+		t.AssertNil(err)
+		defer resp.Close()
+		t.Assert(resp.StatusCode, http.StatusNotFound)
+	})
+}
@@ -0,0 +1,176 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package ghttp_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/os/gfile"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/gogf/gf/v2/util/guid"
+)
+
+func Test_TestClient(t *testing.T) {
+	type HelloReq struct {
+		g.Meta `path:"/hello" method:"get"`
+		Name   string `v:"required"`
+	}
+	type HelloRes struct {
+		Content string `json:"content"`
+	}
+	s := g.Server(guid.S())
+	s.Group("/", func(group *ghttp.RouterGroup) {
+		group.Middleware(ghttp.MiddlewareHandlerResponse)
+		group.Bind(func(ctx context.Context, req *HelloReq) (res *HelloRes, err error) {
+			if req.Name == "error" {
+				return nil, gerror.NewCode(gcode.CodeNotSupported, "not supported")
+			}
+			return &HelloRes{Content: "hello " + req.Name}, nil
+		})
+	})
+	s.BindHandler("/session/set", func(r *ghttp.Request) {
+		r.Session.MustSet("name", r.Get("name"))
+		r.Cookie.Set("theme", "dark")
+	})
+	s.BindHandler("/session/get", func(r *ghttp.Request) {
+		r.Response.Write(r.Session.MustGet("name"), ":", r.Cookie.Get("theme"))
+	})
+	s.BindHandler("/request", func(r *ghttp.Request) {
+		r.Response.Write(r.Host, ":", r.GetClientIp(), ":", r.GetBodyString())
+	})
+	s.SetDumpRouterMap(false)
+
+	gtest.C(t, func(t *gtest.T) {
+		client, err := s.TestClient()
+		t.AssertNil(err)
+		t.Assert(
+			client.GetContent(ctx, "/hello?name=john"),
+			`{"code":0,"message":"OK","data":{"content":"hello john"}}`,
+		)
+		resp, err := client.Get(ctx, "/hello?name=error")
+		t.AssertNil(err)
+		defer resp.Close()
+		record := ghttp.GetTestRecord(resp)
+		t.AssertNE(record, nil)
+		t.Assert(record.Status, 200)
+		t.Assert(gerror.Code(record.Request.GetError()), gcode.CodeNotSupported)
+		t.Assert(string(record.Body), resp.ReadAllString())
+	})
+	gtest.C(t, func(t *gtest.T) {
+		client, err := s.TestClient()
+		t.AssertNil(err)
+		t.Assert(client.GetContent(ctx, "/session/set?name=john"), "")
+		t.Assert(client.GetContent(ctx, "/session/get"), "john:dark")
+		other, err := s.TestClient()
+		t.AssertNil(err)
+		t.Assert(other.GetContent(ctx, "/session/get"), ":")
+	})
+	gtest.C(t, func(t *gtest.T) {
+		client, err := s.TestClient()
+		t.AssertNil(err)
+		t.Assert(client.PostContent(ctx, "/request", "body"), "localhost:127.0.0.1:body")
+
+		resp, err := client.Get(ctx, "/not-found")
+		t.AssertNil(err)
+		defer resp.Close()
+		t.Assert(resp.StatusCode, 404)
+		t.Assert(ghttp.GetTestRecord(resp).Status, 404)
+	})
+}
+
+func Test_TestClient_Prepare(t *testing.T) {
+	var (
+		s    = g.Server(guid.S())
+		file = gfile.Temp(guid.S())
+	)
+	s.SetDumpRouterMap(false)
+	s.BindHandler("/first", func(r *ghttp.Request) {
+		r.Response.Write("first")
+	})
+	gtest.C(t, func(t *gtest.T) {
+		t.AssertNil(gfile.PutContents(file, ""))
+		defer gfile.Remove(file)
+
+		// It fails preparing as the session path is a file.
+		t.AssertNil(s.SetConfigWithMap(g.Map{"sessionPath": file}))
+		_, err := s.TestClient()
+		t.AssertNE(err, nil)
+
+		// It is prepared again after the failure is fixed.
+		t.AssertNil(s.SetConfigWithMap(g.Map{"sessionPath": gfile.Temp(guid.S())}))
+		client, err := s.TestClient()
+		t.AssertNil(err)
+		t.Assert(client.GetContent(ctx, "/first"), "first")
+
+		// The group routes registered after the client is created are bound before the next request.
+		s.Group("/", func(group *ghttp.RouterGroup) {
+			group.GET("/second", func(r *ghttp.Request) {
+				r.Response.Write("second")
+			})
+		})
+		t.Assert(client.GetContent(ctx, "/second"), "second")
+	})
+}
+
+func Test_TestClient_WebSocket(t *testing.T) {
+	s := g.Server(guid.S())
+	s.BindHandler("/ws", func(r *ghttp.Request) {
+		ws, err := r.WebSocket()
+		if err != nil {
+			r.Exit()
+		}
+		for {
+			msgType, msg, err := ws.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err = ws.WriteMessage(msgType, msg); err != nil {
+				return
+			}
+		}
+	})
+	s.SetDumpRouterMap(false)
+
+	gtest.C(t, func(t *gtest.T) {
+		dialer := websocket.Dialer{NetDialContext: s.TestDialContext}
+		conn, _, err := dialer.Dial("ws://localhost/ws", nil)
+		t.AssertNil(err)
+		defer conn.Close()
+
+		err = conn.WriteMessage(websocket.TextMessage, []byte("hello"))
+		t.AssertNil(err)
+		msgType, msg, err := conn.ReadMessage()
+		t.AssertNil(err)
+		t.Assert(msgType, websocket.TextMessage)
+		t.Assert(string(msg), "hello")
+	})
+}
+
+func Test_TestClient_Shutdown(t *testing.T) {
+	s := g.Server(guid.S())
+	s.BindHandler("/hello", func(r *ghttp.Request) {
+		r.Response.Write("hello")
+	})
+	s.SetDumpRouterMap(false)
+
+	gtest.C(t, func(t *gtest.T) {
+		conn, err := s.TestDialContext(ctx, "tcp", ghttp.TestClientHost)
+		t.AssertNil(err)
+		t.AssertNil(conn.Close())
+
+		t.AssertNil(s.Shutdown())
+		_, err = s.TestDialContext(ctx, "tcp", ghttp.TestClientHost)
+		t.Assert(gerror.Code(err), gcode.CodeInvalidOperation)
+	})
+}
@@ -0,0 +1,253 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package ghttp_test
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/os/gfile"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/gogf/gf/v2/util/guid"
+)
+
+func Test_Tus_Upload(t *testing.T) {
+	var (
+		storagePath = gfile.Temp(guid.S())
+		savePath    = gfile.Temp(guid.S())
+		savedName   string
+	)
+	defer gfile.Remove(storagePath)
+	defer gfile.Remove(savePath)
+
+	s := g.Server(guid.S())
+	s.BindTus("/files", ghttp.TusOptions{
+		Storage: ghttp.NewTusStorageFile(storagePath),
+		MaxSize: 1024,
+		OnComplete: func(r *ghttp.Request, info *ghttp.TusUploadInfo) (err error) {
+			savedName, err = info.Save(r.Context(), savePath)
+			return
+		},
+	})
+	s.SetDumpRouterMap(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	prefix := fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort())
+	gtest.C(t, func(t *gtest.T) {
+		client := g.Client().Prefix(prefix).Header(g.MapStrStr{"Tus-Resumable": "1.0.0"})
+
+		// Discovery.
+		resp, err := client.Options(ctx, "/files")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 204)
+		t.Assert(resp.Header.Get("Tus-Max-Size"), "1024")
+		t.AssertNE(resp.Header.Get("Tus-Extension"), "")
+		resp.Close()
+
+		// Creation.
+		resp, err = client.Header(g.MapStrStr{
+			"Upload-Length":   "11",
+			"Upload-Metadata": "filename " + base64.StdEncoding.EncodeToString([]byte("hello.txt")),
+		}).Post(ctx, "/files")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 201)
+		location := resp.Header.Get("Location")
+		t.AssertNE(location, "")
+		t.AssertNE(resp.Header.Get("Upload-Expires"), "")
+		resp.Close()
+
+		// First chunk.
+		patchClient := client.ContentType("application/offset+octet-stream")
+		resp, err = patchClient.Header(g.MapStrStr{"Upload-Offset": "0"}).Patch(ctx, location, "hello")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 204)
+		t.Assert(resp.Header.Get("Upload-Offset"), "5")
+		resp.Close()
+
+		// Offset.
+		resp, err = client.Head(ctx, location)
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 200)
+		t.Assert(resp.Header.Get("Upload-Offset"), "5")
+		t.Assert(resp.Header.Get("Upload-Length"), "11")
+		resp.Close()
+
+		// Mismatched offset.
+		resp, err = patchClient.Header(g.MapStrStr{"Upload-Offset": "3"}).Patch(ctx, location, " world")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 409)
+		resp.Close()
+
+		// Mismatched checksum.
+		resp, err = patchClient.Header(g.MapStrStr{
+			"Upload-Offset":   "5",
+			"Upload-Checksum": "sha1 " + base64.StdEncoding.EncodeToString([]byte("invalid")),
+		}).Patch(ctx, location, " world")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 460)
+		resp.Close()
+
+		// Completion with checksum.
+		sum := sha1.Sum([]byte(" world"))
+		resp, err = patchClient.Header(g.MapStrStr{
+			"Upload-Offset":   "5",
+			"Upload-Checksum": "sha1 " + base64.StdEncoding.EncodeToString(sum[:]),
+		}).Patch(ctx, location, " world")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 204)
+		t.Assert(resp.Header.Get("Upload-Offset"), "11")
+		resp.Close()
+		t.Assert(savedName, "hello.txt")
+		t.Assert(gfile.GetContents(gfile.Join(savePath, "hello.txt")), "hello world")
+
+		// Termination.
+		resp, err = client.Delete(ctx, location)
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 204)
+		resp.Close()
+		resp, err = client.Head(ctx, location)
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 404)
+		resp.Close()
+	})
+	gtest.C(t, func(t *gtest.T) {
+		client := g.Client().Prefix(prefix)
+		// Missing version.
+		resp, err := client.Header(g.MapStrStr{"Upload-Length": "1"}).Post(ctx, "/files")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 412)
+		resp.Close()
+
+		client = client.Header(g.MapStrStr{"Tus-Resumable": "1.0.0"})
+		// Too large.
+		resp, err = client.Header(g.MapStrStr{"Upload-Length": "1025"}).Post(ctx, "/files")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 413)
+		resp.Close()
+
+		// Creation with upload.
+		savedName = ""
+		resp, err = client.ContentType("application/offset+octet-stream").
+			Header(g.MapStrStr{"Upload-Length": "3"}).Post(ctx, "/files", "abc")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 201)
+		t.Assert(resp.Header.Get("Upload-Offset"), "3")
+		resp.Close()
+		t.AssertNE(savedName, "")
+		t.Assert(gfile.GetContents(gfile.Join(savePath, savedName)), "abc")
+	})
+}
+
+func Test_Tus_Expiration(t *testing.T) {
+	storagePath := gfile.Temp(guid.S())
+	defer gfile.Remove(storagePath)
+
+	s := g.Server(guid.S())
+	s.BindTus("/files", ghttp.TusOptions{
+		Storage:    ghttp.NewTusStorageFile(storagePath),
+		Expiration: 500 * time.Millisecond,
+	})
+	s.SetDumpRouterMap(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	gtest.C(t, func(t *gtest.T) {
+		client := g.Client().
+			Prefix(fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort())).
+			Header(g.MapStrStr{"Tus-Resumable": "1.0.0"})
+		resp, err := client.Header(g.MapStrStr{"Upload-Length": "10"}).Post(ctx, "/files")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 201)
+		location := resp.Header.Get("Location")
+		resp.Close()
+
+		time.Sleep(time.Second)
+		resp, err = client.Head(ctx, location)
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 410)
+		resp.Close()
+
+		resp, err = client.Head(ctx, location)
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 404)
+		resp.Close()
+	})
+}
+
+// tusFailStorage is the storage that fails after writing at most `limit` bytes once.
+type tusFailStorage struct {
+	*ghttp.TusStorageFile
+	limit int64
+}
+
+func (s *tusFailStorage) Write(ctx context.Context, id string, offset int64, reader io.Reader) (int64, error) {
+	if s.limit <= 0 {
+		return s.TusStorageFile.Write(ctx, id, offset, reader)
+	}
+	n, err := s.TusStorageFile.Write(ctx, id, offset, io.LimitReader(reader, s.limit))
+	s.limit = 0
+	if err == nil {
+		err = errors.New("disk full")
+	}
+	return n, err
+}
+
+func Test_Tus_WriteError(t *testing.T) {
+	storagePath := gfile.Temp(guid.S())
+	defer gfile.Remove(storagePath)
+
+	s := g.Server(guid.S())
+	s.BindTus("/files", ghttp.TusOptions{
+		Storage: &tusFailStorage{TusStorageFile: ghttp.NewTusStorageFile(storagePath), limit: 5},
+	})
+	s.SetDumpRouterMap(false)
+	s.Start()
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	gtest.C(t, func(t *gtest.T) {
+		client := g.Client().
+			Prefix(fmt.Sprintf("http://127.0.0.1:%d", s.GetListenedPort())).
+			Header(g.MapStrStr{"Tus-Resumable": "1.0.0"})
+		resp, err := client.Header(g.MapStrStr{"Upload-Length": "11"}).Post(ctx, "/files")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 201)
+		location := resp.Header.Get("Location")
+		resp.Close()
+
+		// The failed writing is not reported as success.
+		patchClient := client.ContentType("application/offset+octet-stream")
+		resp, err = patchClient.Header(g.MapStrStr{"Upload-Offset": "0"}).Patch(ctx, location, "hello world")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 500)
+		t.Assert(resp.Header.Get("Upload-Offset"), "")
+		resp.Close()
+
+		// The upload is resumed from the persisted offset.
+		resp, err = client.Head(ctx, location)
+		t.AssertNil(err)
+		t.Assert(resp.Header.Get("Upload-Offset"), "5")
+		resp.Close()
+
+		resp, err = patchClient.Header(g.MapStrStr{"Upload-Offset": "5"}).Patch(ctx, location, " world")
+		t.AssertNil(err)
+		t.Assert(resp.StatusCode, 204)
+		t.Assert(resp.Header.Get("Upload-Offset"), "11")
+		resp.Close()
+	})
+}
@@ -0,0 +1,265 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package ghttp_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/gogf/gf/v2/util/guid"
+)
+
+// hubTestBackplane is the in-memory backplane shared by hubs.
+type hubTestBackplane struct {
+	mu       sync.Mutex
+	handlers []func(ctx context.Context, payload []byte)
+}
+
+func (b *hubTestBackplane) Publish(ctx context.Context, payload []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, handler := range b.handlers {
+		handler(ctx, payload)
+	}
+	return nil
+}
+
+func (b *hubTestBackplane) Subscribe(ctx context.Context, handler func(ctx context.Context, payload []byte)) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, handler)
+	return nil
+}
+
+func startWebSocketHubServer(hub *ghttp.WebSocketHub) *ghttp.Server {
+	s := g.Server(guid.S())
+	s.BindHandler("/ws", hub.Handle)
+	s.SetDumpRouterMap(false)
+	s.SetLogStdout(false)
+	s.Start()
+	time.Sleep(100 * time.Millisecond)
+	return s
+}
+
+func dialWebSocketHub(t *gtest.T, s *ghttp.Server, query string) *websocket.Conn {
+	conn, _, err := websocket.DefaultDialer.Dial(
+		fmt.Sprintf("ws://127.0.0.1:%d/ws?%s", s.GetListenedPort(), query), nil,
+	)
+	t.AssertNil(err)
+	return conn
+}
+
+func readWebSocketHubMessage(t *gtest.T, conn *websocket.Conn) *gjson.Json {
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, content, err := conn.ReadMessage()
+	t.AssertNil(err)
+	j, err := gjson.LoadContent(content)
+	t.AssertNil(err)
+	return j
+}
+
+func Test_WebSocketHub_Routing(t *testing.T) {
+	type JoinReq struct {
+		Room string `v:"required"`
+	}
+	type SendReq struct {
+		Room string `v:"required"`
+		Text string `v:"required"`
+	}
+	type SendRes struct {
+		Sent bool `json:"sent"`
+	}
+	hub := ghttp.NewWebSocketHub(ghttp.WebSocketHubOptions{
+		OnConnect: func(conn *ghttp.WebSocketConn) error {
+			if user := conn.Request().Get("user").String(); user != "" {
+				conn.SetUserId(user)
+				return nil
+			}
+			return gerror.NewCode(gcode.CodeNotAuthorized, "user required")
+		},
+	})
+	defer hub.Close()
+	hub.On("room.join", func(ctx context.Context, req *JoinReq) error {
+		ghttp.WebSocketConnFromCtx(ctx).Join(req.Room)
+		return nil
+	})
+	hub.On("room.send", func(ctx context.Context, req *SendReq) (res *SendRes, err error) {
+		conn := ghttp.WebSocketConnFromCtx(ctx)
+		err = hub.BroadcastRoom(ctx, req.Room, "room.message", g.Map{
+			"user": conn.UserId(),
+			"text": req.Text,
+		}, conn.Id())
+		return &SendRes{Sent: err == nil}, err
+	})
+	s := startWebSocketHubServer(hub)
+	defer s.Shutdown()
+
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			john  = dialWebSocketHub(t, s, "user=john")
+			smith = dialWebSocketHub(t, s, "user=smith")
+		)
+		defer john.Close()
+		defer smith.Close()
+
+		for _, conn := range []*websocket.Conn{john, smith} {
+			t.AssertNil(conn.WriteJSON(g.Map{"type": "room.join", "id": "1", "data": g.Map{"room": "r1"}}))
+			j := readWebSocketHubMessage(t, conn)
+			t.Assert(j.Get("type"), "room.join")
+			t.Assert(j.Get("id"), "1")
+			t.Assert(j.Get("error"), nil)
+		}
+		time.Sleep(50 * time.Millisecond)
+		t.Assert(hub.Count(), 2)
+		t.Assert(len(hub.RoomConns("r1")), 2)
+		t.Assert(len(hub.UserConns("john")), 1)
+
+		// Typed handler and room broadcast.
+		t.AssertNil(john.WriteJSON(g.Map{"type": "room.send", "id": "2", "data": g.Map{"room": "r1", "text": "hi"}}))
+		j := readWebSocketHubMessage(t, john)
+		t.Assert(j.Get("id"), "2")
+		t.Assert(j.Get("data.sent"), true)
+		j = readWebSocketHubMessage(t, smith)
+		t.Assert(j.Get("type"), "room.message")
+		t.Assert(j.Get("data.user"), "john")
+		t.Assert(j.Get("data.text"), "hi")
+
+		// Validation.
+		t.AssertNil(john.WriteJSON(g.Map{"type": "room.send", "id": "3", "data": g.Map{"room": "r1"}}))
+		j = readWebSocketHubMessage(t, john)
+		t.Assert(j.Get("id"), "3")
+		t.Assert(j.Get("error.code"), gcode.CodeValidationFailed.Code())
+
+		// Unknown type and malformed message.
+		t.AssertNil(john.WriteJSON(g.Map{"type": "unknown"}))
+		j = readWebSocketHubMessage(t, john)
+		t.Assert(j.Get("error.code"), gcode.CodeNotFound.Code())
+		t.AssertNil(john.WriteMessage(websocket.TextMessage, []byte("invalid")))
+		j = readWebSocketHubMessage(t, john)
+		t.Assert(j.Get("type"), ghttp.WebSocketMessageTypeError)
+
+		// Send to user and broadcast.
+		t.AssertNil(hub.SendToUser(ctx, "smith", "notice", "hello smith"))
+		j = readWebSocketHubMessage(t, smith)
+		t.Assert(j.Get("type"), "notice")
+		t.Assert(j.Get("data"), "hello smith")
+		t.AssertNil(hub.Broadcast(ctx, "notice", "hello all"))
+		t.Assert(readWebSocketHubMessage(t, john).Get("data"), "hello all")
+		t.Assert(readWebSocketHubMessage(t, smith).Get("data"), "hello all")
+
+		// Disconnection.
+		smith.Close()
+		time.Sleep(100 * time.Millisecond)
+		t.Assert(hub.Count(), 1)
+		t.Assert(len(hub.RoomConns("r1")), 1)
+		t.Assert(len(hub.UserConns("smith")), 0)
+	})
+	// Connection rejected by OnConnect.
+	gtest.C(t, func(t *gtest.T) {
+		conn := dialWebSocketHub(t, s, "")
+		defer conn.Close()
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		_, _, err := conn.ReadMessage()
+		t.Assert(websocket.IsCloseError(err, websocket.CloseNormalClosure), true)
+	})
+}
+
+func Test_WebSocketHub_Heartbeat(t *testing.T) {
+	hub := ghttp.NewWebSocketHub(ghttp.WebSocketHubOptions{
+		PingInterval: 100 * time.Millisecond,
+		PongTimeout:  300 * time.Millisecond,
+	})
+	defer hub.Close()
+	s := startWebSocketHubServer(hub)
+	defer s.Shutdown()
+
+	gtest.C(t, func(t *gtest.T) {
+		// The client does not read, so pongs are not replied.
+		conn := dialWebSocketHub(t, s, "")
+		defer conn.Close()
+		time.Sleep(100 * time.Millisecond)
+		t.Assert(hub.Count(), 1)
+		time.Sleep(500 * time.Millisecond)
+		t.Assert(hub.Count(), 0)
+	})
+	gtest.C(t, func(t *gtest.T) {
+		// The client reads, so pongs are replied automatically.
+		conn := dialWebSocketHub(t, s, "")
+		defer conn.Close()
+		go func() {
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+		time.Sleep(600 * time.Millisecond)
+		t.Assert(hub.Count(), 1)
+	})
+}
+
+func Test_WebSocketHub_Backplane(t *testing.T) {
+	var (
+		backplane = &hubTestBackplane{}
+		hub1      = ghttp.NewWebSocketHub()
+		hub2      = ghttp.NewWebSocketHub()
+	)
+	defer hub1.Close()
+	defer hub2.Close()
+	gtest.AssertNil(hub1.SetBackplane(ctx, backplane))
+	gtest.AssertNil(hub2.SetBackplane(ctx, backplane))
+	hub1.On("join", func(ctx context.Context, req *struct{ Room string }) error {
+		ghttp.WebSocketConnFromCtx(ctx).Join(req.Room)
+		return nil
+	})
+	hub2.On("join", func(ctx context.Context, req *struct{ Room string }) error {
+		ghttp.WebSocketConnFromCtx(ctx).Join(req.Room)
+		return nil
+	})
+	var (
+		s1 = startWebSocketHubServer(hub1)
+		s2 = startWebSocketHubServer(hub2)
+	)
+	defer s1.Shutdown()
+	defer s2.Shutdown()
+
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			conn1 = dialWebSocketHub(t, s1, "")
+			conn2 = dialWebSocketHub(t, s2, "")
+		)
+		defer conn1.Close()
+		defer conn2.Close()
+		t.AssertNil(conn1.WriteJSON(g.Map{"type": "join", "id": "1", "data": g.Map{"room": "r"}}))
+		t.AssertNil(conn2.WriteJSON(g.Map{"type": "join", "id": "1", "data": g.Map{"room": "r"}}))
+		readWebSocketHubMessage(t, conn1)
+		readWebSocketHubMessage(t, conn2)
+
+		t.AssertNil(hub1.BroadcastRoom(ctx, "r", "message", "hello"))
+		t.Assert(readWebSocketHubMessage(t, conn1).Get("data"), "hello")
+		t.Assert(readWebSocketHubMessage(t, conn2).Get("data"), "hello")
+
+		// Exactly once for each connection.
+		t.AssertNil(hub2.Broadcast(ctx, "message", "world"))
+		t.Assert(readWebSocketHubMessage(t, conn1).Get("data"), "world")
+		t.Assert(readWebSocketHubMessage(t, conn2).Get("data"), "world")
+		_ = conn1.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+		_, _, err := conn1.ReadMessage()
+		t.AssertNE(err, nil)
+	})
+}
@@ -30,6 +30,9 @@ import (
 	"sync"
 	"time"
 
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+
 	"github.com/gogf/gf/v2/container/gtype"
 	"github.com/gogf/gf/v2/errors/gcode"
 	"github.com/gogf/gf/v2/errors/gerror"
@@ -108,6 +111,16 @@ type ServerConfig struct {
 	// KeepAlive enables HTTP keep-alive.
 	KeepAlive bool `json:"keepAlive"`
 
+	// H2C enables cleartext HTTP/2 for HTTP service, with both prior knowledge and HTTP/1.1 upgrade.
+	H2C bool `json:"h2c"`
+
+	// HTTP2MaxConcurrentStreams specifies the number of concurrent streams that each HTTP/2 client
+	// may have open at a time.
+	HTTP2MaxConcurrentStreams uint32 `json:"http2MaxConcurrentStreams"`
+
+	// HTTP2MaxReadFrameSize specifies the largest HTTP/2 frame the server is willing to read.
+	HTTP2MaxReadFrameSize uint32 `json:"http2MaxReadFrameSize"`
+
 	// Logger specifies the logger for server.
 	Logger *glog.Logger `json:"logger"`
 }
@@ -164,9 +177,41 @@ func newHttpServer(
 		ErrorLog:       log.New(loggerWriter, "", 0),
 	}
 	server.SetKeepAlivesEnabled(config.KeepAlive)
+	if config.H2C || config.HTTP2MaxConcurrentStreams > 0 || config.HTTP2MaxReadFrameSize > 0 {
+		configureHttp2Server(server, config)
+	}
 	return server
 }
 
+// configureHttp2Server configures HTTP/2 support of `server`, which enables HTTP/2 for HTTPS service
+// and also cleartext HTTP/2 for HTTP service if H2C is enabled.
+func configureHttp2Server(server *http.Server, config ServerConfig) {
+	http2Server := &http2.Server{
+		MaxConcurrentStreams: config.HTTP2MaxConcurrentStreams,
+		MaxReadFrameSize:     config.HTTP2MaxReadFrameSize,
+		IdleTimeout:          config.IdleTimeout,
+	}
+	if err := http2.ConfigureServer(server, http2Server); err != nil {
+		config.Logger.Errorf(context.TODO(), `configure HTTP/2 server failed: %+v`, err)
+		return
+	}
+	if !config.H2C {
+		return
+	}
+	var (
+		handler    = server.Handler
+		h2cHandler = h2c.NewHandler(handler, http2Server)
+	)
+	server.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Cleartext HTTP/2 is not allowed over TLS connections.
+		if r.TLS != nil {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		h2cHandler.ServeHTTP(w, r)
+	})
+}
+
 // Fd retrieves and returns the file descriptor of the current server.
 // It is available ony in *nix like operating systems like linux, unix, darwin.
 func (s *Server) Fd() uintptr {
@@ -219,7 +264,11 @@ func (s *Server) CreateListenerTLS(certFile, keyFile string, tlsConfig ...*tls.C
 		config = &tls.Config{}
 	}
 	if config.NextProtos == nil {
-		config.NextProtos = []string{"http/1.1"}
+		if _, ok := s.httpServer.TLSNextProto[http2.NextProtoTLS]; ok {
+			config.NextProtos = []string{http2.NextProtoTLS, "http/1.1"}
+		} else {
+			config.NextProtos = []string{"http/1.1"}
+		}
 	}
 	var err error
 	if len(config.Certificates) == 0 {
@@ -97,10 +97,11 @@ func New() *OpenApiV3 {
 
 // AddInput is the structured parameter for function OpenApiV3.Add.
 type AddInput struct {
-	Path   string      // Path specifies the custom path if this is not configured in Meta of struct tag.
-	Prefix string      // Prefix specifies the custom route path prefix, which will be added with the path tag in Meta of struct tag.
-	Method string      // Method specifies the custom HTTP method if this is not configured in Meta of struct tag.
-	Object interface{} // Object can be an instance of struct or a route function.
+	Path       string      // Path specifies the custom path if this is not configured in Meta of struct tag.
+	Prefix     string      // Prefix specifies the custom route path prefix, which will be added with the path tag in Meta of struct tag.
+	Method     string      // Method specifies the custom HTTP method if this is not configured in Meta of struct tag.
+	Object     interface{} // Object can be an instance of struct or a route function.
+	Deprecated bool        // Deprecated marks the operation deprecated, which can also be configured in Meta of struct tag.
 }
 
 // Add adds an instance of struct or a route function to OpenApiV3 definition implements.
@@ -117,10 +118,11 @@ func (oai *OpenApiV3) Add(in AddInput) error {
 
 	case reflect.Func:
 		return oai.addPath(addPathInput{
-			Path:     in.Path,
-			Prefix:   in.Prefix,
-			Method:   in.Method,
-			Function: in.Object,
+			Path:       in.Path,
+			Prefix:     in.Prefix,
+			Method:     in.Method,
+			Function:   in.Object,
+			Deprecated: in.Deprecated,
 		})
 
 	default:
@@ -13,6 +13,7 @@ import (
 	"github.com/gogf/gf/v2/container/gset"
 	"github.com/gogf/gf/v2/errors/gcode"
 	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/internal/httputil"
 	"github.com/gogf/gf/v2/internal/json"
 	"github.com/gogf/gf/v2/os/gstructs"
 	"github.com/gogf/gf/v2/text/gstr"
@@ -96,6 +97,31 @@ func (oai *OpenApiV3) newParameterRefWithStructMethod(field gstructs.Field, path
 	}, nil
 }
 
+// applyRouteConstraintToParameter applies the constraint of route parameter to the schema of
+// path parameter `parameterRef`, like `{id:int}` and `{code:[A-Z]{3}}`.
+func applyRouteConstraintToParameter(parameterRef *ParameterRef, routeParams []httputil.RouteParam) {
+	var parameter = parameterRef.Value
+	if parameter == nil || parameter.In != ParameterInPath {
+		return
+	}
+	if parameter.Schema == nil || parameter.Schema.Ref != "" || parameter.Schema.Value == nil {
+		return
+	}
+	for _, routeParam := range routeParams {
+		if routeParam.Constraint == "" || !gstr.Equal(routeParam.Name, parameter.Name) {
+			continue
+		}
+		var schema = parameter.Schema.Value
+		if schema.Pattern == "" {
+			schema.Pattern = "^" + httputil.ConstraintToRegex(routeParam.Constraint) + "$"
+		}
+		if routeParam.Constraint == "uuid" && schema.Type == TypeString && (schema.Format == "" || schema.Format == TypeString) {
+			schema.Format = "uuid"
+		}
+		return
+	}
+}
+
 func (r ParameterRef) MarshalJSON() ([]byte, error) {
 	if r.Ref != "" {
 		return formatRefToBytes(r.Ref), nil
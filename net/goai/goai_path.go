@@ -14,6 +14,7 @@ import (
 	"github.com/gogf/gf/v2/container/gmap"
 	"github.com/gogf/gf/v2/errors/gcode"
 	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/internal/httputil"
 	"github.com/gogf/gf/v2/internal/json"
 	"github.com/gogf/gf/v2/os/gstructs"
 	"github.com/gogf/gf/v2/text/gstr"
@@ -49,10 +50,11 @@ const (
 )
 
 type addPathInput struct {
-	Path     string      // Precise route path.
-	Prefix   string      // Route path prefix.
-	Method   string      // Route method.
-	Function interface{} // Uniformed function.
+	Path       string      // Precise route path.
+	Prefix     string      // Route path prefix.
+	Method     string      // Route method.
+	Function   interface{} // Uniformed function.
+	Deprecated bool        // Marks the operation deprecated.
 }
 
 func (oai *OpenApiV3) addPath(in addPathInput) error {
@@ -112,6 +114,10 @@ func (oai *OpenApiV3) addPath(in addPathInput) error {
 		)
 	}
 
+	// Constraints of path parameters are removed from the path, and applied to their schemas.
+	var routeParams = httputil.ParseRouteParams(in.Path)
+	in.Path = httputil.StripRouteConstraints(in.Path)
+
 	if v, ok := oai.Paths[in.Path]; ok {
 		path = v
 	}
@@ -156,6 +162,9 @@ func (oai *OpenApiV3) addPath(in addPathInput) error {
 			mime = inputMetaMap[gtag.Consumes]
 		}
 	}
+	if in.Deprecated {
+		operation.Deprecated = true
+	}
 
 	// path security
 	// note: the security schema type only support http and apiKey;not support oauth2 and openIdConnect.
@@ -185,6 +194,7 @@ func (oai *OpenApiV3) addPath(in addPathInput) error {
 			return err
 		}
 		if parameterRef != nil {
+			applyRouteConstraintToParameter(parameterRef, routeParams)
 			operation.Parameters = append(operation.Parameters, *parameterRef)
 		}
 	}
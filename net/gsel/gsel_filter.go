@@ -0,0 +1,48 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gsel
+
+import (
+	"context"
+)
+
+// NodeFilter checks whether the node can be picked by selector.
+type NodeFilter func(ctx context.Context, node Node) bool
+
+// ctxKeyNodeFilter is the context key for NodeFilter.
+type ctxKeyNodeFilter struct{}
+
+// WithNodeFilter returns a new context with `filter`, which makes the selectors
+// picking with the context skip the nodes that `filter` returns false.
+// The selector picks nil node if all nodes are skipped.
+func WithNodeFilter(ctx context.Context, filter NodeFilter) context.Context {
+	return context.WithValue(ctx, ctxKeyNodeFilter{}, filter)
+}
+
+// GetNodeFilter returns the NodeFilter from context, which is nil if not set.
+func GetNodeFilter(ctx context.Context) NodeFilter {
+	if filter, ok := ctx.Value(ctxKeyNodeFilter{}).(NodeFilter); ok {
+		return filter
+	}
+	return nil
+}
+
+// FilterNodes returns the nodes that pass the NodeFilter from context.
+// It returns `nodes` itself if no filter is set in context.
+func FilterNodes(ctx context.Context, nodes Nodes) Nodes {
+	filter := GetNodeFilter(ctx)
+	if filter == nil {
+		return nodes
+	}
+	filtered := make(Nodes, 0, len(nodes))
+	for _, node := range nodes {
+		if filter(ctx, node) {
+			filtered = append(filtered, node)
+		}
+	}
+	return filtered
+}
@@ -49,17 +49,20 @@ func (s *selectorLeastConnection) Update(ctx context.Context, nodes Nodes) error
 func (s *selectorLeastConnection) Pick(ctx context.Context) (node Node, done DoneFunc, err error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	var pickedNode *leastConnectionNode
-	if len(s.nodes) == 1 {
-		pickedNode = s.nodes[0]
-	} else {
-		for _, v := range s.nodes {
-			if pickedNode == nil {
-				pickedNode = v
-			} else if v.inflight.Val() < pickedNode.inflight.Val() {
-				pickedNode = v
-			}
+	var (
+		pickedNode *leastConnectionNode
+		filter     = GetNodeFilter(ctx)
+	)
+	for _, v := range s.nodes {
+		if filter != nil && !filter(ctx, v.Node) {
+			continue
 		}
+		if pickedNode == nil || v.inflight.Val() < pickedNode.inflight.Val() {
+			pickedNode = v
+		}
+	}
+	if pickedNode == nil {
+		return nil, nil, nil
 	}
 	pickedNode.inflight.Add(1)
 	done = func(ctx context.Context, di DoneInfo) {
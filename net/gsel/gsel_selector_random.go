@@ -36,10 +36,11 @@ func (s *selectorRandom) Update(ctx context.Context, nodes Nodes) error {
 func (s *selectorRandom) Pick(ctx context.Context) (node Node, done DoneFunc, err error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	if len(s.nodes) == 0 {
+	nodes := FilterNodes(ctx, s.nodes)
+	if len(nodes) == 0 {
 		return nil, nil, nil
 	}
-	node = s.nodes[grand.Intn(len(s.nodes))]
+	node = nodes[grand.Intn(len(nodes))]
 	intlog.Printf(ctx, `Picked node: %s`, node.Address())
 	return node, nil, nil
 }
@@ -37,11 +37,18 @@ func (s *selectorRoundRobin) Update(ctx context.Context, nodes Nodes) error {
 func (s *selectorRoundRobin) Pick(ctx context.Context) (node Node, done DoneFunc, err error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if len(s.nodes) == 0 {
+	filter := GetNodeFilter(ctx)
+	for i := 0; i < len(s.nodes); i++ {
+		candidate := s.nodes[s.next]
+		s.next = (s.next + 1) % len(s.nodes)
+		if filter == nil || filter(ctx, candidate) {
+			node = candidate
+			break
+		}
+	}
+	if node == nil {
 		return
 	}
-	node = s.nodes[s.next]
-	s.next = (s.next + 1) % len(s.nodes)
 	intlog.Printf(ctx, `Picked node: %s`, node.Address())
 	return
 }
@@ -27,6 +27,10 @@ const (
 	Path                 = `path`            // Route path for HTTP request.
 	Method               = `method`          // Route method for HTTP request.
 	Domain               = `domain`          // Route domain for HTTP request.
+	Version              = `version`         // Route API version for HTTP request.
+	Timeout              = `timeout`         // Handling timeout for HTTP request, like: 3s, 500ms.
+	Bulkhead             = `bulkhead`        // Bulkhead options for HTTP request, like: maxConcurrent=10,maxQueue=100.
+	CircuitBreaker       = `circuitBreaker`  // Circuit breaker options for HTTP request, like: errorRate=0.5,slowThreshold=1s.
 	Mime                 = `mime`            // MIME type for HTTP request/response.
 	Consumes             = `consumes`        // MIME type for HTTP request.
 	Summary              = `summary`         // Summary for struct, usually for OpenAPI in request struct.